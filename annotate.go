@@ -0,0 +1,256 @@
+package vcf
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// AnnotationField selects a value of an annotation source to be copied into the INFO of the annotated variants.
+type AnnotationField struct {
+	// From is the INFO key to copy from an annotation VCF. For BED and TSV sources it is the name of a column, as
+	// written on the last header line of the file, or its 1-based number.
+	From string
+
+	// To is the INFO key written on the annotated variants. It defaults to From.
+	To string
+
+	// Number, Type and Description are used on the ##INFO line added for To. When empty, they are taken from the
+	// definition of From on the header of an annotation VCF, or default to a list of strings for other sources.
+	Number      string
+	Type        string
+	Description string
+}
+
+func (f AnnotationField) key() string {
+	if f.To != "" {
+		return f.To
+	}
+	return f.From
+}
+
+// AnnotationSource is a tabix-indexed file whose records annotate the variants they match.
+type AnnotationSource struct {
+	reader *bgzfReader
+	index  *tabixIndex
+	fields []AnnotationField
+
+	// header is set for VCF sources, whose records match variants with the same alleles. The records of other
+	// sources match the variants they overlap, and columns holds the position of each field among their columns.
+	header  *Header
+	columns []int
+}
+
+// NewVCFAnnotationSource opens a bgzipped VCF, such as gnomAD or ClinVar, along with its tabix index. Its records
+// annotate the variants with the same alleles, once both are reduced to their minimal representation.
+func NewVCFAnnotationSource(data io.ReadSeeker, index io.Reader, fields []AnnotationField) (*AnnotationSource, error) {
+	source, err := newAnnotationSource(data, index, fields)
+	if err != nil {
+		return nil, err
+	}
+	source.header, err = vcfHeader(bufio.NewReader(source.reader))
+	if err != nil {
+		return nil, err
+	}
+	for i, field := range source.fields {
+		definition, found := source.header.Infos[field.From]
+		if !found {
+			return nil, fmt.Errorf("INFO/%s is not declared on the header of the annotation VCF", field.From)
+		}
+		if field.Number == "" {
+			field.Number = definition.Number
+		}
+		if field.Type == "" {
+			field.Type = definition.Type
+		}
+		if field.Description == "" {
+			field.Description = definition.Description
+		}
+		source.fields[i] = field
+	}
+	return source, nil
+}
+
+// NewIntervalAnnotationSource opens a bgzipped BED or TSV file along with its tabix index. Its records annotate the
+// variants they overlap, and the values of every overlapping record are joined by commas.
+func NewIntervalAnnotationSource(data io.ReadSeeker, index io.Reader, fields []AnnotationField) (*AnnotationSource, error) {
+	source, err := newAnnotationSource(data, index, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := source.reader.readLine()
+		if err == io.EOF || (err == nil && !source.index.isMeta(line, lineNumber)) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line != "" && line[0] == source.index.meta {
+			names = strings.Split(strings.TrimLeft(line, string(source.index.meta)), "\t")
+		}
+	}
+
+	for i, field := range source.fields {
+		column, err := strconv.Atoi(field.From)
+		if err != nil {
+			column = 0
+			for j, name := range names {
+				if name == field.From {
+					column = j + 1
+				}
+			}
+			if column == 0 {
+				return nil, fmt.Errorf("column %s is not named on the header of the annotation file", field.From)
+			}
+		}
+		source.columns = append(source.columns, column-1)
+		if field.Number == "" {
+			field.Number = "."
+		}
+		if field.Type == "" {
+			field.Type = "String"
+		}
+		if field.Description == "" {
+			field.Description = "Copied from column " + field.From + " of the overlapping intervals"
+		}
+		source.fields[i] = field
+	}
+	return source, nil
+}
+
+func newAnnotationSource(data io.ReadSeeker, index io.Reader, fields []AnnotationField) (*AnnotationSource, error) {
+	if len(fields) == 0 {
+		return nil, errors.New("no annotation fields selected")
+	}
+	tabix, err := readTabixIndex(index)
+	if err != nil {
+		return nil, err
+	}
+	return &AnnotationSource{
+		reader: newBGZFReader(data),
+		index:  tabix,
+		fields: append([]AnnotationField{}, fields...),
+	}, nil
+}
+
+// Annotator copies the selected fields of its sources into the INFO of each variant.
+//
+// Variants are annotated one at a time, usually as they are read from the output channel of ToChannel, and the
+// header handler is the place to declare the new fields with AddHeaderLines.
+type Annotator struct {
+	sources []*AnnotationSource
+}
+
+// NewAnnotator creates an Annotator from its sources. When sources write the same INFO key, the last one wins.
+func NewAnnotator(sources ...*AnnotationSource) *Annotator {
+	return &Annotator{sources: sources}
+}
+
+// AddHeaderLines adds an ##INFO line to the header for each annotation field it does not declare yet.
+func (a *Annotator) AddHeaderLines(header *Header) {
+	for _, source := range a.sources {
+		for _, field := range source.fields {
+			if _, declared := header.Infos[field.key()]; declared {
+				continue
+			}
+			description := strings.Replace(field.Description, `"`, `\"`, -1)
+			header.AddMetaLine(fmt.Sprintf(`##INFO=<ID=%s,Number=%s,Type=%s,Description="%s">`, field.key(), field.Number, field.Type, description))
+		}
+	}
+}
+
+// Annotate looks up the records of every source matching the variant and copies their fields into its INFO.
+// Fields absent from the matching records are left untouched.
+func (a *Annotator) Annotate(variant *Variant) error {
+	if variant.Info == nil {
+		variant.Info = make(map[string]interface{})
+	}
+	for _, source := range a.sources {
+		var err error
+		if source.header != nil {
+			err = source.annotateAlleles(variant)
+		} else {
+			err = source.annotateIntervals(variant)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to annotate %s:%d: %v", variant.Chrom, variant.Pos+1, err)
+		}
+	}
+	return nil
+}
+
+func (s *AnnotationSource) annotateAlleles(variant *Variant) error {
+	records, err := s.index.query(s.reader, variant.Chrom, variant.Pos, variant.Pos+len(variant.Ref))
+	if err != nil {
+		return err
+	}
+	pos, ref, alt := minimalRepresentation(variant.Pos, variant.Ref, variant.Alt)
+	for _, record := range records {
+		candidates, err := parseVcfLine(record, s.header, Lenient, nil)
+		if err != nil {
+			continue
+		}
+		for _, candidate := range candidates {
+			candidate = fixRefAltSuffix(candidate)
+			candidatePos, candidateRef, candidateAlt := minimalRepresentation(candidate.Pos, candidate.Ref, candidate.Alt)
+			if candidatePos != pos || candidateRef != ref || candidateAlt != alt {
+				continue
+			}
+			for _, field := range s.fields {
+				if value, found := candidate.Info[field.From]; found {
+					variant.Info[field.key()] = value
+				}
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *AnnotationSource) annotateIntervals(variant *Variant) error {
+	start, end := variant.Pos, variant.end()
+	records, err := s.index.query(s.reader, variant.Chrom, start, end)
+	if err != nil {
+		return err
+	}
+	for i, field := range s.fields {
+		var values []string
+		seen := make(map[string]bool)
+		for _, record := range records {
+			columns := strings.Split(record, "\t")
+			if s.columns[i] >= len(columns) {
+				continue
+			}
+			value := columns[s.columns[i]]
+			if value != "" && value != "." && !seen[value] {
+				seen[value] = true
+				values = append(values, value)
+			}
+		}
+		if len(values) > 0 {
+			variant.Info[field.key()] = strings.Join(values, ",")
+		}
+	}
+	return nil
+}
+
+// minimalRepresentation removes the bases shared by the end and then the start of both alleles, keeping at least
+// one base on each, so that the same variant written with different padding is compared equal.
+func minimalRepresentation(pos int, ref, alt string) (int, string, string) {
+	if isSymbolicAllele(alt) || isBreakendAllele(alt) {
+		return pos, ref, alt
+	}
+	for len(ref) > 1 && len(alt) > 1 && ref[len(ref)-1] == alt[len(alt)-1] {
+		ref, alt = ref[:len(ref)-1], alt[:len(alt)-1]
+	}
+	for len(ref) > 1 && len(alt) > 1 && ref[0] == alt[0] {
+		ref, alt, pos = ref[1:], alt[1:], pos+1
+	}
+	return pos, ref, alt
+}
@@ -0,0 +1,127 @@
+package vcf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+const annotationVCF = `##fileformat=VCFv4.2
+##INFO=<ID=AF,Number=A,Type=Float,Description="Allele frequency">
+##INFO=<ID=CLNSIG,Number=.,Type=String,Description="Clinical significance">
+##INFO=<ID=COMMON,Number=0,Type=Flag,Description="Common variant">
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO
+chr1	100	.	A	G,T	.	.	AF=0.1,0.2;COMMON
+chr1	200	.	ATG	ACG	.	.	AF=0.05;CLNSIG=Pathogenic
+chr2	50	.	CA	C	.	.	AF=0.3
+`
+
+const annotationBED = `#chrom	start	end	gene
+chr1	0	150	GENE1
+chr1	120	300	GENE2
+chr1	195	205	GENE2
+chr2	0	1000	GENE3
+`
+
+type AnnotateSuite struct {
+	suite.Suite
+}
+
+func (s *AnnotateSuite) source(text string, format tabixFormat) (*bytes.Reader, *bytes.Buffer) {
+	data := bgzipped(text)
+	var index bytes.Buffer
+	assert.NoError(s.T(), writeTabixIndex(bytes.NewReader(data), &index, format))
+	return bytes.NewReader(data), &index
+}
+
+func (s *AnnotateSuite) annotator() *Annotator {
+	data, index := s.source(annotationVCF, tabixFormatVCF)
+	vcfSource, err := NewVCFAnnotationSource(data, index, []AnnotationField{
+		{From: "AF", To: "gnomAD_AF"},
+		{From: "CLNSIG"},
+		{From: "COMMON"},
+	})
+	assert.NoError(s.T(), err)
+
+	data, index = s.source(annotationBED, tabixFormatBED)
+	bedSource, err := NewIntervalAnnotationSource(data, index, []AnnotationField{{From: "gene", To: "GENE"}})
+	assert.NoError(s.T(), err)
+
+	return NewAnnotator(vcfSource, bedSource)
+}
+
+func (s *AnnotateSuite) TestAnnotate() {
+	annotator := s.annotator()
+	vcfLines := "##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n" +
+		"1\t100\t.\tA\tT\t.\t.\t.\n" +
+		"1\t201\t.\tT\tC\t.\t.\t.\n" +
+		"1\t200\t.\tA\tC\t.\t.\t.\n" +
+		"2\t50\t.\tCAA\tCA\t.\t.\tDP=3\n" +
+		"3\t10\t.\tA\tG\t.\t.\t.\n" +
+		"1\t130\t.\tC\tG\t.\t.\t.\n"
+
+	var header *Header
+	output := make(chan *Variant, 10)
+	invalids := make(chan InvalidLine, 10)
+	err := ToChannel(strings.NewReader(vcfLines), output, invalids, WithHeaderHandler(func(h *Header) {
+		header = h
+		annotator.AddHeaderLines(h)
+	}))
+	assert.NoError(s.T(), err)
+
+	var variants []*Variant
+	for variant := range output {
+		assert.NoError(s.T(), annotator.Annotate(variant))
+		variants = append(variants, variant)
+	}
+
+	assert.Equal(s.T(), "0.2", variants[0].Info["gnomAD_AF"], "the value of the matching alternative should be copied")
+	assert.Equal(s.T(), true, variants[0].Info["COMMON"])
+	assert.Equal(s.T(), "GENE1", variants[0].Info["GENE"])
+
+	assert.Equal(s.T(), "0.05", variants[1].Info["gnomAD_AF"], "alleles should match once padding is removed")
+	assert.Equal(s.T(), "Pathogenic", variants[1].Info["CLNSIG"])
+	assert.Equal(s.T(), "GENE2", variants[1].Info["GENE"])
+	assert.Equal(s.T(), "GENE2", variants[2].Info["GENE"])
+
+	assert.NotContains(s.T(), variants[2].Info, "gnomAD_AF", "a different allele at the same position should not match")
+
+	assert.Equal(s.T(), "0.3", variants[3].Info["gnomAD_AF"], "deletions should match once their suffix is trimmed")
+	assert.Equal(s.T(), "3", variants[3].Info["DP"])
+
+	assert.NotContains(s.T(), variants[4].Info, "GENE")
+	assert.Equal(s.T(), "GENE1,GENE2", variants[5].Info["GENE"], "the values of every overlapping interval should be joined")
+
+	assert.Equal(s.T(), &InfoDefinition{ID: "gnomAD_AF", Number: "A", Type: "Float", Description: "Allele frequency"}, header.Infos["gnomAD_AF"])
+	assert.Equal(s.T(), "0", header.Infos["COMMON"].Number)
+	assert.Equal(s.T(), &InfoDefinition{ID: "GENE", Number: ".", Type: "String", Description: "Copied from column gene of the overlapping intervals"}, header.Infos["GENE"])
+}
+
+func (s *AnnotateSuite) TestUnknownFields() {
+	data, index := s.source(annotationVCF, tabixFormatVCF)
+	_, err := NewVCFAnnotationSource(data, index, []AnnotationField{{From: "AC"}})
+	assert.EqualError(s.T(), err, "INFO/AC is not declared on the header of the annotation VCF")
+
+	data, index = s.source(annotationBED, tabixFormatBED)
+	_, err = NewIntervalAnnotationSource(data, index, []AnnotationField{{From: "score"}})
+	assert.EqualError(s.T(), err, "column score is not named on the header of the annotation file")
+
+	data, index = s.source(annotationBED, tabixFormatBED)
+	_, err = NewIntervalAnnotationSource(data, index, []AnnotationField{{From: "4"}})
+	assert.NoError(s.T(), err, "columns can be selected by number")
+}
+
+func (s *AnnotateSuite) TestMinimalRepresentation() {
+	pos, ref, alt := minimalRepresentation(10, "ATG", "ACG")
+	assert.Equal(s.T(), []interface{}{11, "T", "C"}, []interface{}{pos, ref, alt})
+
+	pos, ref, alt = minimalRepresentation(10, "CAA", "CA")
+	assert.Equal(s.T(), []interface{}{10, "CA", "C"}, []interface{}{pos, ref, alt})
+}
+
+func TestAnnotateSuite(t *testing.T) {
+	suite.Run(t, new(AnnotateSuite))
+}
@@ -0,0 +1,147 @@
+package vcf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Interval is a region of a chromosome, 0-based and half-open like the Pos of a Variant and the regions of BED
+// files. Name and Score are the optional fourth and fifth columns of BED, with Score nil when absent.
+type Interval struct {
+	Chrom string
+	Start int
+	End   int
+
+	Name  string
+	Score *float64
+}
+
+// ReadBED reads the intervals of a BED file. Comments, blank lines and the track and browser lines of the UCSC
+// Genome Browser are skipped, and columns after the fifth are ignored.
+func ReadBED(reader io.Reader) ([]Interval, error) {
+	var intervals []Interval
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 100*1024), 10*1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") ||
+			strings.HasPrefix(line, "track") || strings.HasPrefix(line, "browser") {
+			continue
+		}
+		interval, err := parseBEDLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+		intervals = append(intervals, interval)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return intervals, nil
+}
+
+func parseBEDLine(line string) (Interval, error) {
+	fields := strings.Split(line, "\t")
+	if len(fields) < 3 {
+		return Interval{}, fmt.Errorf("expected at least 3 columns, found %d", len(fields))
+	}
+	interval := Interval{Chrom: fields[0]}
+	var err error
+	if interval.Start, err = strconv.Atoi(fields[1]); err != nil || interval.Start < 0 {
+		return Interval{}, fmt.Errorf("unable to parse start: %s", fields[1])
+	}
+	if interval.End, err = strconv.Atoi(fields[2]); err != nil || interval.End < interval.Start {
+		return Interval{}, fmt.Errorf("unable to parse end: %s", fields[2])
+	}
+	if len(fields) > 3 {
+		interval.Name = fields[3]
+	}
+	if len(fields) > 4 && fields[4] != "." {
+		score, err := strconv.ParseFloat(fields[4], 64)
+		if err != nil {
+			return Interval{}, fmt.Errorf("unable to parse score: %s", fields[4])
+		}
+		interval.Score = &score
+	}
+	return interval, nil
+}
+
+// IntervalSet answers whether regions overlap a set of intervals. Overlapping and adjacent intervals are merged,
+// and the merged intervals of each chromosome are kept sorted, so each query is a binary search.
+//
+// Chromosome names are compared after stripping the "chr" prefix, like the Chrom of a Variant. Empty intervals,
+// such as the insertion points of BED files, cover no base and never overlap anything.
+type IntervalSet struct {
+	chromosomes map[string][]Interval
+}
+
+// NewIntervalSet indexes the given intervals, which do not need to be sorted.
+func NewIntervalSet(intervals []Interval) *IntervalSet {
+	byChrom := make(map[string][]Interval)
+	for _, interval := range intervals {
+		if interval.End > interval.Start {
+			chrom := normalizeChrom(interval.Chrom)
+			byChrom[chrom] = append(byChrom[chrom], Interval{Chrom: chrom, Start: interval.Start, End: interval.End})
+		}
+	}
+
+	set := &IntervalSet{chromosomes: make(map[string][]Interval, len(byChrom))}
+	for chrom, unsorted := range byChrom {
+		sort.Slice(unsorted, func(i, j int) bool { return unsorted[i].Start < unsorted[j].Start })
+		merged := unsorted[:1]
+		for _, interval := range unsorted[1:] {
+			last := &merged[len(merged)-1]
+			if interval.Start <= last.End {
+				if interval.End > last.End {
+					last.End = interval.End
+				}
+				continue
+			}
+			merged = append(merged, interval)
+		}
+		set.chromosomes[chrom] = merged
+	}
+	return set
+}
+
+// Overlaps tells whether the 0-based half-open region shares at least one base with the set.
+func (s *IntervalSet) Overlaps(chrom string, start, end int) bool {
+	intervals := s.chromosomes[normalizeChrom(chrom)]
+	// the first interval ending after start is the only candidate, since merged intervals do not overlap
+	i := sort.Search(len(intervals), func(i int) bool { return intervals[i].End > start })
+	return i < len(intervals) && intervals[i].Start < end
+}
+
+// Contains tells whether the 0-based position is covered by the set.
+func (s *IntervalSet) Contains(chrom string, pos int) bool {
+	return s.Overlaps(chrom, pos, pos+1)
+}
+
+// OverlapsVariant tells whether the set overlaps the bases of the REF of the variant, up to its END when present.
+func (s *IntervalSet) OverlapsVariant(variant *Variant) bool {
+	end := variant.end()
+	if end <= variant.Pos {
+		end = variant.Pos + 1
+	}
+	return s.Overlaps(variant.Chrom, variant.Pos, end)
+}
+
+// Intervals returns the merged intervals of the set, sorted within each chromosome.
+func (s *IntervalSet) Intervals() []Interval {
+	chroms := make([]string, 0, len(s.chromosomes))
+	for chrom := range s.chromosomes {
+		chroms = append(chroms, chrom)
+	}
+	order := newContigOrder()
+	sort.Slice(chroms, func(i, j int) bool { return order.compare(chroms[i], chroms[j]) < 0 })
+
+	var intervals []Interval
+	for _, chrom := range chroms {
+		intervals = append(intervals, s.chromosomes[chrom]...)
+	}
+	return intervals
+}
@@ -0,0 +1,93 @@
+package vcf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type BEDSuite struct {
+	suite.Suite
+}
+
+func (s *BEDSuite) TestReadBED() {
+	bed := "browser position chr1:1-1000\ntrack name=targets\n# comment\n\nchr1\t100\t200\texon1\t960\t+\nchr1\t300\t400\nchr2\t0\t50\tx\t.\n"
+
+	intervals, err := ReadBED(strings.NewReader(bed))
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), intervals, 3)
+	assert.Equal(s.T(), "chr1", intervals[0].Chrom)
+	assert.Equal(s.T(), 100, intervals[0].Start)
+	assert.Equal(s.T(), 200, intervals[0].End)
+	assert.Equal(s.T(), "exon1", intervals[0].Name)
+	assert.Equal(s.T(), 960.0, *intervals[0].Score)
+	assert.Equal(s.T(), Interval{Chrom: "chr1", Start: 300, End: 400}, intervals[1])
+	assert.Nil(s.T(), intervals[2].Score)
+}
+
+func (s *BEDSuite) TestInvalidBED() {
+	for bed, message := range map[string]string{
+		"chr1\t100\n":            "line 1: expected at least 3 columns, found 2",
+		"chr1\t1\t2\nchr1\ta\t2": "line 2: unable to parse start: a",
+		"chr1\t200\t100":         "line 1: unable to parse end: 100",
+		"chr1\t1\t2\tx\thigh":    "line 1: unable to parse score: high",
+	} {
+		_, err := ReadBED(strings.NewReader(bed))
+		assert.EqualError(s.T(), err, message)
+	}
+}
+
+func (s *BEDSuite) TestIntervalSet() {
+	set := NewIntervalSet([]Interval{
+		{Chrom: "chr1", Start: 300, End: 400},
+		{Chrom: "chr1", Start: 100, End: 200},
+		{Chrom: "chr1", Start: 150, End: 250},
+		{Chrom: "2", Start: 10, End: 10},
+		{Chrom: "chr2", Start: 10, End: 20},
+	})
+
+	assert.Equal(s.T(), []Interval{{Chrom: "1", Start: 100, End: 250}, {Chrom: "1", Start: 300, End: 400}, {Chrom: "2", Start: 10, End: 20}}, set.Intervals())
+
+	assert.True(s.T(), set.Contains("1", 100))
+	assert.True(s.T(), set.Contains("chr1", 249))
+	assert.False(s.T(), set.Contains("1", 250), "ends are exclusive")
+	assert.False(s.T(), set.Contains("1", 99))
+	assert.True(s.T(), set.Overlaps("1", 240, 310))
+	assert.False(s.T(), set.Overlaps("1", 250, 300))
+	assert.False(s.T(), set.Overlaps("X", 0, 1000))
+	assert.False(s.T(), set.Contains("2", 9), "empty intervals cover no base")
+
+	deletion := &Variant{Chrom: "1", Pos: 95, Ref: "AAAAAA", Alt: "A"}
+	assert.True(s.T(), set.OverlapsVariant(deletion), "the whole REF should be considered")
+}
+
+func (s *BEDSuite) TestRegionOptions() {
+	vcfLines := "##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n" +
+		"1\t100\t.\tA\tG\t.\t.\t.\n" +
+		"1\t150\t.\tA\tG\t.\t.\t.\n" +
+		"1\t500\t.\tA\tG\t.\t.\t.\n" +
+		"2\t15\t.\tA\tG\t.\t.\t.\n"
+	targets := NewIntervalSet([]Interval{{Chrom: "chr1", Start: 0, End: 200}, {Chrom: "chr2", Start: 0, End: 100}})
+	blacklist := NewIntervalSet([]Interval{{Chrom: "1", Start: 149, End: 150}})
+
+	positions := func(options ...ReaderOption) []int {
+		output := make(chan *Variant, 10)
+		invalids := make(chan InvalidLine, 10)
+		assert.NoError(s.T(), ToChannel(strings.NewReader(vcfLines), output, invalids, options...))
+		assert.Len(s.T(), invalids, 0)
+		var result []int
+		for variant := range output {
+			result = append(result, variant.Pos+1)
+		}
+		return result
+	}
+	assert.Equal(s.T(), []int{100, 150, 15}, positions(IncludeRegions(targets)))
+	assert.Equal(s.T(), []int{100, 500, 15}, positions(ExcludeRegions(blacklist)))
+	assert.Equal(s.T(), []int{100, 15}, positions(IncludeRegions(targets), ExcludeRegions(blacklist)))
+}
+
+func TestBEDSuite(t *testing.T) {
+	suite.Run(t, new(BEDSuite))
+}
@@ -0,0 +1,221 @@
+package vcf
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"strings"
+)
+
+// BGZF is the blocked gzip format used by bgzip and tabix: a series of gzip members of at most 64 KiB each,
+// whose compressed size is recorded on a "BC" extra field, followed by an empty end-of-file block.
+
+// bgzfMaxBlockData is the amount of uncompressed data written on each block, leaving room for the
+// compression overhead of data that does not compress.
+const bgzfMaxBlockData = 0xff00
+
+// bgzfEOF is the empty block that marks the end of a BGZF file.
+var bgzfEOF = []byte{
+	0x1f, 0x8b, 0x08, 0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0x06, 0x00, 0x42, 0x43, 0x02, 0x00,
+	0x1b, 0x00, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+}
+
+type bgzfBlock struct {
+	// raw is the compressed block, as read from the input
+	raw []byte
+
+	// data is the uncompressed content of the block
+	data []byte
+}
+
+// readBGZFBlock reads and decompresses the next block of r. It returns io.EOF when r has no more data.
+func readBGZFBlock(r io.Reader) (*bgzfBlock, error) {
+	fixed := make([]byte, 12)
+	if _, err := io.ReadFull(r, fixed); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, errors.New("truncated BGZF block")
+		}
+		return nil, err
+	}
+	if fixed[0] != 0x1f || fixed[1] != 0x8b || fixed[2] != 8 || fixed[3]&4 == 0 {
+		return nil, errors.New("not a BGZF file")
+	}
+
+	extra := make([]byte, binary.LittleEndian.Uint16(fixed[10:]))
+	if _, err := io.ReadFull(r, extra); err != nil {
+		return nil, errors.New("truncated BGZF block")
+	}
+	size := -1
+	for i := 0; i+4 <= len(extra); {
+		length := int(binary.LittleEndian.Uint16(extra[i+2:]))
+		if extra[i] == 'B' && extra[i+1] == 'C' && length == 2 && i+6 <= len(extra) {
+			size = int(binary.LittleEndian.Uint16(extra[i+4:])) + 1
+			break
+		}
+		i += 4 + length
+	}
+	if size < len(fixed)+len(extra)+8 {
+		return nil, errors.New("gzip member without a valid BGZF block size")
+	}
+
+	rest := make([]byte, size-len(fixed)-len(extra))
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return nil, errors.New("truncated BGZF block")
+	}
+	compressed, trailer := rest[:len(rest)-8], rest[len(rest)-8:]
+	data, err := io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	if err != nil {
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(data) != binary.LittleEndian.Uint32(trailer) || uint32(len(data)) != binary.LittleEndian.Uint32(trailer[4:]) {
+		return nil, errors.New("corrupted BGZF block")
+	}
+
+	raw := make([]byte, 0, size)
+	raw = append(append(append(raw, fixed...), extra...), rest...)
+	return &bgzfBlock{raw: raw, data: data}, nil
+}
+
+// compressBGZFBlock compresses up to bgzfMaxBlockData bytes into a single block.
+func compressBGZFBlock(data []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+	deflater, err := flate.NewWriter(&compressed, flate.DefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := deflater.Write(data); err != nil {
+		return nil, err
+	}
+	if err := deflater.Close(); err != nil {
+		return nil, err
+	}
+
+	block := make([]byte, 18, 18+compressed.Len()+8)
+	copy(block, []byte{0x1f, 0x8b, 0x08, 0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0x06, 0x00, 'B', 'C', 0x02, 0x00})
+	binary.LittleEndian.PutUint16(block[16:], uint16(18+compressed.Len()+8-1))
+	block = append(block, compressed.Bytes()...)
+	block = binary.LittleEndian.AppendUint32(block, crc32.ChecksumIEEE(data))
+	block = binary.LittleEndian.AppendUint32(block, uint32(len(data)))
+	return block, nil
+}
+
+// writeBGZFBlocks compresses data into as many blocks as needed and writes them to w.
+func writeBGZFBlocks(w io.Writer, data []byte) error {
+	for len(data) > 0 {
+		size := len(data)
+		if size > bgzfMaxBlockData {
+			size = bgzfMaxBlockData
+		}
+		block, err := compressBGZFBlock(data[:size])
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(block); err != nil {
+			return err
+		}
+		data = data[size:]
+	}
+	return nil
+}
+
+// bgzfReader reads the uncompressed content of a BGZF file line by line, keeping track of virtual offsets: the
+// position of a block on the file shifted 16 bits to the left, plus a position within its uncompressed data.
+type bgzfReader struct {
+	source io.Reader
+	block  *bgzfBlock
+
+	// blockOffset is the position of the current block on the file and nextOffset the one of the block after it,
+	// where source always stands.
+	blockOffset int64
+	nextOffset  int64
+
+	// position is the next byte to read within the data of the current block
+	position int
+}
+
+func newBGZFReader(source io.Reader) *bgzfReader {
+	return &bgzfReader{source: source}
+}
+
+// virtualOffset returns the virtual offset of the next byte to read. Once a block is exhausted, it points to the
+// start of the next one, as htslib does.
+func (r *bgzfReader) virtualOffset() uint64 {
+	if r.block == nil || r.position == len(r.block.data) {
+		return uint64(r.nextOffset) << 16
+	}
+	return uint64(r.blockOffset)<<16 | uint64(r.position)
+}
+
+// seek moves to a virtual offset, which requires source to be an io.Seeker. The current block is reused when the
+// offset falls within it.
+func (r *bgzfReader) seek(virtualOffset uint64) error {
+	blockOffset, position := int64(virtualOffset>>16), int(virtualOffset&0xffff)
+	if r.block == nil || blockOffset != r.blockOffset {
+		seeker, ok := r.source.(io.Seeker)
+		if !ok {
+			return errors.New("BGZF source does not support seeking")
+		}
+		if _, err := seeker.Seek(blockOffset, io.SeekStart); err != nil {
+			return err
+		}
+		r.block, r.nextOffset = nil, blockOffset
+		if err := r.nextBlock(); err != nil && err != io.EOF {
+			return err
+		}
+	}
+	if r.block == nil || position > len(r.block.data) {
+		return errors.New("virtual offset beyond the end of its BGZF block")
+	}
+	r.position = position
+	return nil
+}
+
+func (r *bgzfReader) nextBlock() error {
+	block, err := readBGZFBlock(r.source)
+	if err != nil {
+		return err
+	}
+	r.block, r.blockOffset, r.position = block, r.nextOffset, 0
+	r.nextOffset += int64(len(block.raw))
+	return nil
+}
+
+// Read implements io.Reader over the uncompressed data.
+func (r *bgzfReader) Read(p []byte) (int, error) {
+	for r.block == nil || r.position == len(r.block.data) {
+		if err := r.nextBlock(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.block.data[r.position:])
+	r.position += n
+	return n, nil
+}
+
+// readLine returns the next line without its line terminator, or io.EOF once there are no more lines.
+func (r *bgzfReader) readLine() (string, error) {
+	var line []byte
+	for {
+		if r.block == nil || r.position == len(r.block.data) {
+			err := r.nextBlock()
+			if err == io.EOF && len(line) > 0 {
+				return strings.TrimSuffix(string(line), "\r"), nil
+			}
+			if err != nil {
+				return "", err
+			}
+			continue
+		}
+		data := r.block.data[r.position:]
+		if end := bytes.IndexByte(data, '\n'); end >= 0 {
+			line = append(line, data[:end]...)
+			r.position += end + 1
+			return strings.TrimSuffix(string(line), "\r"), nil
+		}
+		line = append(line, data...)
+		r.position = len(r.block.data)
+	}
+}
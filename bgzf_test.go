@@ -0,0 +1,103 @@
+package vcf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type BGZFSuite struct {
+	suite.Suite
+}
+
+func (s *BGZFSuite) TestRoundTrip() {
+	data := make([]byte, 3*bgzfMaxBlockData+10)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	var compressed bytes.Buffer
+	assert.NoError(s.T(), writeBGZFBlocks(&compressed, data))
+	compressed.Write(bgzfEOF)
+	encoded := compressed.Bytes()
+
+	// a standard gzip reader should read the concatenated members
+	decompressed, err := gzip.NewReader(bytes.NewReader(encoded))
+	assert.NoError(s.T(), err)
+	content, err := io.ReadAll(decompressed)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), data, content)
+
+	reader := bytes.NewReader(encoded)
+	var blocks, raw []byte
+	for {
+		block, err := readBGZFBlock(reader)
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(s.T(), err)
+		assert.True(s.T(), len(block.raw) <= 65536)
+		blocks = append(blocks, block.data...)
+		raw = append(raw, block.raw...)
+	}
+	assert.Equal(s.T(), data, blocks)
+	assert.Equal(s.T(), encoded, raw)
+}
+
+func (s *BGZFSuite) TestInvalidBlocks() {
+	_, err := readBGZFBlock(bytes.NewReader([]byte("##fileformat=VCFv4.2\n")))
+	assert.EqualError(s.T(), err, "not a BGZF file")
+
+	_, err = readBGZFBlock(bytes.NewReader(bgzfEOF[:20]))
+	assert.EqualError(s.T(), err, "truncated BGZF block")
+
+	var plain bytes.Buffer
+	writer := gzip.NewWriter(&plain)
+	writer.Write([]byte("data"))
+	writer.Close()
+	_, err = readBGZFBlock(&plain)
+	assert.EqualError(s.T(), err, "not a BGZF file", "plain gzip has no extra field")
+}
+
+func (s *BGZFSuite) TestReaderVirtualOffsets() {
+	lines := make([]string, 20000)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i)
+	}
+	reader := newBGZFReader(bytes.NewReader(bgzipped(strings.Join(lines, "\n"))))
+
+	offsets := make([]uint64, len(lines))
+	for i := range lines {
+		offsets[i] = reader.virtualOffset()
+		line, err := reader.readLine()
+		assert.NoError(s.T(), err)
+		assert.Equal(s.T(), lines[i], line, "lines should be read across blocks")
+	}
+	_, err := reader.readLine()
+	assert.Equal(s.T(), io.EOF, err)
+	assert.True(s.T(), offsets[len(offsets)-1]>>16 > 0, "the lines should span several blocks")
+
+	for _, i := range []int{19999, 0, 12345, 12346, 7} {
+		assert.NoError(s.T(), reader.seek(offsets[i]))
+		line, err := reader.readLine()
+		assert.NoError(s.T(), err)
+		assert.Equal(s.T(), lines[i], line)
+	}
+}
+
+// bgzipped compresses text into a BGZF file.
+func bgzipped(text string) []byte {
+	var compressed bytes.Buffer
+	writeBGZFBlocks(&compressed, []byte(text))
+	compressed.Write(bgzfEOF)
+	return compressed.Bytes()
+}
+
+func TestBGZFSuite(t *testing.T) {
+	suite.Run(t, new(BGZFSuite))
+}
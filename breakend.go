@@ -0,0 +1,116 @@
+package vcf
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// BreakendAllele is the parsed form of a breakend ALT, as described in section 5.4 of the VCF 4.2 spec.
+//
+// The four bracketed forms t[p[, t]p], ]p]t and [p[t are described by the JoinAfter and MateExtendsRight
+// fields. Single breakends (.t and t.) have no mate, and only JoinAfter is meaningful for them.
+type BreakendAllele struct {
+	// Sequence is the t part of the ALT: the reference base plus any inserted bases, upper-cased.
+	Sequence string
+
+	// Inserted holds the bases inserted at the novel adjacency, that is, Sequence without the reference base.
+	Inserted string
+
+	// MateChrom and MatePos locate the mate breakend p. MatePos is 0-based, like Variant.Pos, and
+	// MateChrom has the "chr" prefix stripped, like Variant.Chrom. Both are unset for single breakends.
+	MateChrom string
+	MatePos   int
+
+	// Single is true for single breakends, which have no mate.
+	Single bool
+
+	// JoinAfter is true when the joined piece comes after Sequence (t[p[, t]p] and t.), meaning the
+	// reference to the left of the breakend is kept. It is false when the joined piece comes before it.
+	JoinAfter bool
+
+	// MateExtendsRight is true when the joined piece starts at the mate and extends to its right ('[' brackets),
+	// and false when it ends at the mate and extends to its left (']' brackets).
+	MateExtendsRight bool
+}
+
+// ReverseComplemented tells whether the joined piece of sequence is reverse complemented at the
+// novel adjacency, as happens for t]p] and [p[t.
+func (b *BreakendAllele) ReverseComplemented() bool {
+	return !b.Single && b.JoinAfter != b.MateExtendsRight
+}
+
+// isBreakendAllele tells whether an ALT uses the breakend notation, either bracketed or single.
+// Dotted alternatives such as "G...." are not single breakends, which must have exactly one dot.
+func isBreakendAllele(alt string) bool {
+	if strings.ContainsAny(alt, "[]") {
+		return true
+	}
+	if len(alt) < 2 || strings.Count(alt, ".") != 1 {
+		return false
+	}
+	return strings.HasPrefix(alt, ".") || strings.HasSuffix(alt, ".")
+}
+
+func parseBreakendAllele(alt, ref string) (*BreakendAllele, error) {
+	breakend := &BreakendAllele{}
+
+	if !strings.ContainsAny(alt, "[]") {
+		breakend.Single = true
+		breakend.JoinAfter = strings.HasSuffix(alt, ".")
+		breakend.Sequence = strings.ToUpper(strings.Trim(alt, "."))
+		breakend.Inserted = insertedBreakendSequence(breakend.Sequence, ref, breakend.JoinAfter)
+		return breakend, nil
+	}
+
+	bracket := alt[strings.IndexAny(alt, "[]")]
+	first := strings.IndexByte(alt, bracket)
+	last := strings.LastIndexByte(alt, bracket)
+	if first == last || strings.Count(alt, string(bracket)) != 2 || strings.ContainsAny(alt, otherBracket(bracket)) {
+		return nil, errors.New("unable to parse breakend ALT: " + alt)
+	}
+
+	breakend.MateExtendsRight = bracket == '['
+	switch {
+	case first == 0 && last < len(alt)-1:
+		breakend.Sequence = alt[last+1:]
+	case first > 0 && last == len(alt)-1:
+		breakend.JoinAfter = true
+		breakend.Sequence = alt[:first]
+	default:
+		return nil, errors.New("unable to parse breakend ALT: " + alt)
+	}
+	breakend.Sequence = strings.ToUpper(breakend.Sequence)
+
+	mate := alt[first+1 : last]
+	separator := strings.LastIndexByte(mate, ':')
+	if separator <= 0 {
+		return nil, errors.New("unable to parse breakend mate position: " + alt)
+	}
+	matePos, err := strconv.Atoi(mate[separator+1:])
+	if err != nil {
+		return nil, errors.New("unable to parse breakend mate position: " + alt)
+	}
+	breakend.MateChrom = normalizeChrom(mate[:separator])
+	breakend.MatePos = matePos - 1 // converts mate to 0-based
+	breakend.Inserted = insertedBreakendSequence(breakend.Sequence, ref, breakend.JoinAfter)
+
+	return breakend, nil
+}
+
+func otherBracket(bracket byte) string {
+	if bracket == '[' {
+		return "]"
+	}
+	return "["
+}
+
+func insertedBreakendSequence(sequence, ref string, joinAfter bool) string {
+	if len(sequence) <= len(ref) {
+		return ""
+	}
+	if joinAfter {
+		return sequence[len(ref):]
+	}
+	return sequence[:len(sequence)-len(ref)]
+}
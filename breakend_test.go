@@ -0,0 +1,133 @@
+package vcf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type BreakendSuite struct {
+	suite.Suite
+}
+
+func (s *BreakendSuite) TestJoinedAfterExtendingRight() {
+	breakend, err := parseBreakendAllele("G[chr17:198983[", "G")
+
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "G", breakend.Sequence)
+	assert.Equal(s.T(), "", breakend.Inserted)
+	assert.Equal(s.T(), "17", breakend.MateChrom)
+	assert.Equal(s.T(), 198982, breakend.MatePos, "mate position should be 0-based")
+	assert.False(s.T(), breakend.Single)
+	assert.True(s.T(), breakend.JoinAfter)
+	assert.True(s.T(), breakend.MateExtendsRight)
+	assert.False(s.T(), breakend.ReverseComplemented())
+}
+
+func (s *BreakendSuite) TestJoinedAfterExtendingLeft() {
+	breakend, err := parseBreakendAllele("G]17:198982]", "G")
+
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "17", breakend.MateChrom)
+	assert.Equal(s.T(), 198981, breakend.MatePos)
+	assert.True(s.T(), breakend.JoinAfter)
+	assert.False(s.T(), breakend.MateExtendsRight)
+	assert.True(s.T(), breakend.ReverseComplemented())
+}
+
+func (s *BreakendSuite) TestJoinedBeforeExtendingLeft() {
+	breakend, err := parseBreakendAllele("]13:123456]T", "T")
+
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "T", breakend.Sequence)
+	assert.Equal(s.T(), "13", breakend.MateChrom)
+	assert.Equal(s.T(), 123455, breakend.MatePos)
+	assert.False(s.T(), breakend.JoinAfter)
+	assert.False(s.T(), breakend.MateExtendsRight)
+	assert.False(s.T(), breakend.ReverseComplemented())
+}
+
+func (s *BreakendSuite) TestJoinedBeforeExtendingRightWithInsertion() {
+	breakend, err := parseBreakendAllele("[2:321682[agtnnnnnC", "C")
+
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "AGTNNNNNC", breakend.Sequence)
+	assert.Equal(s.T(), "AGTNNNNN", breakend.Inserted)
+	assert.False(s.T(), breakend.JoinAfter)
+	assert.True(s.T(), breakend.MateExtendsRight)
+	assert.True(s.T(), breakend.ReverseComplemented())
+}
+
+func (s *BreakendSuite) TestSingleBreakends() {
+	breakend, err := parseBreakendAllele(".A", "A")
+	assert.NoError(s.T(), err)
+	assert.True(s.T(), breakend.Single)
+	assert.False(s.T(), breakend.JoinAfter)
+	assert.Equal(s.T(), "A", breakend.Sequence)
+	assert.Empty(s.T(), breakend.MateChrom)
+	assert.False(s.T(), breakend.ReverseComplemented())
+
+	breakend, err = parseBreakendAllele("GTC.", "G")
+	assert.NoError(s.T(), err)
+	assert.True(s.T(), breakend.Single)
+	assert.True(s.T(), breakend.JoinAfter)
+	assert.Equal(s.T(), "TC", breakend.Inserted)
+}
+
+func (s *BreakendSuite) TestMalformedBreakends() {
+	for _, alt := range []string{"G[17:198983]", "G[17:198983", "[17:198983[", "G[17[", "G[17:abc[", "]17:1]T]"} {
+		_, err := parseBreakendAllele(alt, "G")
+		assert.Error(s.T(), err, alt)
+	}
+}
+
+func (s *BreakendSuite) TestIsBreakendAllele() {
+	assert.True(s.T(), isBreakendAllele("G]17:198982]"))
+	assert.True(s.T(), isBreakendAllele(".A"))
+	assert.True(s.T(), isBreakendAllele("G."))
+	assert.False(s.T(), isBreakendAllele("G...."))
+	assert.False(s.T(), isBreakendAllele("."))
+	assert.False(s.T(), isBreakendAllele("<DEL>"))
+	assert.False(s.T(), isBreakendAllele("GT"))
+}
+
+func (s *BreakendSuite) TestParseVcfLineKeepsBreakendAlt() {
+	result, err := parseVcfLine("2\t321681\tbnd_W\tG\tG]chr17:198982],.G\t6\tPASS\tSVTYPE=BND;MATEID=bnd_Y;EVENT=RR0", defaultHeader, Standard, nil)
+
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), result, 2)
+
+	assert.Equal(s.T(), "G]chr17:198982]", result[0].Alt, "breakend ALT should not be upper-cased or stripped")
+	assert.NotNil(s.T(), result[0].Breakend)
+	assert.Equal(s.T(), "17", result[0].Breakend.MateChrom)
+	assert.Equal(s.T(), Breakend, *result[0].StructuralVariantType)
+	assert.Equal(s.T(), "bnd_Y", *result[0].MateID)
+	assert.Equal(s.T(), "RR0", *result[0].Event)
+
+	assert.Equal(s.T(), ".G", result[1].Alt)
+	assert.True(s.T(), result[1].Breakend.Single)
+
+	fixed := fixRefAltSuffix(result[1])
+	assert.Equal(s.T(), ".G", fixed.Alt, "suffix trimming should not touch breakends")
+}
+
+func (s *BreakendSuite) TestSVTypeDerivedFromBreakendAlt() {
+	result, err := parseVcfLine("2\t321681\tbnd_W\tG\tG[13:123457[\t6\tPASS\tMATEID=bnd_Y", defaultHeader, Standard, nil)
+
+	assert.NoError(s.T(), err)
+	assert.NotNil(s.T(), result[0].StructuralVariantType)
+	assert.Equal(s.T(), Breakend, *result[0].StructuralVariantType)
+	assert.Nil(s.T(), result[0].Event)
+}
+
+func (s *BreakendSuite) TestMalformedBreakendLineShouldReturnError() {
+	result, err := parseVcfLine("2\t321681\tbnd_W\tG\tG[13:abc[\t6\tPASS\tSVTYPE=BND", defaultHeader, Standard, nil)
+
+	assert.Error(s.T(), err)
+	assert.Empty(s.T(), result)
+}
+
+func TestBreakendSuite(t *testing.T) {
+	suite.Run(t, new(BreakendSuite))
+}
@@ -0,0 +1,180 @@
+package vcf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ChainSet holds the alignments of a UCSC chain file, such as hg19ToHg38.over.chain, which map the coordinates of
+// a source assembly, the reference of the chains, to a target one, their query.
+//
+// Chromosome names are compared after stripping the "chr" prefix, like the Chrom of a Variant.
+type ChainSet struct {
+	// blocks holds the ungapped blocks of every chain by normalized source chromosome, sorted by source start,
+	// and maxEnds the largest source end among each block and the ones before it, since chains may overlap.
+	blocks  map[string][]chainBlock
+	maxEnds map[string][]int
+}
+
+// chainBlock is an ungapped alignment of a chain. Target positions are on the strand of the chain, so for reverse
+// chains the forward position of targetStart is targetSize-targetStart-1.
+type chainBlock struct {
+	sourceStart int
+	sourceEnd   int
+	targetChrom string
+	targetStart int
+	targetSize  int
+	reverse     bool
+}
+
+// chainMapping is the target region of a source region that falls entirely within a block.
+type chainMapping struct {
+	chrom   string
+	start   int
+	end     int
+	reverse bool
+}
+
+// ReadChains reads every chain of a chain file, as described on https://genome.ucsc.edu/goldenPath/help/chain.html.
+func ReadChains(reader io.Reader) (*ChainSet, error) {
+	set := &ChainSet{blocks: make(map[string][]chainBlock), maxEnds: make(map[string][]int)}
+	scanner := bufio.NewScanner(reader)
+	var chain *chainBlock // source and target positions of the next block of the current chain
+	var sourceEnd, targetEnd int
+	var sourceChrom string
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		if fields[0] == "chain" {
+			if chain != nil {
+				return nil, fmt.Errorf("line %d: chain starts before the previous one ends", lineNumber)
+			}
+			if len(fields) < 12 {
+				return nil, fmt.Errorf("line %d: expected at least 12 columns on the chain header, found %d", lineNumber, len(fields))
+			}
+			numbers, err := atoiFields(fields, 3, 5, 6, 8, 10, 11)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+			}
+			if fields[4] != "+" || (fields[9] != "+" && fields[9] != "-") {
+				return nil, fmt.Errorf("line %d: unsupported chain strands %s and %s", lineNumber, fields[4], fields[9])
+			}
+			sourceChrom = normalizeChrom(fields[2])
+			sourceEnd, targetEnd = numbers[6], numbers[11]
+			chain = &chainBlock{
+				sourceStart: numbers[5],
+				targetChrom: fields[7],
+				targetStart: numbers[10],
+				targetSize:  numbers[8],
+				reverse:     fields[9] == "-",
+			}
+			continue
+		}
+
+		if chain == nil {
+			return nil, fmt.Errorf("line %d: alignment data outside a chain", lineNumber)
+		}
+		if len(fields) != 1 && len(fields) != 3 {
+			return nil, fmt.Errorf("line %d: expected 1 or 3 columns on the alignment data, found %d", lineNumber, len(fields))
+		}
+		numbers, err := atoiFields(fields, 0, 1, 2)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+		block := *chain
+		block.sourceEnd = block.sourceStart + numbers[0]
+		set.blocks[sourceChrom] = append(set.blocks[sourceChrom], block)
+		if len(fields) == 1 {
+			if block.sourceEnd != sourceEnd || block.targetStart+numbers[0] != targetEnd {
+				return nil, fmt.Errorf("line %d: chain blocks do not end where the chain header does", lineNumber)
+			}
+			chain = nil
+			continue
+		}
+		chain.sourceStart = block.sourceEnd + numbers[1]
+		chain.targetStart = block.targetStart + numbers[0] + numbers[2]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if chain != nil {
+		return nil, fmt.Errorf("the last chain does not end with a single-column line")
+	}
+
+	for chrom, blocks := range set.blocks {
+		sort.Slice(blocks, func(i, j int) bool { return blocks[i].sourceStart < blocks[j].sourceStart })
+		maxEnds := make([]int, len(blocks))
+		for i, block := range blocks {
+			maxEnds[i] = block.sourceEnd
+			if i > 0 && maxEnds[i-1] > maxEnds[i] {
+				maxEnds[i] = maxEnds[i-1]
+			}
+		}
+		set.maxEnds[chrom] = maxEnds
+	}
+	return set, nil
+}
+
+// atoiFields parses the fields at the given indexes, returning them at the same indexes of the result. Indexes
+// beyond the fields are left as zero.
+func atoiFields(fields []string, indexes ...int) ([]int, error) {
+	numbers := make([]int, len(fields))
+	for _, i := range indexes {
+		if i >= len(fields) {
+			continue
+		}
+		number, err := strconv.Atoi(fields[i])
+		if err != nil || number < 0 {
+			return nil, fmt.Errorf("unable to parse %s", fields[i])
+		}
+		numbers[i] = number
+	}
+	return numbers, nil
+}
+
+// overlapping returns the blocks with at least one base of the 0-based half-open source region.
+func (s *ChainSet) overlapping(chrom string, start, end int) []chainBlock {
+	chrom = normalizeChrom(chrom)
+	blocks, maxEnds := s.blocks[chrom], s.maxEnds[chrom]
+	// blocks after last start at or after end, and maxEnds tells when no block before i reaches start
+	last := sort.Search(len(blocks), func(i int) bool { return blocks[i].sourceStart >= end })
+	var found []chainBlock
+	for i := last - 1; i >= 0 && maxEnds[i] > start; i-- {
+		if blocks[i].sourceEnd > start {
+			found = append(found, blocks[i])
+		}
+	}
+	return found
+}
+
+// mappings returns the target regions of the blocks containing the whole 0-based half-open source region, along
+// with whether any block contains only part of it.
+func (s *ChainSet) mappings(chrom string, start, end int) (mappings []chainMapping, partial bool) {
+	if end <= start {
+		end = start + 1
+	}
+	for _, block := range s.overlapping(chrom, start, end) {
+		if start < block.sourceStart || end > block.sourceEnd {
+			partial = true
+			continue
+		}
+		mapping := chainMapping{
+			chrom:   block.targetChrom,
+			start:   block.targetStart + start - block.sourceStart,
+			end:     block.targetStart + end - block.sourceStart,
+			reverse: block.reverse,
+		}
+		if block.reverse {
+			mapping.start, mapping.end = block.targetSize-mapping.end, block.targetSize-mapping.start
+		}
+		mappings = append(mappings, mapping)
+	}
+	return mappings, partial
+}
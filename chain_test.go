@@ -0,0 +1,74 @@
+package vcf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ChainSuite struct {
+	suite.Suite
+}
+
+// chainText maps chr1:0-20 to chr1:5-25 and chr1:25-40 to itself, chr1:30-35 again to chr5 and chr2:0-30 to the
+// reverse strand of chr3.
+const chainText = `chain 100 chr1 100 + 0 40 chr1 100 + 5 40 1
+20	5	0
+15
+
+chain 10 chr1 100 + 30 35 chr5 10 + 0 5 3
+5
+
+chain 50 chr2 50 + 0 30 chr3 30 - 0 30 2
+30
+`
+
+func (s *ChainSuite) TestMappings() {
+	chains, err := ReadChains(strings.NewReader(chainText))
+	assert.NoError(s.T(), err)
+
+	for _, query := range []struct {
+		chrom      string
+		start, end int
+		expected   []chainMapping
+		partial    bool
+	}{
+		{"1", 10, 12, []chainMapping{{chrom: "chr1", start: 15, end: 17}}, false},
+		{"chr1", 0, 20, []chainMapping{{chrom: "chr1", start: 5, end: 25}}, false},
+		{"chr1", 25, 26, []chainMapping{{chrom: "chr1", start: 25, end: 26}}, false},
+		{"chr1", 21, 23, nil, false},
+		{"chr1", 18, 23, nil, true},
+		{"chr1", 38, 42, nil, true},
+		{"chr1", 60, 61, nil, false},
+		{"chrX", 1, 2, nil, false},
+		{"chr2", 5, 8, []chainMapping{{chrom: "chr3", start: 22, end: 25, reverse: true}}, false},
+	} {
+		mappings, partial := chains.mappings(query.chrom, query.start, query.end)
+		assert.Equal(s.T(), query.expected, mappings, "%s:%d-%d", query.chrom, query.start, query.end)
+		assert.Equal(s.T(), query.partial, partial, "%s:%d-%d", query.chrom, query.start, query.end)
+	}
+
+	mappings, _ := chains.mappings("chr1", 31, 33)
+	assert.ElementsMatch(s.T(), []chainMapping{{chrom: "chr1", start: 31, end: 33}, {chrom: "chr5", start: 1, end: 3}}, mappings)
+}
+
+func (s *ChainSuite) TestInvalidChains() {
+	for chain, message := range map[string]string{
+		"chain 1 chr1 100 + 0 10 chr1 100 + 0\n10\n":      "line 1: expected at least 12 columns on the chain header, found 11",
+		"chain 1 chr1 100 + 0 10 chr1 100 + 0 x 1\n10\n":  "line 1: unable to parse x",
+		"chain 1 chr1 100 - 0 10 chr1 100 + 0 10 1\n10\n": "line 1: unsupported chain strands - and +",
+		"10\n": "line 1: alignment data outside a chain",
+		"chain 1 chr1 100 + 0 10 chr1 100 + 0 10 1\n5 1\n":   "line 2: expected 1 or 3 columns on the alignment data, found 2",
+		"chain 1 chr1 100 + 0 10 chr1 100 + 0 10 1\n9\n":     "line 2: chain blocks do not end where the chain header does",
+		"chain 1 chr1 100 + 0 10 chr1 100 + 0 10 1\n5 0 0\n": "the last chain does not end with a single-column line",
+	} {
+		_, err := ReadChains(strings.NewReader(chain))
+		assert.EqualError(s.T(), err, message)
+	}
+}
+
+func TestChainSuite(t *testing.T) {
+	suite.Run(t, new(ChainSuite))
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mendelics/vcf"
+)
+
+func compare(args []string) error {
+	flags := flag.NewFlagSet("compare", flag.ContinueOnError)
+	truthSample := flags.String("truth-sample", "", "sample of the truth set, the first one by default")
+	querySample := flags.String("query-sample", "", "sample of the query, the first one by default")
+	genotype := flags.Bool("genotype", false, "require matching genotypes as well as alleles")
+	regions := flags.String("regions", "", "BED file with the confident regions of the truth set")
+	reference := flags.String("reference", "", "FASTA used to left-align indels, read through its .fai index when present")
+	haplotypes := flags.Bool("haplotypes", false, "match differently written variants by the haplotypes they spell, which requires -reference")
+	clusterDistance := flags.Int("cluster-distance", vcf.DefaultClusterDistance, "largest distance between the variants of a cluster compared by haplotype")
+	passOnly := flags.Bool("pass", false, "skip query records that do not PASS their filters")
+	truthOutput := flags.String("truth-output", "", "write the truth set annotated with INFO/BENCH to this file")
+	queryOutput := flags.String("query-output", "", "write the query annotated with INFO/BENCH to this file")
+	flags.Usage = func() {
+		flags.Output().Write([]byte("usage: vcf compare [-genotype] [-regions confident.bed] [-reference ref.fa] [-haplotypes] [-pass] [-truth-output truth.vcf] [-query-output query.vcf] truth.vcf query.vcf\n"))
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return errors.New("expected a truth set and a query VCF")
+	}
+
+	options := vcf.CompareOptions{
+		TruthSample:       *truthSample,
+		QuerySample:       *querySample,
+		MatchGenotype:     *genotype,
+		HaplotypeMatching: *haplotypes,
+		ClusterDistance:   *clusterDistance,
+		PassOnly:          *passOnly,
+	}
+	if *regions != "" {
+		set, err := readRegions(*regions)
+		if err != nil {
+			return err
+		}
+		options.ConfidentRegions = set
+	}
+	if *reference != "" {
+		fasta, closeFASTA, err := openFASTA(*reference)
+		if err != nil {
+			return err
+		}
+		defer closeFASTA()
+		options.Reference = fasta
+	}
+
+	truth, err := openInput(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer truth.Close()
+	query, err := openInput(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	defer query.Close()
+
+	var outputs []io.WriteCloser
+	for _, output := range []struct {
+		name   string
+		writer *io.Writer
+	}{{*truthOutput, &options.TruthOutput}, {*queryOutput, &options.QueryOutput}} {
+		if output.name == "" {
+			continue
+		}
+		out, err := os.Create(output.name)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		outputs = append(outputs, out)
+		*output.writer = out
+	}
+
+	report, err := vcf.Compare(truth, query, options)
+	if err != nil {
+		return err
+	}
+	for _, out := range outputs {
+		if err := out.Close(); err != nil {
+			return err
+		}
+	}
+	fmt.Fprint(os.Stdout, report.String())
+	return nil
+}
+
+// readRegions reads the intervals of a BED file into an IntervalSet.
+func readRegions(name string) (*vcf.IntervalSet, error) {
+	input, err := openInput(name)
+	if err != nil {
+		return nil, err
+	}
+	defer input.Close()
+	intervals, err := vcf.ReadBED(input)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return vcf.NewIntervalSet(intervals), nil
+}
+
+// openFASTA reads a FASTA file through its .fai index when there is one, or loads it in memory otherwise. The
+// returned function closes the file once the FASTA is no longer needed.
+func openFASTA(name string) (*vcf.FASTA, func() error, error) {
+	index, err := os.Open(name + ".fai")
+	if err != nil {
+		input, err := openInput(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer input.Close()
+		fasta, err := vcf.ReadFASTA(input)
+		return fasta, func() error { return nil }, err
+	}
+	defer index.Close()
+
+	data, err := os.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	fasta, err := vcf.NewIndexedFASTA(data, index)
+	if err != nil {
+		data.Close()
+		return nil, nil, err
+	}
+	return fasta, data.Close, nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mendelics/vcf"
+)
+
+func concat(args []string) error {
+	flags := flag.NewFlagSet("concat", flag.ContinueOnError)
+	output := flags.String("o", "", "output file, standard output by default")
+	removeDuplicates := flags.Bool("remove-duplicates", false, "drop records repeated at the boundaries of overlapping inputs")
+	naive := flags.Bool("naive", false, "copy the compressed blocks of bgzip inputs, writing a bgzip output, without checking the order of the records")
+	flags.Usage = func() {
+		flags.Output().Write([]byte("usage: vcf concat [-o output.vcf] [-remove-duplicates] [-naive] input.vcf [input.vcf ...]\n"))
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("no input VCF")
+	}
+
+	readers := make([]io.Reader, 0, flags.NArg())
+	for _, name := range flags.Args() {
+		var input io.ReadCloser
+		var err error
+		if *naive {
+			input, err = os.Open(name)
+		} else {
+			input, err = openInput(name)
+		}
+		if err != nil {
+			return err
+		}
+		defer input.Close()
+		readers = append(readers, input)
+	}
+
+	out, err := createOutput(*output)
+	if err != nil {
+		return err
+	}
+	if *naive {
+		err = vcf.ConcatBGZF(readers, out)
+	} else {
+		var conflicts []vcf.HeaderConflict
+		conflicts, err = vcf.Concat(readers, out, vcf.ConcatOptions{RemoveDuplicates: *removeDuplicates})
+		for _, conflict := range conflicts {
+			fmt.Fprintf(os.Stderr, "%v, keeping the first one\n", conflict)
+		}
+	}
+	if err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+
+	"github.com/mendelics/vcf"
+)
+
+func gvcf2vcf(args []string) error {
+	flags := flag.NewFlagSet("gvcf2vcf", flag.ContinueOnError)
+	output := flags.String("o", "", "output file, standard output by default")
+	flags.Usage = func() {
+		flags.Output().Write([]byte("usage: vcf gvcf2vcf [-o output.vcf] input.g.vcf [input.g.vcf ...]\n"))
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("no input gVCF")
+	}
+
+	readers := make([]io.Reader, 0, flags.NArg())
+	for _, name := range flags.Args() {
+		input, err := openInput(name)
+		if err != nil {
+			return err
+		}
+		defer input.Close()
+		readers = append(readers, input)
+	}
+
+	out, err := createOutput(*output)
+	if err != nil {
+		return err
+	}
+	if err := vcf.MergeGVCFs(readers, out); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
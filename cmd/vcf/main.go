@@ -0,0 +1,108 @@
+// Command vcf provides command line tools built on top of the vcf package.
+//
+// Usage:
+//
+//	vcf <command> [arguments]
+//
+// Run vcf <command> -h for the arguments of each command.
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+type command struct {
+	description string
+	run         func(args []string) error
+}
+
+var commands = map[string]command{
+	"compare":  {"compare a VCF against a truth set and report precision and recall", compare},
+	"concat":   {"concatenate VCFs with the same samples over different regions", concat},
+	"gvcf2vcf": {"convert and merge gVCFs into a multi-sample VCF", gvcf2vcf},
+	"mendel":   {"check the genotypes of trios for Mendelian violations and de novo candidates", mendel},
+	"sort":     {"sort the records of a VCF by contig order and position", sortVCF},
+	"validate": {"check a VCF against the spec and report the violations of each rule", validate},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, found := commands[os.Args[1]]
+	if !found {
+		fmt.Fprintf(os.Stderr, "vcf: unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+	if err := cmd.run(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "vcf %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "usage: vcf <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].description)
+	}
+}
+
+// openInput opens a VCF file for reading, decompressing it when its name ends with .gz.
+// The name "-" stands for the standard input.
+func openInput(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(name, ".gz") {
+		return file, nil
+	}
+	decompressed, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return readCloser{decompressed, file}, nil
+}
+
+type readCloser struct {
+	io.Reader
+	file *os.File
+}
+
+func (r readCloser) Close() error {
+	return r.file.Close()
+}
+
+// createOutput opens the output file, or the standard output when the name is empty or "-".
+func createOutput(name string) (io.WriteCloser, error) {
+	if name == "" || name == "-" {
+		return nopWriteCloser{os.Stdout}, nil
+	}
+	return os.Create(name)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mendelics/vcf"
+)
+
+func mendel(args []string) error {
+	flags := flag.NewFlagSet("mendel", flag.ContinueOnError)
+	ped := flags.String("ped", "", "PED file relating the samples, the ##PEDIGREE lines of the header by default")
+	par := flags.String("par", "", "BED file with the pseudoautosomal regions of chrX and chrY")
+	deNovo := flags.Bool("de-novo", false, "report de novo candidates only")
+	output := flags.String("o", "", "output file, standard output by default")
+	flags.Usage = func() {
+		flags.Output().Write([]byte("usage: vcf mendel [-ped family.ped] [-par par.bed] [-de-novo] [-o output.tsv] input.vcf\n"))
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("expected a single input VCF")
+	}
+
+	var pedigree *vcf.Pedigree
+	if *ped != "" {
+		input, err := openInput(*ped)
+		if err != nil {
+			return err
+		}
+		pedigree, err = vcf.ReadPED(input)
+		input.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", *ped, err)
+		}
+	}
+	var options vcf.MendelOptions
+	if *par != "" {
+		regions, err := readRegions(*par)
+		if err != nil {
+			return err
+		}
+		options.PseudoautosomalRegions = regions
+	}
+
+	input, err := openInput(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	variants := make(chan *vcf.Variant, 1024)
+	invalids := make(chan vcf.InvalidLine, 1024)
+	headers := make(chan *vcf.Header, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- vcf.ToChannel(input, variants, invalids, vcf.WithHeaderHandler(func(header *vcf.Header) { headers <- header }))
+	}()
+	var header *vcf.Header
+	select {
+	case header = <-headers:
+	case err := <-done:
+		return err
+	}
+	invalidCount := make(chan int, 1)
+	go func() {
+		count := 0
+		for range invalids {
+			count++
+		}
+		invalidCount <- count
+	}()
+
+	if pedigree == nil {
+		pedigree = header.Pedigree()
+	}
+	checker, err := vcf.NewMendelChecker(header, pedigree, options)
+	if err != nil {
+		for range variants {
+		}
+		return err
+	}
+
+	out, err := createOutput(*output)
+	if err != nil {
+		for range variants {
+		}
+		return err
+	}
+	writer := bufio.NewWriter(out)
+	fmt.Fprintln(writer, "#CHROM\tPOS\tREF\tALT\tCHILD\tSTATUS\tCHILD_GT\tFATHER_GT\tMOTHER_GT")
+
+	trios := checker.Trios()
+	checked := make([]int, len(trios))
+	violations := make([]int, len(trios))
+	deNovos := make([]int, len(trios))
+	for variant := range variants {
+		for i, result := range checker.Check(variant) {
+			switch result.Status {
+			case vcf.MendelMissing:
+				continue
+			case vcf.DeNovoCandidate:
+				deNovos[i]++
+				fallthrough
+			case vcf.MendelViolation:
+				violations[i]++
+			}
+			checked[i]++
+			if result.Status == vcf.MendelConsistent || (*deNovo && result.Status != vcf.DeNovoCandidate) {
+				continue
+			}
+			fmt.Fprintf(writer, "%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", variant.Chrom, variant.Pos+1, variant.Ref, variant.Alt,
+				result.Trio.Child.ID, result.Status, genotypeString(result.ChildGenotype),
+				genotypeString(result.FatherGenotype), genotypeString(result.MotherGenotype))
+		}
+	}
+
+	if err := <-done; err != nil {
+		out.Close()
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	for i, trio := range trios {
+		fmt.Fprintf(os.Stderr, "%s: %d genotypes checked, %d Mendelian violations, %d de novo candidates\n",
+			trio.Child.ID, checked[i], violations[i], deNovos[i])
+	}
+	if count := <-invalidCount; count > 0 {
+		return fmt.Errorf("%d lines could not be parsed", count)
+	}
+	return nil
+}
+
+func genotypeString(genotype *vcf.Genotype) string {
+	if genotype == nil {
+		return "."
+	}
+	return genotype.String()
+}
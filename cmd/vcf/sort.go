@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+	"flag"
+
+	"github.com/mendelics/vcf"
+)
+
+func sortVCF(args []string) error {
+	flags := flag.NewFlagSet("sort", flag.ContinueOnError)
+	output := flags.String("o", "", "output file, standard output by default")
+	memory := flags.Int("m", vcf.DefaultSortMemoryLimit>>20, "memory budget in MiB, exceeding records are sorted on temporary files")
+	tempDir := flags.String("T", "", "directory of the temporary files")
+	flags.Usage = func() {
+		flags.Output().Write([]byte("usage: vcf sort [-o output.vcf] [-m MiB] [-T dir] input.vcf\n"))
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("expected a single input VCF")
+	}
+
+	input, err := openInput(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	out, err := createOutput(*output)
+	if err != nil {
+		return err
+	}
+	if err := vcf.Sort(input, out, vcf.SortOptions{MemoryLimit: *memory << 20, TempDir: *tempDir}); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mendelics/vcf"
+)
+
+func validate(args []string) error {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	examples := flags.Int("n", vcf.DefaultValidationExamples, "number of example lines shown for each rule")
+	flags.Usage = func() {
+		flags.Output().Write([]byte("usage: vcf validate [-n examples] input.vcf\n"))
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("expected a single input VCF")
+	}
+
+	input, err := openInput(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	report, err := vcf.Validate(input, vcf.ValidateOptions{MaxExamples: *examples})
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(os.Stdout, report.String())
+	if !report.Valid() {
+		return fmt.Errorf("%s is not valid", flags.Arg(0))
+	}
+	return nil
+}
@@ -0,0 +1,453 @@
+package vcf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// VariantClass groups the counts of a comparison by the kind of variant, as found after normalization.
+type VariantClass string
+
+const (
+	// ClassSNV is a single base substitution.
+	ClassSNV VariantClass = "SNV"
+	// ClassMNP is a substitution of several adjacent bases.
+	ClassMNP VariantClass = "MNP"
+	// ClassIndel is an insertion, a deletion or a complex replacement of bases by a sequence of another length.
+	ClassIndel VariantClass = "INDEL"
+	// ClassSV is a symbolic or breakend allele.
+	ClassSV VariantClass = "SV"
+)
+
+// variantClasses is the order in which classes are listed on a ComparisonReport.
+var variantClasses = []VariantClass{ClassSNV, ClassMNP, ClassIndel, ClassSV}
+
+func classifyAlleles(ref, alt string) VariantClass {
+	switch {
+	case isSymbolicAllele(alt) || isBreakendAllele(alt):
+		return ClassSV
+	case len(ref) != len(alt):
+		return ClassIndel
+	case len(ref) == 1:
+		return ClassSNV
+	}
+	return ClassMNP
+}
+
+// The values of the BENCH INFO key written on the annotated outputs of Compare.
+const (
+	benchTruePositive  = "TP"
+	benchFalsePositive = "FP"
+	benchFalseNegative = "FN"
+	benchNotAssessed   = "N"
+	benchOutside       = "UNK"
+)
+
+// CompareOptions configures Compare.
+type CompareOptions struct {
+	// TruthSample and QuerySample name the samples compared, defaulting to the first sample of each file. Records
+	// whose genotype does not carry the alternative allele are not assessed. Files without samples compare every
+	// record.
+	TruthSample string
+	QuerySample string
+
+	// MatchGenotype requires the number of copies of the alternative allele to match as well. Records matching
+	// only by allele count as a false positive and a false negative.
+	MatchGenotype bool
+
+	// ConfidentRegions restricts the comparison to the records it overlaps, when set.
+	ConfidentRegions *IntervalSet
+
+	// Reference, when set, is used to left-align indels, so that indels in repeats written at different
+	// positions are compared equal. Otherwise alleles are only trimmed to their minimal representation.
+	Reference *FASTA
+
+	// HaplotypeMatching matches the variants left without an allele match when, within clusters of nearby
+	// variants, both sides spell the same haplotypes on the Reference, which it requires. This finds calls
+	// written differently, such as an MNP written as separate SNVs or complex indels in repeats. With
+	// MatchGenotype, haplotypes are built from the GT of each side, trying both orientations of unphased
+	// heterozygous calls. Otherwise every variant of a cluster is applied to the same haplotype.
+	HaplotypeMatching bool
+
+	// ClusterDistance is the largest number of bases between variants of the same cluster for
+	// HaplotypeMatching. Zero means DefaultClusterDistance.
+	ClusterDistance int
+
+	// PassOnly skips query records with a FILTER other than PASS.
+	PassOnly bool
+
+	// TruthOutput and QueryOutput, when set, receive the records of each input with an INFO/BENCH key telling
+	// whether they are true positives (TP), false negatives (FN), false positives (FP), not assessed (N) or
+	// outside the confident regions (UNK).
+	TruthOutput io.Writer
+	QueryOutput io.Writer
+}
+
+// ComparisonCounts holds the outcome of a comparison for a class of variants.
+type ComparisonCounts struct {
+	// TruePositives counts the truth variants found on the query, and QueryTruePositives the query variants found
+	// on the truth set. They only differ when a variant of one side matches several variants of the other.
+	TruePositives      int
+	QueryTruePositives int
+	FalsePositives     int
+	FalseNegatives     int
+}
+
+// Precision is the fraction of query variants found on the truth set, or zero when there are none.
+func (c *ComparisonCounts) Precision() float64 {
+	return ratio(c.QueryTruePositives, c.QueryTruePositives+c.FalsePositives)
+}
+
+// Recall is the fraction of truth variants found on the query, or zero when there are none.
+func (c *ComparisonCounts) Recall() float64 {
+	return ratio(c.TruePositives, c.TruePositives+c.FalseNegatives)
+}
+
+// F1 is the harmonic mean of Precision and Recall.
+func (c *ComparisonCounts) F1() float64 {
+	precision, recall := c.Precision(), c.Recall()
+	if precision+recall == 0 {
+		return 0
+	}
+	return 2 * precision * recall / (precision + recall)
+}
+
+func ratio(numerator, denominator int) float64 {
+	if denominator == 0 {
+		return 0
+	}
+	return float64(numerator) / float64(denominator)
+}
+
+// ComparisonReport is the result of Compare.
+type ComparisonReport struct {
+	Total   ComparisonCounts
+	Classes map[VariantClass]*ComparisonCounts
+}
+
+func (r *ComparisonReport) add(class VariantClass, status string, query bool) {
+	counts, found := r.Classes[class]
+	if !found {
+		counts = &ComparisonCounts{}
+		r.Classes[class] = counts
+	}
+	for _, c := range []*ComparisonCounts{&r.Total, counts} {
+		switch status {
+		case benchTruePositive:
+			if query {
+				c.QueryTruePositives++
+			} else {
+				c.TruePositives++
+			}
+		case benchFalsePositive:
+			c.FalsePositives++
+		case benchFalseNegative:
+			c.FalseNegatives++
+		}
+	}
+}
+
+// String formats the report as a table, with the totals followed by a line for each class found.
+func (r *ComparisonReport) String() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%-6s %9s %9s %9s %9s %9s %9s\n", "class", "TP", "FP", "FN", "precision", "recall", "F1")
+	line := func(name string, c *ComparisonCounts) {
+		fmt.Fprintf(&builder, "%-6s %9d %9d %9d %9.4f %9.4f %9.4f\n", name, c.TruePositives, c.FalsePositives,
+			c.FalseNegatives, c.Precision(), c.Recall(), c.F1())
+	}
+	line("ALL", &r.Total)
+	for _, class := range variantClasses {
+		if counts, found := r.Classes[class]; found {
+			line(string(class), counts)
+		}
+	}
+	return builder.String()
+}
+
+// Compare benchmarks a query VCF, such as the calls of a pipeline, against a truth set, such as the ones of
+// Genome in a Bottle. Both files must be sorted.
+//
+// Multiple alternatives are split and the alleles of both files are normalized before they are matched by
+// position, REF and ALT, optionally along with their genotypes, and then, with HaplotypeMatching, by the
+// haplotypes they spell. Truth variants with a match are true positives
+// and the ones without are false negatives, while query variants without a match are false positives.
+//
+// Lines that cannot be parsed are skipped and reported in the returned error once the comparison is done.
+func Compare(truth, query io.Reader, options CompareOptions) (*ComparisonReport, error) {
+	streams, err := openVariantStreams([]io.Reader{truth, query})
+	if err != nil {
+		return nil, err
+	}
+	comparison, err := newComparison(streams[0].header, streams[1].header, options)
+	if err != nil {
+		closeVariantStreams(streams)
+		return nil, err
+	}
+
+	order := newContigOrder(streams[0].header, streams[1].header)
+	for {
+		chrom, _, found := nextLocus(streams, order)
+		if !found {
+			break
+		}
+		truthVariants := streams[0].popChrom(chrom)
+		queryVariants := streams[1].popChrom(chrom)
+		if err := comparison.compareChrom(chrom, truthVariants, queryVariants); err != nil {
+			closeVariantStreams(streams)
+			return nil, err
+		}
+	}
+
+	if err := comparison.flush(); err != nil {
+		closeVariantStreams(streams)
+		return nil, err
+	}
+	return comparison.report, closeVariantStreams(streams)
+}
+
+// popChrom consumes and returns every variant of the stream up to the next chromosome.
+func (s *variantStream) popChrom(chrom string) []*Variant {
+	var variants []*Variant
+	for variant := s.peek(); variant != nil && normalizeChrom(variant.Chrom) == normalizeChrom(chrom); variant = s.peek() {
+		variants = append(variants, s.next())
+	}
+	return variants
+}
+
+// comparison holds the state of Compare across chromosomes.
+type comparison struct {
+	options       CompareOptions
+	truthSample   int
+	querySample   int
+	truthWriter   *Writer
+	queryWriter   *Writer
+	report        *ComparisonReport
+	truthVariants []*comparedVariant
+	queryVariants []*comparedVariant
+}
+
+// comparedVariant is a variant along with its normalized alleles, the number of copies of its alternative allele
+// on the compared sample, or -1 when unknown, and its outcome.
+type comparedVariant struct {
+	variant  *Variant
+	pos      int
+	ref, alt string
+	dosage   int
+	genotype *Genotype
+	class    VariantClass
+	status   string
+}
+
+func newComparison(truthHeader, queryHeader *Header, options CompareOptions) (*comparison, error) {
+	c := &comparison{options: options, report: &ComparisonReport{Classes: make(map[VariantClass]*ComparisonCounts)}}
+	var err error
+	if c.truthSample, err = sampleIndex(truthHeader, options.TruthSample); err != nil {
+		return nil, fmt.Errorf("truth set: %v", err)
+	}
+	if c.querySample, err = sampleIndex(queryHeader, options.QuerySample); err != nil {
+		return nil, fmt.Errorf("query: %v", err)
+	}
+	if options.MatchGenotype && (c.truthSample < 0 || c.querySample < 0) {
+		return nil, errors.New("matching genotypes requires samples on both files")
+	}
+	if options.HaplotypeMatching && options.Reference == nil {
+		return nil, errors.New("matching haplotypes requires a reference")
+	}
+	if c.truthWriter, err = benchWriter(options.TruthOutput, truthHeader); err != nil {
+		return nil, err
+	}
+	if c.queryWriter, err = benchWriter(options.QueryOutput, queryHeader); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// sampleIndex finds the column of the named sample, or of the first one when name is empty. It returns -1 for
+// files without samples.
+func sampleIndex(header *Header, name string) (int, error) {
+	samples := header.SampleIDs()
+	if name == "" {
+		if len(samples) == 0 {
+			return -1, nil
+		}
+		return 0, nil
+	}
+	for i, sample := range samples {
+		if sample == name {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("sample %s not found", name)
+}
+
+func benchWriter(w io.Writer, header *Header) (*Writer, error) {
+	if w == nil {
+		return nil, nil
+	}
+	if _, declared := header.Infos["BENCH"]; !declared {
+		header.AddMetaLine(`##INFO=<ID=BENCH,Number=1,Type=String,Description="Comparison outcome: TP, FP, FN, N for records not assessed or UNK for records outside the confident regions">`)
+	}
+	return NewWriter(w, header)
+}
+
+func (c *comparison) compareChrom(chrom string, truth, query []*Variant) error {
+	var err error
+	if c.truthVariants, err = c.prepare(truth, c.truthSample, false); err != nil {
+		return err
+	}
+	if c.queryVariants, err = c.prepare(query, c.querySample, c.options.PassOnly); err != nil {
+		return err
+	}
+	c.matchAlleles()
+	if c.options.HaplotypeMatching {
+		if err := c.matchHaplotypes(chrom); err != nil {
+			return err
+		}
+	}
+	for _, compared := range c.truthVariants {
+		if compared.status == "" {
+			compared.status = benchFalseNegative
+		}
+		c.report.add(compared.class, compared.status, false)
+	}
+	for _, compared := range c.queryVariants {
+		if compared.status == "" {
+			compared.status = benchFalsePositive
+		}
+		c.report.add(compared.class, compared.status, true)
+	}
+	return c.write()
+}
+
+// prepare normalizes the variants and sets apart the ones that are not assessed.
+func (c *comparison) prepare(variants []*Variant, sample int, passOnly bool) ([]*comparedVariant, error) {
+	prepared := make([]*comparedVariant, len(variants))
+	for i, variant := range variants {
+		compared := &comparedVariant{variant: variant, dosage: -1}
+		prepared[i] = compared
+		if sample >= 0 && sample < len(variant.Samples) {
+			if genotype, err := ParseGenotype(variant.Samples[sample]["GT"]); err == nil {
+				compared.genotype = genotype.biallelic(variant.AlleleIndex)
+				compared.dosage = 0
+				for _, allele := range genotype.Alleles {
+					if allele == variant.AlleleIndex {
+						compared.dosage++
+					}
+				}
+			}
+		}
+
+		switch {
+		case c.options.ConfidentRegions != nil && !c.options.ConfidentRegions.OverlapsVariant(variant):
+			compared.status = benchOutside
+		case compared.dosage == 0 || (passOnly && variant.Filter != "PASS" && variant.Filter != "" && variant.Filter != "."):
+			compared.status = benchNotAssessed
+		}
+
+		var err error
+		compared.pos, compared.ref, compared.alt, err = normalizeAlleles(variant.Chrom, variant.Pos, variant.Ref, variant.Alt, c.options.Reference)
+		if err != nil {
+			return nil, fmt.Errorf("unable to normalize %s:%d: %v", variant.Chrom, variant.Pos+1, err)
+		}
+		compared.class = classifyAlleles(compared.ref, compared.alt)
+	}
+	return prepared, nil
+}
+
+// matchAlleles pairs the assessed truth and query variants with the same normalized alleles.
+func (c *comparison) matchAlleles() {
+	type alleles struct {
+		pos      int
+		ref, alt string
+	}
+	truth := make(map[alleles][]*comparedVariant)
+	for _, compared := range c.truthVariants {
+		if compared.status == "" {
+			key := alleles{compared.pos, compared.ref, compared.alt}
+			truth[key] = append(truth[key], compared)
+		}
+	}
+	for _, compared := range c.queryVariants {
+		if compared.status != "" {
+			continue
+		}
+		for _, candidate := range truth[alleles{compared.pos, compared.ref, compared.alt}] {
+			if candidate.status == "" && (!c.options.MatchGenotype || candidate.dosage == compared.dosage) {
+				candidate.status, compared.status = benchTruePositive, benchTruePositive
+				break
+			}
+		}
+	}
+}
+
+func (c *comparison) write() error {
+	for _, output := range []struct {
+		writer   *Writer
+		variants []*comparedVariant
+	}{{c.truthWriter, c.truthVariants}, {c.queryWriter, c.queryVariants}} {
+		if output.writer == nil {
+			continue
+		}
+		for _, compared := range output.variants {
+			annotated := *compared.variant
+			annotated.Info = make(map[string]interface{}, len(compared.variant.Info)+1)
+			for key, value := range compared.variant.Info {
+				annotated.Info[key] = value
+			}
+			annotated.Info["BENCH"] = compared.status
+			if err := output.writer.Write(&annotated); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *comparison) flush() error {
+	for _, writer := range []*Writer{c.truthWriter, c.queryWriter} {
+		if writer != nil {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// normalizeAlleles reduces the alleles to their minimal representation and, when a reference is given, shifts
+// indels to their leftmost position, anchored on the base before them as VCF does.
+func normalizeAlleles(chrom string, pos int, ref, alt string, reference *FASTA) (int, string, string, error) {
+	ref, alt = strings.ToUpper(ref), strings.ToUpper(alt)
+	pos, ref, alt = minimalRepresentation(pos, ref, alt)
+	if reference == nil || len(ref) == len(alt) || ref[0] != alt[0] || isSymbolicAllele(alt) || isBreakendAllele(alt) {
+		return pos, ref, alt, nil
+	}
+
+	// without the anchor base, the inserted or deleted sequence can be rotated left while it ends with the
+	// base before it
+	start, sequence := pos+1, ref[1:]+alt[1:]
+	for start > 1 {
+		base, err := reference.Bases(chrom, start-1, start)
+		if err != nil {
+			return 0, "", "", err
+		}
+		if base[0] != sequence[len(sequence)-1] {
+			break
+		}
+		sequence = base + sequence[:len(sequence)-1]
+		start--
+	}
+	if start == pos+1 {
+		return pos, ref, alt, nil
+	}
+	anchor, err := reference.Bases(chrom, start-1, start)
+	if err != nil {
+		return 0, "", "", err
+	}
+	if len(ref) > len(alt) {
+		return start - 1, anchor + sequence, anchor, nil
+	}
+	return start - 1, anchor, anchor + sequence, nil
+}
@@ -0,0 +1,143 @@
+package vcf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CompareSuite struct {
+	suite.Suite
+}
+
+const compareTruth = `##fileformat=VCFv4.2
+##contig=<ID=chr1,length=20>
+##contig=<ID=chr2,length=20>
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	TRUTH
+chr1	2	.	G	C	.	PASS	.	GT	0/1
+chr1	4	.	AT	A	.	PASS	.	GT	1/1
+chr1	9	.	GC	AT	.	PASS	.	GT	0/1
+chr1	13	.	G	A,T	.	PASS	.	GT	1/2
+chr1	18	.	G	T	.	PASS	.	GT	0/0
+chr2	5	.	A	G	.	PASS	.	GT	0/1
+`
+
+const compareQuery = `##fileformat=VCFv4.2
+##contig=<ID=chr1,length=20>
+##contig=<ID=chr2,length=20>
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	OTHER	QUERY
+chr1	2	.	G	C	50	PASS	.	GT	0/0	0/1
+chr1	7	.	TT	T	50	PASS	.	GT	0/0	0/1
+chr1	9	.	G	A	50	PASS	.	GT	0/0	0/1
+chr1	13	.	G	A	50	PASS	.	GT	0/0	0/1
+chr1	15	.	A	C	50	LowQual	.	GT	0/0	0/1
+chr2	5	.	A	G	50	PASS	.	GT	0/0	0/1
+`
+
+func (s *CompareSuite) reference() *FASTA {
+	fasta, err := ReadFASTA(strings.NewReader(">chr1\nGGCATTTTGCAGGCATGCAG\n"))
+	assert.NoError(s.T(), err)
+	return fasta
+}
+
+func (s *CompareSuite) TestAlleleMatching() {
+	report, err := Compare(strings.NewReader(compareTruth), strings.NewReader(compareQuery), CompareOptions{QuerySample: "QUERY"})
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), ComparisonCounts{TruePositives: 3, QueryTruePositives: 3, FalsePositives: 3, FalseNegatives: 3}, report.Total)
+	assert.Equal(s.T(), &ComparisonCounts{TruePositives: 3, QueryTruePositives: 3, FalsePositives: 2, FalseNegatives: 1}, report.Classes[ClassSNV])
+	assert.Equal(s.T(), &ComparisonCounts{FalsePositives: 1, FalseNegatives: 1}, report.Classes[ClassIndel])
+	assert.Equal(s.T(), &ComparisonCounts{FalseNegatives: 1}, report.Classes[ClassMNP])
+}
+
+func (s *CompareSuite) TestNormalizedRegions() {
+	var truthOutput, queryOutput bytes.Buffer
+	options := CompareOptions{
+		QuerySample:      "QUERY",
+		ConfidentRegions: NewIntervalSet([]Interval{{Chrom: "chr1", Start: 0, End: 20}}),
+		Reference:        s.reference(),
+		PassOnly:         true,
+		TruthOutput:      &truthOutput,
+		QueryOutput:      &queryOutput,
+	}
+	report, err := Compare(strings.NewReader(compareTruth), strings.NewReader(compareQuery), options)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), ComparisonCounts{TruePositives: 3, QueryTruePositives: 3, FalsePositives: 1, FalseNegatives: 2}, report.Total)
+	assert.Equal(s.T(), &ComparisonCounts{TruePositives: 1, QueryTruePositives: 1}, report.Classes[ClassIndel])
+	assert.Equal(s.T(), 0.75, report.Total.Precision())
+	assert.Equal(s.T(), 0.6, report.Total.Recall())
+
+	assert.Contains(s.T(), truthOutput.String(), "##INFO=<ID=BENCH,")
+	for _, expected := range []string{
+		"chr1\t2\t.\tG\tC\t.\tPASS\tBENCH=TP\tGT\t0/1",
+		"chr1\t4\t.\tAT\tA\t.\tPASS\tBENCH=TP\tGT\t1/1",
+		"chr1\t9\t.\tGC\tAT\t.\tPASS\tBENCH=FN\tGT\t0/1",
+		"chr1\t13\t.\tG\tA\t.\tPASS\tBENCH=TP\tGT\t1/.",
+		"chr1\t13\t.\tG\tT\t.\tPASS\tBENCH=FN\tGT\t./1",
+		"chr1\t18\t.\tG\tT\t.\tPASS\tBENCH=N\tGT\t0/0",
+		"chr2\t5\t.\tA\tG\t.\tPASS\tBENCH=UNK\tGT\t0/1",
+	} {
+		assert.Contains(s.T(), truthOutput.String(), expected+"\n")
+	}
+	for _, expected := range []string{
+		"chr1\t7\t.\tTT\tT\t50\tPASS\tBENCH=TP",
+		"chr1\t9\t.\tG\tA\t50\tPASS\tBENCH=FP",
+		"chr1\t15\t.\tA\tC\t50\tLowQual\tBENCH=N",
+	} {
+		assert.Contains(s.T(), queryOutput.String(), expected+"\t")
+	}
+}
+
+func (s *CompareSuite) TestGenotypeMatching() {
+	options := CompareOptions{QuerySample: "QUERY", MatchGenotype: true, Reference: s.reference(), PassOnly: true}
+	report, err := Compare(strings.NewReader(compareTruth), strings.NewReader(compareQuery), options)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), &ComparisonCounts{FalsePositives: 1, FalseNegatives: 1}, report.Classes[ClassIndel])
+	assert.Equal(s.T(), &ComparisonCounts{TruePositives: 3, QueryTruePositives: 3, FalsePositives: 1, FalseNegatives: 1}, report.Classes[ClassSNV])
+
+	_, err = Compare(strings.NewReader(compareTruth), strings.NewReader(compareQuery), CompareOptions{QuerySample: "NA12878"})
+	assert.EqualError(s.T(), err, "query: sample NA12878 not found")
+}
+
+func (s *CompareSuite) TestNormalizeAlleles() {
+	reference := s.reference()
+	for _, alleles := range []struct {
+		pos      int
+		ref, alt string
+		expected []interface{}
+	}{
+		{6, "TT", "T", []interface{}{3, "AT", "A"}},
+		{7, "T", "TT", []interface{}{3, "A", "AT"}},
+		{5, "TTTG", "TTG", []interface{}{3, "AT", "A"}},
+		{8, "GC", "AT", []interface{}{8, "GC", "AT"}},
+		{9, "CA", "CA", []interface{}{9, "C", "C"}},
+		{1, "G", "<DEL>", []interface{}{1, "G", "<DEL>"}},
+	} {
+		pos, ref, alt, err := normalizeAlleles("1", alleles.pos, alleles.ref, alleles.alt, reference)
+		assert.NoError(s.T(), err)
+		assert.Equal(s.T(), alleles.expected, []interface{}{pos, ref, alt})
+	}
+
+	pos, ref, alt, err := normalizeAlleles("1", 6, "tt", "t", nil)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []interface{}{6, "TT", "T"}, []interface{}{pos, ref, alt})
+}
+
+func (s *CompareSuite) TestReport() {
+	report := &ComparisonReport{Total: ComparisonCounts{TruePositives: 3, QueryTruePositives: 3, FalsePositives: 1, FalseNegatives: 1}, Classes: map[VariantClass]*ComparisonCounts{
+		ClassIndel: {TruePositives: 1, QueryTruePositives: 1, FalseNegatives: 1},
+		ClassSNV:   {TruePositives: 2, QueryTruePositives: 2, FalsePositives: 1},
+	}}
+	assert.Equal(s.T(), 0.75, report.Total.F1())
+	assert.Equal(s.T(), 0.0, (&ComparisonCounts{}).F1())
+	assert.Equal(s.T(), "class         TP        FP        FN precision    recall        F1\n"+
+		"ALL            3         1         1    0.7500    0.7500    0.7500\n"+
+		"SNV            2         1         0    0.6667    1.0000    0.8000\n"+
+		"INDEL          1         0         1    1.0000    0.5000    0.6667\n", report.String())
+}
+
+func TestCompareSuite(t *testing.T) {
+	suite.Run(t, new(CompareSuite))
+}
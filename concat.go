@@ -0,0 +1,311 @@
+package vcf
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ConcatOptions configures Concat.
+type ConcatOptions struct {
+	// RemoveDuplicates drops the records at the start of an input that were already written by the previous
+	// inputs, as produced by calling over overlapping intervals. Records on the last contig of the previous
+	// input, between its first and last positions there, are dropped when a record with the same CHROM, POS,
+	// REF and ALT was written, and make Concat fail otherwise. The alleles written on the current contig are
+	// kept in memory to find them. Without it, overlapping inputs make Concat fail. Records before the span of
+	// the previous input are an error either way.
+	RemoveDuplicates bool
+}
+
+// Concat joins VCFs holding the same samples over different regions, such as the per chromosome or per
+// interval outputs of a scattered calling, into a single VCF written to w.
+//
+// Every input must have the same samples, in the same order. Their headers are unified as in Merge, and the
+// definitions that disagree between inputs are returned as conflicts, keeping the first one. The inputs must
+// be given in coordinate order, following the ##contig lines, and records are copied as they are, without
+// being parsed beyond CHROM, POS, REF and ALT. Concat fails on the first record that is out of order.
+func Concat(readers []io.Reader, w io.Writer, options ConcatOptions) ([]HeaderConflict, error) {
+	if len(readers) == 0 {
+		return nil, errors.New("no VCF to concatenate")
+	}
+
+	inputs := make([]*bufio.Reader, len(readers))
+	headers := make([]*Header, len(readers))
+	for i, reader := range readers {
+		inputs[i] = bufio.NewReaderSize(reader, 100*1024)
+		header, err := vcfHeader(inputs[i])
+		if err != nil {
+			return nil, fmt.Errorf("input %d: %v", i+1, err)
+		}
+		if i > 0 {
+			if err := checkSameSamples(headers[0], header); err != nil {
+				return nil, fmt.Errorf("input %d: %v", i+1, err)
+			}
+		}
+		headers[i] = header
+	}
+
+	header, conflicts := mergeHeaders(headers)
+	header.Columns = headers[0].Columns
+	writer := bufio.NewWriterSize(w, 100*1024)
+	if _, err := writer.WriteString(header.String()); err != nil {
+		return conflicts, err
+	}
+
+	concat := &concatenation{order: newContigOrder(headers...), written: make(map[alleleKey]bool), options: options}
+	for i, input := range inputs {
+		lineNumber := headers[i].lineCount
+		if i > 0 {
+			concat.startInput()
+		}
+		for {
+			line, err := input.ReadString('\n')
+			if err != nil && err != io.EOF {
+				return conflicts, err
+			}
+			if line == "" && err == io.EOF {
+				break
+			}
+			lineNumber++
+			if !isHeaderLine(line) {
+				keep, recordErr := concat.accept(line)
+				if recordErr != nil {
+					return conflicts, fmt.Errorf("input %d, line %d: %v", i+1, lineNumber, recordErr)
+				}
+				if keep {
+					if !strings.HasSuffix(line, "\n") {
+						line += "\n"
+					}
+					if _, err := writer.WriteString(line); err != nil {
+						return conflicts, err
+					}
+				}
+			}
+			if err == io.EOF {
+				break
+			}
+		}
+	}
+
+	return conflicts, writer.Flush()
+}
+
+// concatenation tracks the last position written by Concat, to check the order of the records and find
+// duplicates between inputs.
+type concatenation struct {
+	order   *contigOrder
+	options ConcatOptions
+
+	chrom   string
+	pos     int
+	started bool
+	written map[alleleKey]bool
+
+	// contigAlleles holds the alleles written on the current contig, only kept with RemoveDuplicates
+	contigAlleles map[locusKey]bool
+
+	// spanStart is the first position written by the current input on the current contig, and previousStart
+	// the one of the previous input, which ends at chrom:pos while boundary is true.
+	spanStart     int
+	previousStart int
+	inputStarted  bool
+
+	// boundary is true from the start of an input until it writes a record past the previous inputs
+	boundary bool
+}
+
+// startInput marks the start of the records of an input other than the first one.
+func (c *concatenation) startInput() {
+	c.boundary = c.started
+	c.previousStart = c.spanStart
+	c.inputStarted = false
+}
+
+// accept tells whether a record should be written, failing when it is out of order.
+func (c *concatenation) accept(line string) (bool, error) {
+	chrom, pos, key, err := recordLocus(line)
+	if err != nil {
+		return false, err
+	}
+
+	comparison := 1
+	if c.started {
+		comparison = c.order.compareLoci(chrom, pos, c.chrom, c.pos)
+	}
+	switch {
+	case comparison > 0:
+		if !c.inputStarted || chrom != c.chrom {
+			c.spanStart = pos
+		}
+		if chrom != c.chrom {
+			c.contigAlleles = nil
+		}
+		c.chrom, c.pos, c.started, c.boundary, c.inputStarted = chrom, pos, true, false, true
+		c.written = map[alleleKey]bool{key: true}
+		c.remember(pos, key)
+		return true, nil
+	case comparison == 0 && !(c.boundary && c.written[key]):
+		if !c.inputStarted {
+			c.spanStart, c.inputStarted = pos, true
+		}
+		c.written[key] = true
+		c.remember(pos, key)
+		return true, nil
+	case !c.boundary:
+		return false, fmt.Errorf("record at %s:%d comes after %s:%d", chrom, pos, c.chrom, c.pos)
+	case chrom == c.chrom && pos >= c.previousStart:
+		if c.contigAlleles[locusKey{pos: pos, allele: key}] {
+			return false, nil
+		}
+		if c.options.RemoveDuplicates {
+			return false, fmt.Errorf("record at %s:%d overlaps the previous input, which ends at %s:%d, without being one of its records", chrom, pos, c.chrom, c.pos)
+		}
+		return false, fmt.Errorf("record at %s:%d overlaps the previous input, which ends at %s:%d", chrom, pos, c.chrom, c.pos)
+	}
+	return false, fmt.Errorf("record at %s:%d comes before the end of the previous input at %s:%d, the inputs are out of order", chrom, pos, c.chrom, c.pos)
+}
+
+// locusKey identifies an allele written on the current contig.
+type locusKey struct {
+	pos    int
+	allele alleleKey
+}
+
+// remember keeps an allele written on the current contig, to find its duplicates with RemoveDuplicates.
+func (c *concatenation) remember(pos int, key alleleKey) {
+	if !c.options.RemoveDuplicates {
+		return
+	}
+	if c.contigAlleles == nil {
+		c.contigAlleles = make(map[locusKey]bool)
+	}
+	c.contigAlleles[locusKey{pos: pos, allele: key}] = true
+}
+
+// recordLocus reads the normalized CHROM, POS, REF and ALT of a record without parsing the rest of it.
+func recordLocus(line string) (chrom string, pos int, key alleleKey, err error) {
+	fields := strings.SplitN(line, "\t", 6)
+	if len(fields) < 5 {
+		return "", 0, key, errors.New("wrong number of columns")
+	}
+	pos, err = strconv.Atoi(fields[1])
+	if err != nil {
+		return "", 0, key, errors.New("unable to parse POS: " + fields[1])
+	}
+	return normalizeChrom(fields[0]), pos, alleleKey{ref: fields[3], alt: strings.TrimSpace(fields[4])}, nil
+}
+
+// checkSameSamples fails unless both headers have the same samples in the same order.
+func checkSameSamples(first, other *Header) error {
+	firstSamples, otherSamples := first.SampleIDs(), other.SampleIDs()
+	if len(firstSamples) != len(otherSamples) {
+		return fmt.Errorf("expected %d samples, found %d", len(firstSamples), len(otherSamples))
+	}
+	for i := range firstSamples {
+		if firstSamples[i] != otherSamples[i] {
+			return fmt.Errorf("expected sample %s at column %d, found %s", firstSamples[i], i+10, otherSamples[i])
+		}
+	}
+	return nil
+}
+
+// ConcatBGZF is a fast version of Concat for bgzip compressed inputs, which copies the compressed blocks
+// without decompressing and compressing them again. The header of the first input is written as it is, and
+// the inputs must have the same samples in the same order, but neither the rest of the headers nor the
+// order of the records are checked. The output is bgzip compressed as well.
+func ConcatBGZF(readers []io.Reader, w io.Writer) error {
+	if len(readers) == 0 {
+		return errors.New("no VCF to concatenate")
+	}
+
+	var first *Header
+	for i, reader := range readers {
+		header, err := copyBGZFRecords(reader, w, i == 0)
+		if err != nil {
+			return fmt.Errorf("input %d: %v", i+1, err)
+		}
+		if first == nil {
+			first = header
+		} else if err := checkSameSamples(first, header); err != nil {
+			return fmt.Errorf("input %d: %v", i+1, err)
+		}
+	}
+
+	_, err := w.Write(bgzfEOF)
+	return err
+}
+
+// copyBGZFRecords copies the records of a BGZF input to w, and its header as well when writeHeader is true.
+// Only the block where the header ends is compressed again; the following ones are copied as they are.
+func copyBGZFRecords(reader io.Reader, w io.Writer, writeHeader bool) (*Header, error) {
+	var headerData []byte
+	var header *Header
+	for {
+		block, err := readBGZFBlock(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header != nil {
+			if len(block.data) > 0 {
+				if _, err := w.Write(block.raw); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		headerData = append(headerData, block.data...)
+		end, complete := bgzfHeaderEnd(headerData)
+		if !complete {
+			continue
+		}
+		if header, err = writeBGZFHeader(headerData, end, w, writeHeader); err != nil {
+			return nil, err
+		}
+	}
+
+	if header == nil {
+		// a file without records ends with its header
+		return writeBGZFHeader(headerData, len(headerData), w, writeHeader)
+	}
+	return header, nil
+}
+
+// writeBGZFHeader parses the header found on data[:end], writes it when requested, and compresses again any
+// record found on the rest of data.
+func writeBGZFHeader(data []byte, end int, w io.Writer, writeHeader bool) (*Header, error) {
+	header, err := vcfHeader(bufio.NewReader(bytes.NewReader(data[:end])))
+	if err != nil {
+		return nil, err
+	}
+	if writeHeader {
+		if err := writeBGZFBlocks(w, data[:end]); err != nil {
+			return nil, err
+		}
+	}
+	return header, writeBGZFBlocks(w, data[end:])
+}
+
+// bgzfHeaderEnd finds where the header lines end, which is only known once the start of the first record is
+// found.
+func bgzfHeaderEnd(data []byte) (int, bool) {
+	start := 0
+	for start < len(data) {
+		if data[start] != '#' {
+			return start, true
+		}
+		newline := bytes.IndexByte(data[start:], '\n')
+		if newline < 0 {
+			return 0, false
+		}
+		start += newline + 1
+	}
+	return 0, false
+}
@@ -0,0 +1,172 @@
+package vcf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+const chunkHeader = `##fileformat=VCFv4.2
+##contig=<ID=chr2,length=1000>
+##contig=<ID=chr1,length=1000>
+##INFO=<ID=DP,Number=1,Type=Integer,Description="Depth">
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	S1	S2
+`
+
+type ConcatSuite struct {
+	suite.Suite
+}
+
+func (s *ConcatSuite) readers(chunks ...string) []io.Reader {
+	readers := make([]io.Reader, len(chunks))
+	for i, chunk := range chunks {
+		readers[i] = strings.NewReader(chunkHeader + chunk)
+	}
+	return readers
+}
+
+func (s *ConcatSuite) records(output string) []string {
+	records := make([]string, 0)
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			records = append(records, line)
+		}
+	}
+	return records
+}
+
+func (s *ConcatSuite) TestConcat() {
+	var buffer bytes.Buffer
+	conflicts, err := Concat(s.readers(
+		"chr2\t10\t.\tA\tG\t50\tPASS\tDP=3\tGT\t0/1\t0/0\n",
+		"chr1\t5\t.\tC\tT\t50\tPASS\tDP=4\tGT\t1/1\t0/1", // no newline at the end
+		"chr1\t8\t.\tG\tA,C\t50\tPASS\tDP=5\tGT\t1/2\t0/1\n",
+	), &buffer, ConcatOptions{})
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), conflicts)
+
+	output := buffer.String()
+	assert.Equal(s.T(), 1, strings.Count(output, "##INFO=<ID=DP"))
+	assert.Contains(s.T(), output, "#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tS1\tS2\n")
+	assert.Equal(s.T(), []string{
+		"chr2\t10\t.\tA\tG\t50\tPASS\tDP=3\tGT\t0/1\t0/0",
+		"chr1\t5\t.\tC\tT\t50\tPASS\tDP=4\tGT\t1/1\t0/1",
+		"chr1\t8\t.\tG\tA,C\t50\tPASS\tDP=5\tGT\t1/2\t0/1",
+	}, s.records(output), "records should be copied as they are")
+}
+
+func (s *ConcatSuite) TestOutOfOrder() {
+	_, err := Concat(s.readers(
+		"chr1\t5\t.\tC\tT\t50\tPASS\t.\tGT\t1/1\t0/1\n",
+		"chr2\t10\t.\tA\tG\t50\tPASS\t.\tGT\t0/1\t0/0\n",
+	), io.Discard, ConcatOptions{})
+	assert.Error(s.T(), err, "chr2 is declared before chr1")
+
+	_, err = Concat(s.readers(
+		"chr1\t8\t.\tC\tT\t50\tPASS\t.\tGT\t1/1\t0/1\nchr1\t5\t.\tC\tT\t50\tPASS\t.\tGT\t1/1\t0/1\n",
+	), io.Discard, ConcatOptions{})
+	assert.EqualError(s.T(), err, "input 1, line 7: record at 1:5 comes after 1:8")
+}
+
+func (s *ConcatSuite) TestOverlappingChunks() {
+	chunks := []string{
+		"chr1\t5\t.\tC\tT\t50\tPASS\t.\tGT\t1/1\t0/1\nchr1\t8\t.\tG\tA\t50\tPASS\t.\tGT\t0/1\t0/1\n",
+		"chr1\t5\t.\tC\tT\t50\tPASS\t.\tGT\t1/1\t0/1\nchr1\t8\t.\tG\tA\t50\tPASS\t.\tGT\t0/1\t0/1\n" +
+			"chr1\t8\t.\tG\tC\t50\tPASS\t.\tGT\t0/1\t0/1\nchr1\t9\t.\tT\tA\t50\tPASS\t.\tGT\t0/1\t0/1\n",
+	}
+
+	_, err := Concat(s.readers(chunks...), io.Discard, ConcatOptions{})
+	assert.EqualError(s.T(), err, "input 2, line 6: record at 1:5 overlaps the previous input, which ends at 1:8")
+
+	var buffer bytes.Buffer
+	_, err = Concat(s.readers(chunks...), &buffer, ConcatOptions{RemoveDuplicates: true})
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{
+		"chr1\t5\t.\tC\tT\t50\tPASS\t.\tGT\t1/1\t0/1",
+		"chr1\t8\t.\tG\tA\t50\tPASS\t.\tGT\t0/1\t0/1",
+		"chr1\t8\t.\tG\tC\t50\tPASS\t.\tGT\t0/1\t0/1",
+		"chr1\t9\t.\tT\tA\t50\tPASS\t.\tGT\t0/1\t0/1",
+	}, s.records(buffer.String()))
+
+	// records on the overlap that the previous input did not write are not duplicates
+	_, err = Concat(s.readers(
+		chunks[0],
+		"chr1\t5\t.\tC\tT\t50\tPASS\t.\tGT\t1/1\t0/1\nchr1\t6\t.\tA\tG\t50\tPASS\t.\tGT\t0/1\t0/1\n",
+	), io.Discard, ConcatOptions{RemoveDuplicates: true})
+	assert.EqualError(s.T(), err, "input 2, line 7: record at 1:6 overlaps the previous input, which ends at 1:8, without being one of its records")
+
+	_, err = Concat(s.readers(
+		chunks[0],
+		"chr1\t5\t.\tC\tG\t50\tPASS\t.\tGT\t1/1\t0/1\n",
+	), io.Discard, ConcatOptions{RemoveDuplicates: true})
+	assert.EqualError(s.T(), err, "input 2, line 6: record at 1:5 overlaps the previous input, which ends at 1:8, without being one of its records")
+}
+
+func (s *ConcatSuite) TestInputsOutOfOrder() {
+	chunks := []string{
+		"chr2\t10\t.\tA\tG\t50\tPASS\t.\tGT\t0/1\t0/0\n",
+		"chr1\t5\t.\tC\tT\t50\tPASS\t.\tGT\t1/1\t0/1\n",
+	}
+	_, err := Concat(s.readers(chunks[1], chunks[0]), io.Discard, ConcatOptions{RemoveDuplicates: true})
+	assert.EqualError(s.T(), err, "input 2, line 6: record at 2:10 comes before the end of the previous input at 1:5, the inputs are out of order")
+
+	// records before the span of the previous input on the same contig are not duplicates
+	_, err = Concat(s.readers(
+		"chr1\t5\t.\tC\tT\t50\tPASS\t.\tGT\t1/1\t0/1\nchr1\t8\t.\tG\tA\t50\tPASS\t.\tGT\t0/1\t0/1\n",
+		"chr1\t3\t.\tC\tT\t50\tPASS\t.\tGT\t1/1\t0/1\n",
+	), io.Discard, ConcatOptions{RemoveDuplicates: true})
+	assert.EqualError(s.T(), err, "input 2, line 6: record at 1:3 comes before the end of the previous input at 1:8, the inputs are out of order")
+}
+
+func (s *ConcatSuite) TestDifferentSamples() {
+	other := strings.Replace(chunkHeader, "S1\tS2", "S2\tS1", 1)
+	_, err := Concat([]io.Reader{strings.NewReader(chunkHeader), strings.NewReader(other)}, io.Discard, ConcatOptions{})
+	assert.EqualError(s.T(), err, "input 2: expected sample S1 at column 10, found S2")
+
+	other = strings.Replace(chunkHeader, "\tS2", "", 1)
+	_, err = Concat([]io.Reader{strings.NewReader(chunkHeader), strings.NewReader(other)}, io.Discard, ConcatOptions{})
+	assert.EqualError(s.T(), err, "input 2: expected 2 samples, found 1")
+}
+
+func (s *ConcatSuite) TestConcatBGZF() {
+	first := chunkHeader + "chr2\t10\t.\tA\tG\t50\tPASS\tDP=3\tGT\t0/1\t0/0\n"
+	second := chunkHeader + strings.Repeat("chr1\t5\t.\tC\tT\t50\tPASS\tDP=4\tGT\t1/1\t0/1\n", 5000)
+
+	var output bytes.Buffer
+	err := ConcatBGZF([]io.Reader{s.bgzip(first), s.bgzip(second)}, &output)
+	assert.NoError(s.T(), err)
+	assert.True(s.T(), bytes.HasSuffix(output.Bytes(), bgzfEOF), "output should end with the EOF block")
+
+	decompressed, err := gzip.NewReader(&output)
+	assert.NoError(s.T(), err)
+	content, err := io.ReadAll(decompressed)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), first+strings.TrimPrefix(second, chunkHeader), string(content))
+
+	other := strings.Replace(chunkHeader, "\tS2", "", 1)
+	err = ConcatBGZF([]io.Reader{s.bgzip(first), s.bgzip(other)}, io.Discard)
+	assert.EqualError(s.T(), err, "input 2: expected 2 samples, found 1")
+}
+
+// bgzip compresses content in small blocks, so that the header spans more than one of them.
+func (s *ConcatSuite) bgzip(content string) io.Reader {
+	var compressed bytes.Buffer
+	for start := 0; start < len(content); start += 100 {
+		end := start + 100
+		if end > len(content) {
+			end = len(content)
+		}
+		assert.NoError(s.T(), writeBGZFBlocks(&compressed, []byte(content[start:end])))
+	}
+	compressed.Write(bgzfEOF)
+	return &compressed
+}
+
+func TestConcatSuite(t *testing.T) {
+	suite.Run(t, new(ConcatSuite))
+}
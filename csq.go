@@ -0,0 +1,179 @@
+package vcf
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Consequence is a transcript consequence annotated by VEP on the CSQ INFO field. The most used columns are
+// available as fields, and every column of the annotation is kept on Fields, by the name declared on the header.
+type Consequence struct {
+	// Allele is the allele the consequence refers to, in the VEP notation: indels drop the base shared with the
+	// reference, and deletions are written as '-'. AlleleNumber is the ALLELE_NUM column, or zero without it.
+	Allele       string
+	AlleleNumber int
+
+	// Terms holds the Sequence Ontology terms of the Consequence column, such as missense_variant.
+	Terms []string
+
+	Impact      string
+	Symbol      string
+	Gene        string
+	FeatureType string
+	Feature     string
+	Biotype     string
+	Exon        string
+	Intron      string
+	HGVSc       string
+	HGVSp       string
+
+	Fields map[string]string
+}
+
+// CSQDecoder decodes the CSQ INFO field written by VEP, using the layout declared on the Description of its
+// ##INFO line, such as Description="Consequence annotations from Ensembl VEP. Format: Allele|Consequence|IMPACT".
+type CSQDecoder struct {
+	fields []string
+
+	// decodePercent is set for versions older than 4.3, whose values are not percent-decoded by the parser
+	// even though VEP encodes them.
+	decodePercent bool
+}
+
+// NewCSQDecoder reads the layout of the CSQ field from the header. An error is returned when CSQ is not declared
+// or its Description has no Format.
+func NewCSQDecoder(header *Header) (*CSQDecoder, error) {
+	fields, err := annotationLayout(header, "CSQ", "Format:")
+	if err != nil {
+		return nil, err
+	}
+	return &CSQDecoder{fields: fields, decodePercent: !header.atLeast(4, 3)}, nil
+}
+
+// annotationLayout splits the column names that follow the marker on the Description of an INFO field.
+func annotationLayout(header *Header, key, marker string) ([]string, error) {
+	definition, found := header.Infos[key]
+	if !found {
+		return nil, errors.New(key + " is not declared on the header")
+	}
+	start := strings.Index(definition.Description, marker)
+	if start < 0 {
+		return nil, fmt.Errorf("the description of %s does not declare its %s", key, strings.TrimSuffix(marker, ":"))
+	}
+	layout := strings.Trim(strings.TrimSpace(definition.Description[start+len(marker):]), "'\"")
+	fields := strings.Split(layout, "|")
+	for i, field := range fields {
+		fields[i] = strings.TrimSpace(field)
+	}
+	return fields, nil
+}
+
+// Fields returns the column names of the annotation, in the order they appear on each consequence.
+func (d *CSQDecoder) Fields() []string {
+	return d.fields
+}
+
+// Decode splits a raw CSQ value into its consequences, one for each comma-separated entry.
+func (d *CSQDecoder) Decode(value string) ([]*Consequence, error) {
+	if value == "" || value == "." {
+		return nil, nil
+	}
+	entries := strings.Split(value, ",")
+	consequences := make([]*Consequence, 0, len(entries))
+	for _, entry := range entries {
+		columns := strings.Split(entry, "|")
+		if len(columns) != len(d.fields) {
+			return nil, fmt.Errorf("CSQ entry with %d columns, expected %d: %s", len(columns), len(d.fields), entry)
+		}
+		consequence := &Consequence{Fields: make(map[string]string, len(columns))}
+		for i, column := range columns {
+			if d.decodePercent {
+				column = PercentDecode(column)
+			}
+			consequence.Fields[d.fields[i]] = column
+		}
+		consequence.fill()
+		consequences = append(consequences, consequence)
+	}
+	return consequences, nil
+}
+
+func (c *Consequence) fill() {
+	c.Allele = c.Fields["Allele"]
+	c.AlleleNumber, _ = strconv.Atoi(c.Fields["ALLELE_NUM"])
+	c.Terms = splitNonEmpty(c.Fields["Consequence"], "&")
+	c.Impact = c.Fields["IMPACT"]
+	c.Symbol = c.Fields["SYMBOL"]
+	c.Gene = c.Fields["Gene"]
+	c.FeatureType = c.Fields["Feature_type"]
+	c.Feature = c.Fields["Feature"]
+	c.Biotype = c.Fields["BIOTYPE"]
+	c.Exon = c.Fields["EXON"]
+	c.Intron = c.Fields["INTRON"]
+	c.HGVSc = c.Fields["HGVSc"]
+	c.HGVSp = c.Fields["HGVSp"]
+}
+
+// VariantConsequences decodes the CSQ field of a variant and keeps the consequences of its own ALT. Since ToChannel
+// splits multiple alternatives into separate variants, each one still carries the consequences of its siblings.
+//
+// Consequences are matched by ALLELE_NUM when VEP was run with --allele_number, and by the Allele column otherwise.
+func (d *CSQDecoder) VariantConsequences(variant *Variant) ([]*Consequence, error) {
+	value, ok := variant.Info["CSQ"].(string)
+	if !ok {
+		return nil, nil
+	}
+	consequences, err := d.Decode(value)
+	if err != nil {
+		return nil, err
+	}
+	allele := vepAllele(variant)
+	matching := make([]*Consequence, 0, len(consequences))
+	for _, consequence := range consequences {
+		if (consequence.AlleleNumber > 0 && consequence.AlleleNumber == variant.AlleleIndex) ||
+			(consequence.AlleleNumber == 0 && consequence.Allele == allele) {
+			matching = append(matching, consequence)
+		}
+	}
+	return matching, nil
+}
+
+// vepAllele writes the ALT of a variant the way VEP does on the Allele column. When any alternative of the line has
+// a length different from the REF and every allele starts with the same base, that base is dropped from all of
+// them, and the empty ones become '-'.
+func vepAllele(variant *Variant) string {
+	alternatives := variant.Alternatives
+	if len(alternatives) == 0 {
+		alternatives = []string{variant.Alt}
+	}
+	index := variant.AlleleIndex - 1
+	if index < 0 || index >= len(alternatives) {
+		index = 0
+	}
+	alt := alternatives[index]
+	if variant.Symbolic != nil || variant.Breakend != nil || variant.Ref == "" {
+		return alt
+	}
+
+	// the REF was suffix-trimmed along with this ALT, so its original length is recovered from the ALT
+	refLength := len(variant.Ref) + len(alt) - len(variant.Alt)
+	firstBase := variant.Ref[0]
+	indel := false
+	for _, alternative := range alternatives {
+		if len(alternative) != refLength {
+			indel = true
+		}
+		if alternative == "" || alternative[0] != firstBase {
+			return alt
+		}
+	}
+	if !indel {
+		return alt
+	}
+	if len(alt) == 1 {
+		return "-"
+	}
+	return alt[1:]
+}
@@ -0,0 +1,107 @@
+package vcf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+const csqHeader = `##fileformat=VCFv4.2
+##INFO=<ID=CSQ,Number=.,Type=String,Description="Consequence annotations from Ensembl VEP. Format: Allele|Consequence|IMPACT|SYMBOL|Gene|Feature_type|Feature|BIOTYPE|HGVSp">
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO
+`
+
+type CSQSuite struct {
+	suite.Suite
+}
+
+func (s *CSQSuite) read(vcfLines string) (*Header, []*Variant) {
+	var header *Header
+	output := make(chan *Variant, 10)
+	invalids := make(chan InvalidLine, 10)
+	err := ToChannel(strings.NewReader(vcfLines), output, invalids, WithHeaderHandler(func(h *Header) { header = h }))
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), invalids, 0)
+
+	variants := make([]*Variant, 0)
+	for variant := range output {
+		variants = append(variants, variant)
+	}
+	return header, variants
+}
+
+func (s *CSQSuite) TestDecode() {
+	header, _ := s.read(csqHeader)
+	decoder, err := NewCSQDecoder(header)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"Allele", "Consequence", "IMPACT", "SYMBOL", "Gene", "Feature_type", "Feature", "BIOTYPE", "HGVSp"}, decoder.Fields())
+
+	consequences, err := decoder.Decode("T|missense_variant&splice_region_variant|MODERATE|BRCA2|ENSG00000139618|Transcript|ENST00000380152|protein_coding|ENSP00000369497.3:p.Leu%3D")
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), consequences, 1)
+	consequence := consequences[0]
+	assert.Equal(s.T(), "T", consequence.Allele)
+	assert.Equal(s.T(), []string{"missense_variant", "splice_region_variant"}, consequence.Terms)
+	assert.Equal(s.T(), "MODERATE", consequence.Impact)
+	assert.Equal(s.T(), "BRCA2", consequence.Symbol)
+	assert.Equal(s.T(), "ENSG00000139618", consequence.Gene)
+	assert.Equal(s.T(), "ENST00000380152", consequence.Feature)
+	assert.Equal(s.T(), "ENSP00000369497.3:p.Leu=", consequence.HGVSp, "VEP encodes '=' on VCF 4.2 files too")
+	assert.Equal(s.T(), "Transcript", consequence.Fields["Feature_type"])
+
+	_, err = decoder.Decode("T|missense_variant")
+	assert.EqualError(s.T(), err, "CSQ entry with 2 columns, expected 9: T|missense_variant")
+}
+
+func (s *CSQSuite) TestConsequencesOfEachAlternative() {
+	header, variants := s.read(csqHeader +
+		"13\t100\t.\tAT\tA,ATT\t.\tPASS\tCSQ=-|frameshift_variant|HIGH|BRCA2|G|Transcript|T1|protein_coding|,TT|frameshift_variant|HIGH|BRCA2|G|Transcript|T1|protein_coding|,-|upstream_gene_variant|MODIFIER|ZAR1L|G2|Transcript|T2|protein_coding|\n" +
+		"13\t200\t.\tC\tG,T\t.\tPASS\tCSQ=G|missense_variant|MODERATE|BRCA2|G|Transcript|T1|protein_coding|,T|stop_gained|HIGH|BRCA2|G|Transcript|T1|protein_coding|\n")
+	decoder, err := NewCSQDecoder(header)
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), variants, 4)
+
+	features := func(variant *Variant) []string {
+		consequences, err := decoder.VariantConsequences(variant)
+		assert.NoError(s.T(), err)
+		terms := make([]string, len(consequences))
+		for i, consequence := range consequences {
+			terms[i] = consequence.Feature + ":" + strings.Join(consequence.Terms, "&")
+		}
+		return terms
+	}
+	assert.Equal(s.T(), []string{"T1:frameshift_variant", "T2:upstream_gene_variant"}, features(variants[0]), "the deletion is written as '-'")
+	assert.Equal(s.T(), []string{"T1:frameshift_variant"}, features(variants[1]))
+	assert.Equal(s.T(), []string{"T1:missense_variant"}, features(variants[2]))
+	assert.Equal(s.T(), []string{"T1:stop_gained"}, features(variants[3]))
+}
+
+func (s *CSQSuite) TestAlleleNumber() {
+	header := newHeader()
+	header.AddMetaLine(`##INFO=<ID=CSQ,Number=.,Type=String,Description="Consequence annotations from Ensembl VEP. Format: Allele|Consequence|ALLELE_NUM">`)
+	decoder, err := NewCSQDecoder(header)
+	assert.NoError(s.T(), err)
+
+	variants, err := parseVcfLine("1\t100\t.\tA\tG,C\t.\t.\tCSQ=G|missense_variant|2,C|synonymous_variant|1", header, Standard, nil)
+	assert.NoError(s.T(), err)
+	consequences, err := decoder.VariantConsequences(variants[0])
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), consequences, 1)
+	assert.Equal(s.T(), []string{"synonymous_variant"}, consequences[0].Terms, "ALLELE_NUM takes precedence over Allele")
+}
+
+func (s *CSQSuite) TestMissingLayout() {
+	_, err := NewCSQDecoder(newHeader())
+	assert.EqualError(s.T(), err, "CSQ is not declared on the header")
+
+	header := newHeader()
+	header.AddMetaLine(`##INFO=<ID=CSQ,Number=.,Type=String,Description="Consequences">`)
+	_, err = NewCSQDecoder(header)
+	assert.EqualError(s.T(), err, "the description of CSQ does not declare its Format")
+}
+
+func TestCSQSuite(t *testing.T) {
+	suite.Run(t, new(CSQSuite))
+}
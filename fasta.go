@@ -0,0 +1,128 @@
+package vcf
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// FASTA gives the bases of the sequences of a reference genome. Small references can be loaded in memory with
+// ReadFASTA, while whole genomes are read on demand with NewIndexedFASTA. Sequence names are compared after
+// stripping the "chr" prefix, like the Chrom of a Variant.
+type FASTA struct {
+	// sequences holds the bases of a FASTA loaded in memory, by normalized name
+	sequences map[string][]byte
+
+	// index locates the sequences of an indexed FASTA on data, by normalized name
+	index map[string]faiEntry
+	data  io.ReaderAt
+}
+
+// faiEntry is a line of a .fai index: the length of a sequence, the offset of its first base and the number of
+// bases and bytes on each of its lines.
+type faiEntry struct {
+	length    int64
+	offset    int64
+	lineBases int64
+	lineWidth int64
+}
+
+// ReadFASTA loads every sequence of a FASTA file in memory.
+func ReadFASTA(reader io.Reader) (*FASTA, error) {
+	fasta := &FASTA{sequences: make(map[string][]byte)}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 100*1024), 10*1024*1024)
+	var name string
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if bytes.HasPrefix(line, []byte(">")) {
+			fields := strings.Fields(string(line[1:]))
+			if len(fields) == 0 {
+				return nil, errors.New("FASTA sequence without a name")
+			}
+			name = normalizeChrom(fields[0])
+			fasta.sequences[name] = nil
+			continue
+		}
+		if len(line) > 0 && name == "" {
+			return nil, errors.New("FASTA bases before the first sequence name")
+		}
+		fasta.sequences[name] = append(fasta.sequences[name], bytes.ToUpper(line)...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return fasta, nil
+}
+
+// NewIndexedFASTA reads the bases of a FASTA file on demand, locating them with its .fai index, as created by
+// samtools faidx.
+func NewIndexedFASTA(data io.ReaderAt, fai io.Reader) (*FASTA, error) {
+	fasta := &FASTA{index: make(map[string]faiEntry), data: data}
+	scanner := bufio.NewScanner(fai)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		fields := strings.Split(strings.TrimSpace(scanner.Text()), "\t")
+		if len(fields) < 5 {
+			return nil, fmt.Errorf("line %d of the FASTA index: expected 5 columns, found %d", lineNumber, len(fields))
+		}
+		var numbers [4]int64
+		for i := range numbers {
+			number, err := strconv.ParseInt(fields[i+1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d of the FASTA index: unable to parse %s", lineNumber, fields[i+1])
+			}
+			numbers[i] = number
+		}
+		if numbers[0] < 0 || numbers[1] < 0 || numbers[2] <= 0 || numbers[3] < numbers[2] {
+			return nil, fmt.Errorf("line %d of the FASTA index: invalid length, offset or line sizes for %s", lineNumber, fields[0])
+		}
+		fasta.index[normalizeChrom(fields[0])] = faiEntry{length: numbers[0], offset: numbers[1], lineBases: numbers[2], lineWidth: numbers[3]}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return fasta, nil
+}
+
+// Bases returns the upper-cased bases of the 0-based half-open region.
+func (f *FASTA) Bases(chrom string, start, end int) (string, error) {
+	name := normalizeChrom(chrom)
+	if f.sequences != nil {
+		sequence, found := f.sequences[name]
+		if !found {
+			return "", fmt.Errorf("sequence %s not found on the FASTA", chrom)
+		}
+		if start < 0 || end < start || end > len(sequence) {
+			return "", fmt.Errorf("region %s:%d-%d is outside the sequence", chrom, start, end)
+		}
+		return string(sequence[start:end]), nil
+	}
+
+	entry, found := f.index[name]
+	if !found {
+		return "", fmt.Errorf("sequence %s not found on the FASTA", chrom)
+	}
+	if start < 0 || end < start || int64(end) > entry.length {
+		return "", fmt.Errorf("region %s:%d-%d is outside the sequence", chrom, start, end)
+	}
+	if start == end {
+		return "", nil
+	}
+	first := entry.offset + int64(start)/entry.lineBases*entry.lineWidth + int64(start)%entry.lineBases
+	last := entry.offset + int64(end-1)/entry.lineBases*entry.lineWidth + int64(end-1)%entry.lineBases
+	raw := make([]byte, last-first+1)
+	if _, err := f.data.ReadAt(raw, first); err != nil {
+		return "", err
+	}
+	bases := make([]byte, 0, end-start)
+	for _, base := range raw {
+		if base != '\n' && base != '\r' {
+			bases = append(bases, base)
+		}
+	}
+	return strings.ToUpper(string(bases)), nil
+}
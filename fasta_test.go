@@ -0,0 +1,76 @@
+package vcf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type FASTASuite struct {
+	suite.Suite
+}
+
+const fastaText = ">chr1 first chromosome\nACGTA\nCGTac\nGT\n>chr2\nNNNN\n"
+
+func (s *FASTASuite) TestReadFASTA() {
+	fasta, err := ReadFASTA(strings.NewReader(fastaText))
+	assert.NoError(s.T(), err)
+
+	bases, err := fasta.Bases("1", 3, 11)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "TACGTACG", bases)
+
+	bases, err = fasta.Bases("chr2", 0, 4)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "NNNN", bases)
+
+	_, err = fasta.Bases("chr1", 10, 13)
+	assert.EqualError(s.T(), err, "region chr1:10-13 is outside the sequence")
+	_, err = fasta.Bases("chr3", 0, 1)
+	assert.EqualError(s.T(), err, "sequence chr3 not found on the FASTA")
+
+	_, err = ReadFASTA(strings.NewReader("ACGT\n"))
+	assert.EqualError(s.T(), err, "FASTA bases before the first sequence name")
+}
+
+func (s *FASTASuite) TestIndexedFASTA() {
+	fai := "chr1\t12\t23\t5\t6\nchr2\t4\t44\t4\t5\n"
+	fasta, err := NewIndexedFASTA(strings.NewReader(fastaText), strings.NewReader(fai))
+	assert.NoError(s.T(), err)
+
+	for _, region := range []struct {
+		start, end int
+		expected   string
+	}{
+		{0, 12, "ACGTACGTACGT"},
+		{3, 11, "TACGTACG"},
+		{5, 10, "CGTAC"},
+		{4, 5, "A"},
+		{7, 7, ""},
+	} {
+		bases, err := fasta.Bases("1", region.start, region.end)
+		assert.NoError(s.T(), err)
+		assert.Equal(s.T(), region.expected, bases)
+	}
+
+	bases, err := fasta.Bases("chr2", 1, 3)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "NN", bases)
+
+	_, err = NewIndexedFASTA(strings.NewReader(fastaText), strings.NewReader("chr1\t12\t23\t0\t6\n"))
+	assert.EqualError(s.T(), err, "line 1 of the FASTA index: invalid length, offset or line sizes for chr1")
+	_, err = NewIndexedFASTA(strings.NewReader(fastaText), strings.NewReader("chr1\t12\t23\t5\t4\n"))
+	assert.Error(s.T(), err, "lines cannot be narrower than their bases")
+
+	_, err = fasta.Bases("chr1", 0, 13)
+	assert.EqualError(s.T(), err, "region chr1:0-13 is outside the sequence")
+
+	_, err = NewIndexedFASTA(strings.NewReader(fastaText), strings.NewReader("chr1\t12\t23\n"))
+	assert.EqualError(s.T(), err, "line 1 of the FASTA index: expected 5 columns, found 3")
+}
+
+func TestFASTASuite(t *testing.T) {
+	suite.Run(t, new(FASTASuite))
+}
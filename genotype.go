@@ -0,0 +1,177 @@
+package vcf
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Genotype is the parsed form of a GT sample field, such as 0/1, 1|0 or ./..
+type Genotype struct {
+	// Alleles holds the allele indexes of the call: 0 for the reference, 1 for the first alternative and so
+	// on. Missing alleles, written as '.', are represented as MissingAllele.
+	Alleles []int
+
+	// Phased is true when every allele is separated by '|'.
+	Phased bool
+
+	// Separators holds the separator found before each allele but the first, '/' or '|', so that calls mixing
+	// them, such as 0/1|2, are written back as they were read. When it does not have one separator per pair of
+	// alleles, as on genotypes built by hand, String separates every allele by '|' if Phased and '/' otherwise.
+	Separators []byte
+
+	// PhasePrefix is the explicit phasing of the first allele allowed by VCF 4.4, such as the '/' of /0|1, or
+	// zero when there is none. It does not change Phased, except for haploid calls, which are phased by '|'.
+	PhasePrefix byte
+}
+
+// MissingAllele is the allele index used for '.' calls on a Genotype.
+const MissingAllele = -1
+
+// ParseGenotype parses a GT field. Haploid (1), diploid (0/1) and polyploid (0/1/2) calls are supported, as well
+// as the phasing prefix of VCF 4.4 (|0/1).
+func ParseGenotype(gt string) (*Genotype, error) {
+	if gt == "" {
+		return nil, errors.New("empty genotype")
+	}
+	genotype := &Genotype{Phased: true}
+	if gt[0] == '/' || gt[0] == '|' {
+		genotype.PhasePrefix = gt[0]
+		gt = gt[1:]
+	}
+	start := 0
+	for i := 0; i <= len(gt); i++ {
+		if i < len(gt) && gt[i] != '/' && gt[i] != '|' {
+			continue
+		}
+		allele, err := parseAlleleIndex(gt[start:i])
+		if err != nil {
+			return nil, errors.New("unable to parse genotype: " + gt)
+		}
+		genotype.Alleles = append(genotype.Alleles, allele)
+		if i < len(gt) {
+			genotype.Separators = append(genotype.Separators, gt[i])
+			if gt[i] == '/' {
+				genotype.Phased = false
+			}
+		}
+		start = i + 1
+	}
+	if len(genotype.Alleles) == 1 {
+		genotype.Phased = genotype.PhasePrefix == '|'
+	}
+	return genotype, nil
+}
+
+func parseAlleleIndex(allele string) (int, error) {
+	if allele == "." {
+		return MissingAllele, nil
+	}
+	index, err := strconv.Atoi(allele)
+	if err != nil || index < 0 {
+		return 0, errors.New("invalid allele index: " + allele)
+	}
+	return index, nil
+}
+
+// String formats the genotype back to the GT notation.
+func (g *Genotype) String() string {
+	separator := "/"
+	if g.Phased {
+		separator = "|"
+	}
+	var builder strings.Builder
+	if g.PhasePrefix != 0 {
+		builder.WriteByte(g.PhasePrefix)
+	}
+	for i, allele := range g.Alleles {
+		if i > 0 {
+			if len(g.Separators) == len(g.Alleles)-1 {
+				builder.WriteByte(g.Separators[i-1])
+			} else {
+				builder.WriteString(separator)
+			}
+		}
+		if allele == MissingAllele {
+			builder.WriteByte('.')
+		} else {
+			builder.WriteString(strconv.Itoa(allele))
+		}
+	}
+	return builder.String()
+}
+
+// IsMissing tells whether every allele of the call is missing.
+func (g *Genotype) IsMissing() bool {
+	for _, allele := range g.Alleles {
+		if allele != MissingAllele {
+			return false
+		}
+	}
+	return true
+}
+
+// IsHomRef tells whether every allele of the call is the reference.
+func (g *Genotype) IsHomRef() bool {
+	return g.allEqual(0)
+}
+
+// IsHomAlt tells whether every allele of the call is the same alternative allele.
+func (g *Genotype) IsHomAlt() bool {
+	return len(g.Alleles) > 0 && g.Alleles[0] > 0 && g.allEqual(g.Alleles[0])
+}
+
+// IsHet tells whether the call has at least two different called alleles.
+func (g *Genotype) IsHet() bool {
+	first := MissingAllele
+	for _, allele := range g.Alleles {
+		if allele == MissingAllele {
+			continue
+		}
+		if first == MissingAllele {
+			first = allele
+		} else if allele != first {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAllele tells whether the call contains the given allele index.
+func (g *Genotype) HasAllele(index int) bool {
+	for _, allele := range g.Alleles {
+		if allele == index {
+			return true
+		}
+	}
+	return false
+}
+
+// biallelic returns a copy of the genotype restricted to the reference and the given alternative allele,
+// which is renumbered as 1. Any other alternative allele becomes missing.
+func (g *Genotype) biallelic(alleleIndex int) *Genotype {
+	restricted := &Genotype{Alleles: make([]int, len(g.Alleles)), Phased: g.Phased, Separators: g.Separators, PhasePrefix: g.PhasePrefix}
+	for i, allele := range g.Alleles {
+		switch {
+		case allele == 0:
+			restricted.Alleles[i] = 0
+		case allele == alleleIndex && allele > 0:
+			restricted.Alleles[i] = 1
+		default:
+			restricted.Alleles[i] = MissingAllele
+		}
+	}
+	return restricted
+}
+
+func (g *Genotype) allEqual(index int) bool {
+	if len(g.Alleles) == 0 {
+		return false
+	}
+	for _, allele := range g.Alleles {
+		if allele != index {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+package vcf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type GenotypeSuite struct {
+	suite.Suite
+}
+
+func (s *GenotypeSuite) TestDiploid() {
+	genotype, err := ParseGenotype("0/1")
+
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []int{0, 1}, genotype.Alleles)
+	assert.False(s.T(), genotype.Phased)
+	assert.True(s.T(), genotype.IsHet())
+	assert.False(s.T(), genotype.IsHomRef())
+	assert.False(s.T(), genotype.IsHomAlt())
+	assert.Equal(s.T(), "0/1", genotype.String())
+}
+
+func (s *GenotypeSuite) TestPhasedHomAlt() {
+	genotype, err := ParseGenotype("2|2")
+
+	assert.NoError(s.T(), err)
+	assert.True(s.T(), genotype.Phased)
+	assert.True(s.T(), genotype.IsHomAlt())
+	assert.True(s.T(), genotype.HasAllele(2))
+	assert.False(s.T(), genotype.HasAllele(1))
+	assert.Equal(s.T(), "2|2", genotype.String())
+}
+
+func (s *GenotypeSuite) TestHaploidAndMissing() {
+	genotype, err := ParseGenotype("0")
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []int{0}, genotype.Alleles)
+	assert.False(s.T(), genotype.Phased)
+	assert.True(s.T(), genotype.IsHomRef())
+
+	genotype, err = ParseGenotype("./.")
+	assert.NoError(s.T(), err)
+	assert.True(s.T(), genotype.IsMissing())
+	assert.False(s.T(), genotype.IsHet())
+	assert.Equal(s.T(), "./.", genotype.String())
+
+	genotype, err = ParseGenotype("./1")
+	assert.NoError(s.T(), err)
+	assert.False(s.T(), genotype.IsMissing())
+	assert.False(s.T(), genotype.IsHet(), "a single called allele is not heterozygous")
+}
+
+func (s *GenotypeSuite) TestMixedPhasingIsUnphased() {
+	genotype, err := ParseGenotype("0|1/2")
+
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []int{0, 1, 2}, genotype.Alleles)
+	assert.False(s.T(), genotype.Phased)
+	assert.Equal(s.T(), "0|1/2", genotype.String())
+
+	genotype, err = ParseGenotype("0/1|2")
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []byte{'/', '|'}, genotype.Separators)
+	assert.Equal(s.T(), "0/1|2", genotype.String())
+	assert.Equal(s.T(), "0/1|.", genotype.biallelic(1).String())
+
+	// genotypes built by hand are separated according to Phased
+	assert.Equal(s.T(), "0|1|2", (&Genotype{Alleles: []int{0, 1, 2}, Phased: true}).String())
+	assert.Equal(s.T(), "0/1", (&Genotype{Alleles: []int{0, 1}}).String())
+}
+
+func (s *GenotypeSuite) TestPhasePrefix() {
+	genotype, err := ParseGenotype("|1")
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []int{1}, genotype.Alleles)
+	assert.True(s.T(), genotype.Phased, "a haploid call is phased by a '|' prefix")
+	assert.Equal(s.T(), "|1", genotype.String())
+
+	genotype, err = ParseGenotype("/0|1")
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []int{0, 1}, genotype.Alleles)
+	assert.Equal(s.T(), byte('/'), genotype.PhasePrefix)
+	assert.True(s.T(), genotype.Phased)
+	assert.Equal(s.T(), "/0|1", genotype.String())
+
+	genotype, err = ParseGenotype("|0/1")
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), byte('|'), genotype.PhasePrefix)
+	assert.False(s.T(), genotype.Phased)
+	assert.Equal(s.T(), "|0/1", genotype.String())
+
+	_, err = ParseGenotype("|")
+	assert.Error(s.T(), err)
+}
+
+func (s *GenotypeSuite) TestInvalid() {
+	for _, gt := range []string{"", "a/1", "0//1", "-1/0", "0/"} {
+		_, err := ParseGenotype(gt)
+		assert.Error(s.T(), err, gt)
+	}
+}
+
+func TestGenotypeSuite(t *testing.T) {
+	suite.Run(t, new(GenotypeSuite))
+}
@@ -0,0 +1,187 @@
+package vcf
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// isNonRefAllele tells whether an ALT is the gVCF placeholder for any unobserved allele, written as
+// <NON_REF> by GATK and as <*> by other callers and the VCF 4.3 spec.
+func isNonRefAllele(alt string) bool {
+	return alt == "<NON_REF>" || alt == "<*>"
+}
+
+// IsReferenceBlock tells whether the variant is a gVCF reference block: a record whose only alternative
+// is <NON_REF> or <*>, usually spanning up to its END.
+func (v *Variant) IsReferenceBlock() bool {
+	return isNonRefAllele(v.Alt) && len(v.Alternatives) <= 1
+}
+
+// BlockSpan returns the span of a gVCF reference block, 0-based and half-open like Pos. When the block
+// has no END it covers only its REF. For variants that are not reference blocks, ok is false.
+func (v *Variant) BlockSpan() (start, end int, ok bool) {
+	if !v.IsReferenceBlock() {
+		return 0, 0, false
+	}
+	return v.Pos, v.end(), true
+}
+
+// GQBand is a genotype quality band used to group reference blocks, declared on ##GVCFBlock lines such
+// as ##GVCFBlock20-30=minGQ=20(inclusive),maxGQ=30(exclusive).
+type GQBand struct {
+	Min int // inclusive
+	Max int // exclusive
+}
+
+// GQBand returns the band declared on the header that contains the given genotype quality.
+func (h *Header) GQBand(gq int) (GQBand, bool) {
+	for _, band := range h.GQBands {
+		if gq >= band.Min && gq < band.Max {
+			return band, true
+		}
+	}
+	return GQBand{}, false
+}
+
+func parseGQBand(meta *MetaLine) (GQBand, bool) {
+	band := GQBand{}
+	found := 0
+	for _, field := range strings.Split(meta.Value, ",") {
+		separator := strings.Index(field, "=")
+		if separator < 0 {
+			continue
+		}
+		value, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSuffix(field[separator+1:], "(inclusive)"), "(exclusive)"))
+		if err != nil {
+			continue
+		}
+		switch field[:separator] {
+		case "minGQ":
+			band.Min = value
+			found++
+		case "maxGQ":
+			band.Max = value
+			found++
+		}
+	}
+	return band, found == 2
+}
+
+// SiteCall is the answer of a GVCFIndex query: the call of one sample at one position.
+type SiteCall struct {
+	// Variant is the record covering the position, either a variant site or a reference block.
+	Variant *Variant
+
+	// ReferenceBlock is true when the call comes from a reference block.
+	ReferenceBlock bool
+
+	// Genotype is the parsed GT of the sample, or nil when it is absent or malformed.
+	Genotype *Genotype
+
+	// Depth is the MIN_DP of the sample for reference blocks that carry it, and its DP otherwise.
+	Depth *int
+
+	// GQ is the genotype quality of the sample.
+	GQ *int
+}
+
+// GVCFIndex answers genotype and coverage queries for any position of a gVCF, from both its variant sites
+// and its reference blocks. Variants are kept in memory, grouped by chromosome.
+type GVCFIndex struct {
+	samples map[string]int
+	records map[string][]*Variant
+	longest map[string]int
+	sorted  map[string]bool
+}
+
+// NewGVCFIndex creates an empty index for the samples declared on the header.
+func NewGVCFIndex(header *Header) *GVCFIndex {
+	index := &GVCFIndex{
+		samples: make(map[string]int),
+		records: make(map[string][]*Variant),
+		longest: make(map[string]int),
+		sorted:  make(map[string]bool),
+	}
+	for i, sample := range header.SampleIDs() {
+		index.samples[sample] = i
+	}
+	return index
+}
+
+// Add adds a variant site or reference block to the index.
+func (index *GVCFIndex) Add(variant *Variant) {
+	records := index.records[variant.Chrom]
+	if len(records) > 0 && records[len(records)-1].Pos > variant.Pos {
+		index.sorted[variant.Chrom] = false
+	} else if len(records) == 0 {
+		index.sorted[variant.Chrom] = true
+	}
+	index.records[variant.Chrom] = append(records, variant)
+	if length := variant.end() - variant.Pos; length > index.longest[variant.Chrom] {
+		index.longest[variant.Chrom] = length
+	}
+}
+
+// Query returns the call of a sample at a 0-based position. Variant sites take precedence over reference
+// blocks covering the same position. When the position is not covered or the sample is unknown, ok is false.
+func (index *GVCFIndex) Query(chrom string, pos int, sample string) (call *SiteCall, ok bool) {
+	column, found := index.samples[sample]
+	if !found {
+		return nil, false
+	}
+	records := index.chromosomeRecords(normalizeChrom(chrom))
+
+	var covering *Variant
+	first := sort.Search(len(records), func(i int) bool { return records[i].Pos > pos })
+	for i := first - 1; i >= 0 && records[i].Pos+index.longest[records[i].Chrom] > pos; i-- {
+		if records[i].end() <= pos {
+			continue
+		}
+		if covering == nil || (covering.IsReferenceBlock() && !records[i].IsReferenceBlock()) {
+			covering = records[i]
+		}
+	}
+	if covering == nil || column >= len(covering.Samples) {
+		return nil, false
+	}
+
+	return newSiteCall(covering, covering.Samples[column]), true
+}
+
+func (index *GVCFIndex) chromosomeRecords(chrom string) []*Variant {
+	records := index.records[chrom]
+	if !index.sorted[chrom] {
+		sort.SliceStable(records, func(i, j int) bool { return records[i].Pos < records[j].Pos })
+		index.sorted[chrom] = true
+	}
+	return records
+}
+
+func newSiteCall(variant *Variant, sample map[string]string) *SiteCall {
+	call := &SiteCall{
+		Variant:        variant,
+		ReferenceBlock: variant.IsReferenceBlock(),
+		GQ:             parseIntFromSample("GQ", sample),
+	}
+	if gt, found := sample["GT"]; found {
+		call.Genotype, _ = ParseGenotype(gt)
+	}
+	if call.ReferenceBlock {
+		call.Depth = parseIntFromSample("MIN_DP", sample)
+	}
+	if call.Depth == nil {
+		call.Depth = parseIntFromSample("DP", sample)
+	}
+	return call
+}
+
+func parseIntFromSample(key string, sample map[string]string) *int {
+	if value, found := sample[key]; found {
+		intvalue, err := strconv.Atoi(value)
+		if err == nil {
+			return &intvalue
+		}
+	}
+	return nil
+}
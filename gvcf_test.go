@@ -0,0 +1,129 @@
+package vcf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+const gvcfText = `##fileformat=VCFv4.2
+##GVCFBlock0-20=minGQ=0(inclusive),maxGQ=20(exclusive)
+##GVCFBlock20-60=minGQ=20(inclusive),maxGQ=60(exclusive)
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	NA12878
+20	10000000	.	T	<NON_REF>	.	.	END=10000116	GT:DP:GQ:MIN_DP:PL	0/0:44:99:38:0,89,1385
+20	10000117	.	C	T,<NON_REF>	262.77	.	DP=23	GT:AD:DP:GQ:PL:SB	0/1:11,12,0:23:99:291,0,258,324,294,618:4,7,4,8
+20	10000118	.	T	<NON_REF>	.	.	END=10000210	GT:DP:GQ:MIN_DP:PL	0/0:42:30:35:0,81,1165
+`
+
+type GVCFSuite struct {
+	suite.Suite
+}
+
+func (s *GVCFSuite) readAll(options ...ReaderOption) []*Variant {
+	output := make(chan *Variant, 10)
+	invalids := make(chan InvalidLine, 10)
+	err := ToChannel(strings.NewReader(gvcfText), output, invalids, options...)
+	assert.NoError(s.T(), err)
+
+	variants := make([]*Variant, 0)
+	for variant := range output {
+		variants = append(variants, variant)
+	}
+	return variants
+}
+
+func (s *GVCFSuite) TestReferenceBlocks() {
+	variants := s.readAll()
+	assert.Len(s.T(), variants, 4)
+
+	assert.True(s.T(), variants[0].IsReferenceBlock())
+	start, end, ok := variants[0].BlockSpan()
+	assert.True(s.T(), ok)
+	assert.Equal(s.T(), 9999999, start)
+	assert.Equal(s.T(), 10000116, end)
+
+	assert.False(s.T(), variants[1].IsReferenceBlock())
+	assert.Equal(s.T(), "<NON_REF>", variants[2].Alt)
+	assert.False(s.T(), variants[2].IsReferenceBlock(), "NON_REF alternatives of variant sites are not blocks")
+	_, _, ok = variants[2].BlockSpan()
+	assert.False(s.T(), ok)
+}
+
+func (s *GVCFSuite) TestDropNonRefAllele() {
+	variants := s.readAll(DropNonRefAllele())
+	assert.Len(s.T(), variants, 3)
+
+	assert.True(s.T(), variants[0].IsReferenceBlock())
+	assert.Equal(s.T(), "T", variants[1].Alt)
+	assert.True(s.T(), variants[2].IsReferenceBlock())
+}
+
+func (s *GVCFSuite) TestGQBands() {
+	var header *Header
+	s.readAll(WithHeaderHandler(func(h *Header) { header = h }))
+
+	assert.NotNil(s.T(), header)
+	assert.Equal(s.T(), []GQBand{{Min: 0, Max: 20}, {Min: 20, Max: 60}}, header.GQBands)
+
+	band, ok := header.GQBand(30)
+	assert.True(s.T(), ok)
+	assert.Equal(s.T(), GQBand{Min: 20, Max: 60}, band)
+	_, ok = header.GQBand(99)
+	assert.False(s.T(), ok)
+}
+
+func (s *GVCFSuite) TestQuery() {
+	var header *Header
+	variants := s.readAll(WithHeaderHandler(func(h *Header) { header = h }))
+	index := NewGVCFIndex(header)
+	for _, variant := range variants {
+		index.Add(variant)
+	}
+
+	call, ok := index.Query("20", 10000050, "NA12878")
+	assert.True(s.T(), ok)
+	assert.True(s.T(), call.ReferenceBlock)
+	assert.True(s.T(), call.Genotype.IsHomRef())
+	assert.Equal(s.T(), 38, *call.Depth, "blocks should report MIN_DP")
+	assert.Equal(s.T(), 99, *call.GQ)
+
+	call, ok = index.Query("chr20", 10000116, "NA12878")
+	assert.True(s.T(), ok)
+	assert.False(s.T(), call.ReferenceBlock)
+	assert.True(s.T(), call.Genotype.IsHet())
+	assert.Equal(s.T(), 23, *call.Depth)
+
+	call, ok = index.Query("20", 10000209, "NA12878")
+	assert.True(s.T(), ok)
+	assert.Equal(s.T(), 30, *call.GQ)
+
+	_, ok = index.Query("20", 10000210, "NA12878")
+	assert.False(s.T(), ok, "positions after the last block are not covered")
+	_, ok = index.Query("21", 10000050, "NA12878")
+	assert.False(s.T(), ok)
+	_, ok = index.Query("20", 10000050, "unknown")
+	assert.False(s.T(), ok)
+}
+
+func (s *GVCFSuite) TestQueryOutOfOrder() {
+	header := newHeader()
+	header.Columns = []string{"CHROM", "POS", "ID", "REF", "ALT", "QUAL", "FILTER", "INFO", "FORMAT", "S1"}
+	end := 200
+	index := NewGVCFIndex(header)
+	index.Add(&Variant{Chrom: "1", Pos: 150, Ref: "A", Alt: "G", Samples: []map[string]string{{"GT": "1/1"}}})
+	index.Add(&Variant{Chrom: "1", Pos: 99, Ref: "A", Alt: "<*>", End: &end, Samples: []map[string]string{{"GT": "0/0", "DP": "7"}}})
+
+	call, ok := index.Query("1", 150, "S1")
+	assert.True(s.T(), ok)
+	assert.True(s.T(), call.Genotype.IsHomAlt(), "variant sites take precedence over blocks")
+
+	call, ok = index.Query("1", 120, "S1")
+	assert.True(s.T(), ok)
+	assert.Equal(s.T(), 7, *call.Depth, "blocks without MIN_DP should report DP")
+}
+
+func TestGVCFSuite(t *testing.T) {
+	suite.Run(t, new(GVCFSuite))
+}
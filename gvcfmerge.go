@@ -0,0 +1,235 @@
+package vcf
+
+import (
+	"errors"
+	"io"
+	"sort"
+	"strings"
+)
+
+// mergedGVCFFormat lists the sample fields written by MergeGVCFs.
+var mergedGVCFFormat = []string{"GT", "DP", "GQ"}
+
+// MergeGVCFs combines one or more gVCFs into a multi-sample VCF written to w.
+//
+// The inputs are walked together in coordinate order, following their ##contig lines, and must be sorted.
+// Every variant site found on any input becomes a biallelic record on the output. Samples that have the same
+// allele at the site keep their call, samples that are covered by a reference block at that position are
+// called homozygous for the reference, with the block MIN_DP as depth, and all others are written as missing.
+// Alleles of a sample that are not the one of the record, such as the second allele of a 1/2 call, are
+// written as missing as well. Only the GT, DP and GQ sample fields are written.
+//
+// Lines that cannot be parsed are skipped and reported in the returned error once the output is written.
+func MergeGVCFs(readers []io.Reader, w io.Writer) error {
+	if len(readers) == 0 {
+		return errors.New("no gVCF to merge")
+	}
+
+	streams, err := openVariantStreams(readers, DropNonRefAllele())
+	if err != nil {
+		return err
+	}
+	inputs := make([]*gvcfInput, len(streams))
+	headers := make([]*Header, len(streams))
+	for i, stream := range streams {
+		inputs[i] = &gvcfInput{samples: len(stream.header.SampleIDs())}
+		headers[i] = stream.header
+	}
+
+	header, err := mergedGVCFHeader(headers)
+	if err != nil {
+		closeVariantStreams(streams)
+		return err
+	}
+	writer, err := NewWriter(w, header)
+	if err != nil {
+		closeVariantStreams(streams)
+		return err
+	}
+
+	order := newContigOrder(headers...)
+	for {
+		chrom, pos, found := nextLocus(streams, order)
+		if !found {
+			break
+		}
+
+		sites := make([][]*Variant, len(inputs))
+		keys := make([]alleleKey, 0)
+		seen := make(map[alleleKey]bool)
+		for i, stream := range streams {
+			for _, variant := range stream.popLocus(chrom, pos, order) {
+				if variant.IsReferenceBlock() {
+					inputs[i].block = variant
+					continue
+				}
+				sites[i] = append(sites[i], variant)
+				if key := keyOf(variant); !seen[key] {
+					seen[key] = true
+					keys = append(keys, key)
+				}
+			}
+		}
+
+		sort.Slice(keys, func(i, j int) bool { return keys[i].less(keys[j]) })
+		for _, key := range keys {
+			if err := writer.Write(mergeGVCFSite(inputs, sites, key, chrom, pos)); err != nil {
+				closeVariantStreams(streams)
+				return err
+			}
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		closeVariantStreams(streams)
+		return err
+	}
+	return closeVariantStreams(streams)
+}
+
+type gvcfInput struct {
+	samples int
+
+	// block is the last reference block seen on this input
+	block *Variant
+}
+
+// alleleKey identifies a biallelic variant at a given position.
+type alleleKey struct {
+	ref, alt string
+}
+
+func keyOf(variant *Variant) alleleKey {
+	return alleleKey{ref: variant.Ref, alt: variant.Alt}
+}
+
+func (k alleleKey) less(other alleleKey) bool {
+	if k.ref != other.ref {
+		return k.ref < other.ref
+	}
+	return k.alt < other.alt
+}
+
+func mergeGVCFSite(inputs []*gvcfInput, sites [][]*Variant, key alleleKey, chrom string, pos int) *Variant {
+	merged := &Variant{
+		Chrom:        chrom,
+		Pos:          pos,
+		Ref:          key.ref,
+		Alt:          key.alt,
+		AlleleIndex:  1,
+		Alternatives: []string{key.alt},
+		Format:       mergedGVCFFormat,
+		Samples:      make([]map[string]string, 0),
+	}
+
+	for i, input := range inputs {
+		var carrier *Variant
+		for _, variant := range sites[i] {
+			if keyOf(variant) == key {
+				carrier = variant
+				break
+			}
+		}
+		if carrier != nil && merged.Info == nil {
+			merged.ID = carrier.ID
+			merged.Qual = carrier.Qual
+			merged.Filter = carrier.Filter
+			merged.Info = make(map[string]interface{}, len(carrier.Info))
+			for infoKey, value := range carrier.Info {
+				if infoKey != "END" {
+					merged.Info[infoKey] = value
+				}
+			}
+		}
+
+		for sample := 0; sample < input.samples; sample++ {
+			merged.Samples = append(merged.Samples, input.sampleCall(sample, carrier, sites[i], chrom, pos))
+		}
+	}
+
+	return merged
+}
+
+// sampleCall builds the GT, DP and GQ of a sample at a merged site: from its own call when it was made at
+// this position, or from the reference block covering the position.
+func (input *gvcfInput) sampleCall(sample int, carrier *Variant, sites []*Variant, chrom string, pos int) map[string]string {
+	call := make(map[string]string)
+
+	var source *Variant
+	alleleIndex := MissingAllele
+	if carrier != nil {
+		source, alleleIndex = carrier, carrier.AlleleIndex
+	} else if len(sites) > 0 {
+		source = sites[0]
+	}
+
+	if source != nil && sample < len(source.Samples) {
+		fields := source.Samples[sample]
+		if genotype, err := ParseGenotype(fields["GT"]); err == nil {
+			call["GT"] = genotype.biallelic(alleleIndex).String()
+		}
+		call["DP"] = fields["DP"]
+		call["GQ"] = fields["GQ"]
+		return call
+	}
+
+	block := input.block
+	if block != nil && block.Chrom == chrom && block.Pos <= pos && pos < block.end() && sample < len(block.Samples) {
+		fields := block.Samples[sample]
+		ploidy := 2
+		if genotype, err := ParseGenotype(fields["GT"]); err == nil {
+			ploidy = len(genotype.Alleles)
+		}
+		call["GT"] = strings.TrimSuffix(strings.Repeat("0/", ploidy), "/")
+		call["DP"] = fields["MIN_DP"]
+		if call["DP"] == "" {
+			call["DP"] = fields["DP"]
+		}
+		call["GQ"] = fields["GQ"]
+		return call
+	}
+
+	call["GT"] = "./."
+	return call
+}
+
+// mergedGVCFHeader combines the headers of the inputs, keeping the first definition of each INFO, FILTER and
+// contig, and declaring the sample fields written by MergeGVCFs. Sample IDs must be unique across inputs.
+func mergedGVCFHeader(headers []*Header) (*Header, error) {
+	merged := newHeader()
+	merged.AddMetaLine("##fileformat=" + highestFileFormat(headers))
+
+	seen := make(map[string]bool)
+	for _, header := range headers {
+		for _, meta := range header.MetaLines {
+			id := meta.Fields["ID"]
+			switch {
+			case meta.Key == "fileformat", meta.Key == "FORMAT", strings.HasPrefix(meta.Key, "GVCFBlock"):
+				continue
+			case meta.Key == "ALT" && isNonRefAllele("<"+id+">"):
+				continue
+			case meta.Key == "INFO" && id == "END":
+				continue
+			}
+			identity := meta.String()
+			if id != "" {
+				identity = meta.Key + "=" + id
+			}
+			if !seen[identity] {
+				seen[identity] = true
+				merged.addMetaLine(meta)
+			}
+		}
+	}
+	merged.AddMetaLine(`##FORMAT=<ID=GT,Number=1,Type=String,Description="Genotype">`)
+	merged.AddMetaLine(`##FORMAT=<ID=DP,Number=1,Type=Integer,Description="Read depth, or minimum read depth of the reference block">`)
+	merged.AddMetaLine(`##FORMAT=<ID=GQ,Number=1,Type=Integer,Description="Genotype quality">`)
+
+	columns, err := mergedColumns(headers)
+	if err != nil {
+		return nil, err
+	}
+	merged.Columns = columns
+
+	return merged, nil
+}
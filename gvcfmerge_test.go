@@ -0,0 +1,99 @@
+package vcf
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+const firstGVCF = `##fileformat=VCFv4.2
+##contig=<ID=20>
+##contig=<ID=21>
+##INFO=<ID=END,Number=1,Type=Integer,Description="End of block">
+##INFO=<ID=DP,Number=1,Type=Integer,Description="Depth">
+##FORMAT=<ID=MIN_DP,Number=1,Type=Integer,Description="Minimum depth">
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	S1
+20	100	.	A	<NON_REF>	.	.	END=199	GT:DP:GQ:MIN_DP	0/0:30:60:25
+20	200	.	C	T,<NON_REF>	50	.	DP=20	GT:DP:GQ	0/1:20:99
+20	201	.	G	<NON_REF>	.	.	END=300	GT:DP:GQ:MIN_DP	0/0:28:45:22
+21	50	.	T	<NON_REF>	.	.	END=60	GT:DP:GQ:MIN_DP	0/0:10:20:9
+`
+
+const secondGVCF = `##fileformat=VCFv4.2
+##contig=<ID=20>
+##contig=<ID=21>
+##INFO=<ID=DP,Number=1,Type=Integer,Description="Depth">
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	S2
+20	150	.	G	A,<NON_REF>	40	.	DP=15	GT:DP:GQ	1/1:15:40
+20	200	.	C	G,T,<NON_REF>	70	.	DP=18	GT:DP:GQ	1/2:18:70
+21	55	.	T	C,<NON_REF>	30	.	DP=12	GT:DP:GQ	0/1:12:30
+`
+
+type MergeGVCFsSuite struct {
+	suite.Suite
+}
+
+func (s *MergeGVCFsSuite) TestMerge() {
+	var buffer bytes.Buffer
+	err := MergeGVCFs([]io.Reader{strings.NewReader(firstGVCF), strings.NewReader(secondGVCF)}, &buffer)
+	assert.NoError(s.T(), err)
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	records := make([]string, 0)
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "#") {
+			records = append(records, line)
+		}
+	}
+
+	assert.Contains(s.T(), lines, "#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tS1\tS2")
+	assert.NotContains(s.T(), buffer.String(), "ID=END")
+	assert.Equal(s.T(), []string{
+		"20\t150\t.\tG\tA\t40\t.\tDP=15\tGT:DP:GQ\t0/0:25:60\t1/1:15:40",
+		"20\t200\t.\tC\tG\t70\t.\tDP=18\tGT:DP:GQ\t0/.:20:99\t1/.:18:70",
+		"20\t200\t.\tC\tT\t50\t.\tDP=20\tGT:DP:GQ\t0/1:20:99\t./1:18:70",
+		"21\t55\t.\tT\tC\t30\t.\tDP=12\tGT:DP:GQ\t0/0:9:20\t0/1:12:30",
+	}, records)
+}
+
+func (s *MergeGVCFsSuite) TestHighestVersion() {
+	encoded := strings.Replace(secondGVCF, "##fileformat=VCFv4.2", "##fileformat=VCFv4.3", 1)
+	encoded = strings.Replace(encoded, "DP=15\t", "DP=15;NOTE=a%3Bb%3Dc\t", 1)
+
+	var buffer bytes.Buffer
+	err := MergeGVCFs([]io.Reader{strings.NewReader(firstGVCF), strings.NewReader(encoded)}, &buffer)
+	assert.NoError(s.T(), err)
+	assert.True(s.T(), strings.HasPrefix(buffer.String(), "##fileformat=VCFv4.3\n"))
+	assert.Contains(s.T(), buffer.String(), "\tDP=15;NOTE=a%3Bb%3Dc\t", "decoded values should be encoded again")
+}
+
+func (s *MergeGVCFsSuite) TestUncoveredSamplesAreMissing() {
+	var buffer bytes.Buffer
+	err := MergeGVCFs([]io.Reader{strings.NewReader(secondGVCF), strings.NewReader(`##fileformat=VCFv4.2
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	S3
+20	100	.	A	<NON_REF>	.	.	END=120	GT:DP:GQ:MIN_DP	0/0:30:60:25
+`)}, &buffer)
+	assert.NoError(s.T(), err)
+	assert.Contains(s.T(), buffer.String(), "20\t150\t.\tG\tA\t40\t.\tDP=15\tGT:DP:GQ\t1/1:15:40\t./.:.:.\n")
+}
+
+func (s *MergeGVCFsSuite) TestDuplicateSamples() {
+	var buffer bytes.Buffer
+	err := MergeGVCFs([]io.Reader{strings.NewReader(firstGVCF), strings.NewReader(firstGVCF)}, &buffer)
+	assert.Error(s.T(), err)
+}
+
+func (s *MergeGVCFsSuite) TestInvalidLinesAreReported() {
+	var buffer bytes.Buffer
+	err := MergeGVCFs([]io.Reader{strings.NewReader(firstGVCF + "20\tnot a line\n")}, &buffer)
+	assert.Error(s.T(), err)
+	assert.Contains(s.T(), buffer.String(), "20\t200\t.\tC\tT")
+}
+
+func TestMergeGVCFsSuite(t *testing.T) {
+	suite.Run(t, new(MergeGVCFsSuite))
+}
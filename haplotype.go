@@ -0,0 +1,168 @@
+package vcf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultClusterDistance is the largest distance between variants of a cluster when none is given to Compare.
+const DefaultClusterDistance = 10
+
+// maxUnphasedCalls bounds the heterozygous unphased calls of a side of a cluster, since every orientation of
+// them is tried. Larger clusters are left unmatched.
+const maxUnphasedCalls = 12
+
+// matchHaplotypes gathers the assessed variants left without a match into clusters of nearby variants, and
+// matches every variant of a cluster when the truth and the query spell the same haplotypes.
+func (c *comparison) matchHaplotypes(chrom string) error {
+	var pending []*comparedVariant
+	query := make(map[*comparedVariant]bool)
+	for _, side := range []struct {
+		variants []*comparedVariant
+		query    bool
+	}{{c.truthVariants, false}, {c.queryVariants, true}} {
+		for _, compared := range side.variants {
+			if compared.status == "" && compared.class != ClassSV {
+				pending = append(pending, compared)
+				query[compared] = side.query
+			}
+		}
+	}
+	sort.SliceStable(pending, func(i, j int) bool { return pending[i].pos < pending[j].pos })
+
+	distance := c.options.ClusterDistance
+	if distance == 0 {
+		distance = DefaultClusterDistance
+	}
+	for start := 0; start < len(pending); {
+		end, last := start+1, pending[start].pos+len(pending[start].ref)
+		for end < len(pending) && pending[end].pos-last <= distance {
+			if variantEnd := pending[end].pos + len(pending[end].ref); variantEnd > last {
+				last = variantEnd
+			}
+			end++
+		}
+
+		var truthCluster, queryCluster []*comparedVariant
+		for _, compared := range pending[start:end] {
+			if query[compared] {
+				queryCluster = append(queryCluster, compared)
+			} else {
+				truthCluster = append(truthCluster, compared)
+			}
+		}
+		if len(truthCluster) > 0 && len(queryCluster) > 0 {
+			matched, err := c.sameHaplotypes(chrom, pending[start].pos, last, truthCluster, queryCluster)
+			if err != nil {
+				return fmt.Errorf("unable to compare haplotypes at %s:%d: %v", chrom, pending[start].pos+1, err)
+			}
+			if matched {
+				for _, compared := range pending[start:end] {
+					compared.status = benchTruePositive
+				}
+			}
+		}
+		start = end
+	}
+	return nil
+}
+
+// sameHaplotypes tells whether both sides of a cluster spanning the 0-based half-open region spell the same
+// haplotypes.
+func (c *comparison) sameHaplotypes(chrom string, start, end int, truth, query []*comparedVariant) (bool, error) {
+	reference, err := c.options.Reference.Bases(chrom, start, end)
+	if err != nil {
+		return false, err
+	}
+	truthHaplotypes := haplotypes(reference, start, truth, c.options.MatchGenotype)
+	for sequences := range haplotypes(reference, start, query, c.options.MatchGenotype) {
+		if truthHaplotypes[sequences] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// haplotypes returns every set of haplotypes the variants may spell on the reference, which starts at the 0-based
+// position start. Each set is written as its sorted sequences joined by '|', so that sets can be compared
+// regardless of the order of the haplotypes. Without genotypes, the only set holds a single haplotype with every
+// variant.
+func haplotypes(reference string, start int, variants []*comparedVariant, genotypes bool) map[string]bool {
+	found := make(map[string]bool)
+	if !genotypes {
+		if sequence, ok := applyVariants(reference, start, variants); ok {
+			found[sequence] = true
+		}
+		return found
+	}
+
+	ploidy := 0
+	var unphased []int
+	for i, compared := range variants {
+		if compared.genotype == nil {
+			return found
+		}
+		if len(compared.genotype.Alleles) > ploidy {
+			ploidy = len(compared.genotype.Alleles)
+		}
+		if !compared.genotype.Phased && !compared.genotype.allEqual(compared.genotype.Alleles[0]) {
+			unphased = append(unphased, i)
+		}
+	}
+	if ploidy == 0 || len(unphased) > maxUnphasedCalls {
+		return found
+	}
+
+	for orientation := 0; orientation < 1<<uint(len(unphased)); orientation++ {
+		flipped := make(map[int]bool, len(unphased))
+		for bit, i := range unphased {
+			flipped[i] = orientation&(1<<uint(bit)) != 0
+		}
+		carried := make([][]*comparedVariant, ploidy)
+		for i, compared := range variants {
+			alleles := compared.genotype.Alleles
+			for haplotype := range alleles {
+				allele := alleles[haplotype]
+				if flipped[i] {
+					allele = alleles[len(alleles)-1-haplotype]
+				}
+				if allele == 1 {
+					carried[haplotype] = append(carried[haplotype], compared)
+				}
+			}
+		}
+
+		sequences := make([]string, ploidy)
+		valid := true
+		for haplotype := range carried {
+			if sequences[haplotype], valid = applyVariants(reference, start, carried[haplotype]); !valid {
+				break
+			}
+		}
+		if valid {
+			sort.Strings(sequences)
+			found[strings.Join(sequences, "|")] = true
+		}
+	}
+	return found
+}
+
+// applyVariants replaces the REF of each variant by its ALT on the reference, which starts at the 0-based position
+// start. Variants must be sorted by position, and it fails when they overlap or their REF differs from the
+// reference.
+func applyVariants(reference string, start int, variants []*comparedVariant) (string, bool) {
+	var builder strings.Builder
+	cursor := start
+	for _, compared := range variants {
+		refStart, refEnd := compared.pos-start, compared.pos-start+len(compared.ref)
+		if compared.pos < cursor || refEnd > len(reference) || reference[refStart:refEnd] != compared.ref {
+			return "", false
+		}
+		builder.WriteString(reference[cursor-start : refStart])
+		builder.WriteString(compared.alt)
+		cursor = compared.pos + len(compared.ref)
+	}
+	builder.WriteString(reference[cursor-start:])
+	return builder.String(), true
+}
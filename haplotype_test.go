@@ -0,0 +1,96 @@
+package vcf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type HaplotypeSuite struct {
+	suite.Suite
+	reference *FASTA
+}
+
+func (s *HaplotypeSuite) SetupTest() {
+	var err error
+	s.reference, err = ReadFASTA(strings.NewReader(">chr1\nGGCATTTTGCAGGCATGCAG\n"))
+	assert.NoError(s.T(), err)
+}
+
+// haplotypeTruth deletes the A at 4 and changes the T at 5 and the GC at 9 on the same haplotype, which the query
+// writes as a complex indel and two SNVs.
+const haplotypeTruth = `##fileformat=VCFv4.2
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	TRUTH
+chr1	3	.	CA	C	.	PASS	.	GT	0|1
+chr1	5	.	T	G	.	PASS	.	GT	0|1
+chr1	9	.	GC	AT	.	PASS	.	GT	0|1
+`
+
+func (s *HaplotypeSuite) compare(query string, options CompareOptions) *ComparisonReport {
+	options.Reference = s.reference
+	options.HaplotypeMatching = true
+	report, err := Compare(strings.NewReader(haplotypeTruth), strings.NewReader("##fileformat=VCFv4.2\n"+
+		"#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	QUERY\n"+query), options)
+	assert.NoError(s.T(), err)
+	return report
+}
+
+func (s *HaplotypeSuite) TestEquivalentRepresentations() {
+	query := "chr1\t3\t.\tCAT\tCG\t.\tPASS\t.\tGT\t0/1\n" +
+		"chr1\t9\t.\tG\tA\t.\tPASS\t.\tGT\t1/0\n" +
+		"chr1\t10\t.\tC\tT\t.\tPASS\t.\tGT\t0/1\n"
+
+	for _, options := range []CompareOptions{{}, {MatchGenotype: true}, {MatchGenotype: true, ClusterDistance: 2}} {
+		report := s.compare(query, options)
+		assert.Equal(s.T(), ComparisonCounts{TruePositives: 3, QueryTruePositives: 3}, report.Total)
+		assert.Equal(s.T(), &ComparisonCounts{TruePositives: 1}, report.Classes[ClassMNP])
+		assert.Equal(s.T(), &ComparisonCounts{TruePositives: 1, QueryTruePositives: 2}, report.Classes[ClassSNV])
+	}
+
+	report, err := Compare(strings.NewReader(haplotypeTruth), strings.NewReader("##fileformat=VCFv4.2\n"+
+		"#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	QUERY\n"+query), CompareOptions{})
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), ComparisonCounts{FalsePositives: 3, FalseNegatives: 3}, report.Total)
+}
+
+func (s *HaplotypeSuite) TestDifferentHaplotypes() {
+	// the SNVs are phased on different haplotypes, which the MNP of the truth set does not spell
+	query := "chr1\t3\t.\tCAT\tCG\t.\tPASS\t.\tGT\t0/1\n" +
+		"chr1\t9\t.\tG\tA\t.\tPASS\t.\tGT\t1|0\n" +
+		"chr1\t10\t.\tC\tT\t.\tPASS\t.\tGT\t0|1\n"
+
+	report := s.compare(query, CompareOptions{MatchGenotype: true, ClusterDistance: 2})
+	assert.Equal(s.T(), ComparisonCounts{TruePositives: 2, QueryTruePositives: 1, FalsePositives: 2, FalseNegatives: 1}, report.Total)
+	assert.Equal(s.T(), &ComparisonCounts{FalseNegatives: 1}, report.Classes[ClassMNP])
+
+	// a single cluster fails as a whole
+	report = s.compare(query, CompareOptions{MatchGenotype: true})
+	assert.Equal(s.T(), ComparisonCounts{FalsePositives: 3, FalseNegatives: 3}, report.Total)
+
+	// without genotypes, every variant is applied to the same haplotype
+	report = s.compare(query, CompareOptions{})
+	assert.Equal(s.T(), ComparisonCounts{TruePositives: 3, QueryTruePositives: 3}, report.Total)
+}
+
+func (s *HaplotypeSuite) TestRequiresReference() {
+	_, err := Compare(strings.NewReader(haplotypeTruth), strings.NewReader(haplotypeTruth), CompareOptions{HaplotypeMatching: true})
+	assert.EqualError(s.T(), err, "matching haplotypes requires a reference")
+}
+
+func (s *HaplotypeSuite) TestApplyVariants() {
+	variants := []*comparedVariant{{pos: 11, ref: "G", alt: "GTT"}, {pos: 13, ref: "CA", alt: "C"}}
+	sequence, ok := applyVariants("AGGCAT", 10, variants)
+	assert.True(s.T(), ok)
+	assert.Equal(s.T(), "AGTTGCT", sequence)
+
+	_, ok = applyVariants("AGGCAT", 10, []*comparedVariant{{pos: 11, ref: "GG", alt: "G"}, {pos: 12, ref: "G", alt: "T"}})
+	assert.False(s.T(), ok)
+	_, ok = applyVariants("AGGCAT", 10, []*comparedVariant{{pos: 11, ref: "T", alt: "G"}})
+	assert.False(s.T(), ok)
+}
+
+func TestHaplotypeSuite(t *testing.T) {
+	suite.Run(t, new(HaplotypeSuite))
+}
@@ -0,0 +1,383 @@
+package vcf
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Header holds the meta-information lines and the column header line of a VCF file.
+type Header struct {
+	// FileFormat is the version declared on the ##fileformat line, such as "VCFv4.2".
+	FileFormat string
+
+	// MetaLines holds every meta-information line, in the order they appear on the file.
+	MetaLines []*MetaLine
+
+	// Alts holds the ##ALT definitions of symbolic alleles, keyed by their ID.
+	Alts map[string]*AltDefinition
+
+	// Infos holds the ##INFO definitions, keyed by their ID.
+	Infos map[string]*InfoDefinition
+
+	// Formats holds the ##FORMAT definitions, keyed by their ID.
+	Formats map[string]*FormatDefinition
+
+	// Filters holds the ##FILTER definitions, keyed by their ID.
+	Filters map[string]*FilterDefinition
+
+	// Contigs holds the ##contig definitions, in the order they appear on the file.
+	Contigs []*ContigDefinition
+
+	// Metas holds the ##META definitions of the fields allowed on ##SAMPLE lines, keyed by their ID.
+	Metas map[string]*MetaDefinition
+
+	// Samples holds the ##SAMPLE descriptions of the samples, keyed by their ID.
+	Samples map[string]*SampleDefinition
+
+	// GQBands holds the genotype quality bands of gVCF reference blocks, declared on ##GVCFBlock lines.
+	GQBands []GQBand
+
+	// Columns holds the fields of the #CHROM line, including the sample IDs.
+	Columns []string
+
+	// selection maps the samples of Columns to the columns of the file when ToChannel reads a subset of them.
+	selection *sampleSelection
+
+	// lineCount and byteCount are the number of lines and bytes read up to the end of the #CHROM line, used to
+	// locate the records that follow it. columnsOffset is the position of the #CHROM line.
+	lineCount     int
+	byteCount     int64
+	columnsOffset int64
+}
+
+// MetaLine is a single meta-information line, such as ##fileformat=VCFv4.2 or ##INFO=<ID=DP,...>.
+//
+// For structured lines, whose value is enclosed in angle brackets, Fields maps each key to its value,
+// with quotes removed. For unstructured lines Fields is nil and only Value is set.
+type MetaLine struct {
+	Key    string
+	Value  string
+	Fields map[string]string
+}
+
+// AltDefinition is a symbolic allele declared on a ##ALT line.
+type AltDefinition struct {
+	ID          string
+	Description string
+}
+
+// FilterDefinition is a filter declared on a ##FILTER line.
+type FilterDefinition struct {
+	ID          string
+	Description string
+}
+
+// MetaDefinition is a field allowed on ##SAMPLE lines, declared on a ##META line such as
+// ##META=<ID=Assay,Type=String,Number=.,Values=[WholeGenome, Exome]>. Values is nil when any value is allowed.
+type MetaDefinition struct {
+	ID     string
+	Type   string
+	Number string
+	Values []string
+}
+
+// SampleDefinition is a sample described on a ##SAMPLE line, such as ##SAMPLE=<ID=S1,Assay=Exome>. Fields holds
+// every key of the line besides ID, with the raw values.
+type SampleDefinition struct {
+	ID     string
+	Fields map[string]string
+}
+
+// ContigDefinition is a contig declared on a ##contig line. Length is zero when it is not declared.
+type ContigDefinition struct {
+	ID     string
+	Length int
+}
+
+// InfoDefinition is an INFO field declared on a ##INFO line.
+//
+// Number is kept as the raw string of the header, since besides integers it can be one of the special
+// values A (one value per alternate allele), R (one value per allele, including the reference),
+// G (one value per genotype) or . (unknown or unbounded).
+type InfoDefinition struct {
+	ID          string
+	Number      string
+	Type        string
+	Description string
+}
+
+// FormatDefinition is a sample field declared on a ##FORMAT line. Number follows the same rules as the
+// one of InfoDefinition.
+type FormatDefinition struct {
+	ID          string
+	Number      string
+	Type        string
+	Description string
+}
+
+// SampleIDs returns the sample IDs present on the #CHROM line, or nil when there are none.
+func (h *Header) SampleIDs() []string {
+	if len(h.Columns) > 9 {
+		return h.Columns[9:]
+	}
+	return nil
+}
+
+// Version returns the major and minor numbers of the version declared on the ##fileformat line, such as 4 and 3
+// for VCFv4.3. Zeros are returned when the line is missing or malformed.
+func (h *Header) Version() (major, minor int) {
+	numbers := strings.SplitN(strings.TrimPrefix(h.FileFormat, "VCFv"), ".", 2)
+	if len(numbers) != 2 {
+		return 0, 0
+	}
+	major, majorErr := strconv.Atoi(numbers[0])
+	minor, minorErr := strconv.Atoi(numbers[1])
+	if majorErr != nil || minorErr != nil {
+		return 0, 0
+	}
+	return major, minor
+}
+
+// atLeast tells whether the header declares the given version of the spec or a later one. A nil header is
+// taken as an older version.
+func (h *Header) atLeast(major, minor int) bool {
+	if h == nil {
+		return false
+	}
+	declaredMajor, declaredMinor := h.Version()
+	return declaredMajor > major || (declaredMajor == major && declaredMinor >= minor)
+}
+
+// ReadHeader reads the meta-information lines and the column header line from an io.Reader.
+func ReadHeader(reader io.Reader) (*Header, error) {
+	return vcfHeader(bufio.NewReaderSize(reader, 100*1024))
+}
+
+// AddMetaLine parses a meta-information line, such as ##INFO=<ID=DP,Number=1,Type=Integer,Description="Depth">,
+// and adds it to the header. The leading ## is optional.
+func (h *Header) AddMetaLine(line string) {
+	h.addMetaLine(parseMetaLine(strings.TrimSpace(line)))
+}
+
+// String formats the header as it appears on a VCF file, meta-information lines first and the #CHROM
+// line last, each one terminated by a newline.
+func (h *Header) String() string {
+	var builder strings.Builder
+	for _, meta := range h.MetaLines {
+		builder.WriteString(meta.String())
+		builder.WriteByte('\n')
+	}
+	builder.WriteString("#" + strings.Join(h.Columns, "\t") + "\n")
+	return builder.String()
+}
+
+// String formats the meta-information line as it appears on a VCF file, without the trailing newline.
+func (m *MetaLine) String() string {
+	if m.Value == "" && m.Fields == nil {
+		return "##" + m.Key
+	}
+	return "##" + m.Key + "=" + m.Value
+}
+
+func newHeader() *Header {
+	return &Header{
+		Alts:    make(map[string]*AltDefinition),
+		Infos:   make(map[string]*InfoDefinition),
+		Formats: make(map[string]*FormatDefinition),
+		Filters: make(map[string]*FilterDefinition),
+		Metas:   make(map[string]*MetaDefinition),
+		Samples: make(map[string]*SampleDefinition),
+	}
+}
+
+func vcfHeader(bufferedReader *bufio.Reader) (*Header, error) {
+	header := newHeader()
+	for {
+		line, err := bufferedReader.ReadString('\n')
+		if line != "" {
+			header.lineCount++
+			header.byteCount += int64(len(line))
+		}
+		if strings.HasPrefix(line, "##") {
+			header.addMetaLine(parseMetaLine(strings.TrimSpace(line)))
+		} else if strings.HasPrefix(line, "#") {
+			header.columnsOffset = header.byteCount - int64(len(line))
+			line = strings.TrimSpace(line)
+			header.Columns = strings.Split(line[1:], "\t")
+			return header, nil
+		}
+		if err == io.EOF {
+			return nil, errors.New("vcf header not found on file")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (h *Header) addMetaLine(meta *MetaLine) {
+	h.MetaLines = append(h.MetaLines, meta)
+	switch meta.Key {
+	case "fileformat":
+		h.FileFormat = meta.Value
+	case "ALT":
+		if id, ok := meta.Fields["ID"]; ok {
+			h.Alts[id] = &AltDefinition{ID: id, Description: meta.Fields["Description"]}
+		}
+	case "INFO":
+		if id, ok := meta.Fields["ID"]; ok {
+			h.Infos[id] = &InfoDefinition{
+				ID:          id,
+				Number:      meta.Fields["Number"],
+				Type:        meta.Fields["Type"],
+				Description: meta.Fields["Description"],
+			}
+		}
+	case "FORMAT":
+		if id, ok := meta.Fields["ID"]; ok {
+			h.Formats[id] = &FormatDefinition{
+				ID:          id,
+				Number:      meta.Fields["Number"],
+				Type:        meta.Fields["Type"],
+				Description: meta.Fields["Description"],
+			}
+		}
+	case "FILTER":
+		if id, ok := meta.Fields["ID"]; ok {
+			h.Filters[id] = &FilterDefinition{ID: id, Description: meta.Fields["Description"]}
+		}
+	case "META":
+		if id, ok := meta.Fields["ID"]; ok {
+			definition := &MetaDefinition{ID: id, Type: meta.Fields["Type"], Number: meta.Fields["Number"]}
+			if values, found := meta.Fields["Values"]; found {
+				definition.Values = parseMetaValues(values)
+			}
+			h.Metas[id] = definition
+		}
+	case "SAMPLE":
+		if id, ok := meta.Fields["ID"]; ok {
+			definition := &SampleDefinition{ID: id, Fields: make(map[string]string)}
+			for key, value := range meta.Fields {
+				if key != "ID" {
+					definition.Fields[key] = value
+				}
+			}
+			h.Samples[id] = definition
+		}
+	case "contig":
+		if id, ok := meta.Fields["ID"]; ok {
+			length, _ := strconv.Atoi(meta.Fields["length"])
+			h.Contigs = append(h.Contigs, &ContigDefinition{ID: id, Length: length})
+		}
+	default:
+		if strings.HasPrefix(meta.Key, "GVCFBlock") {
+			if band, ok := parseGQBand(meta); ok {
+				h.GQBands = append(h.GQBands, band)
+			}
+		}
+	}
+}
+
+func parseMetaLine(line string) *MetaLine {
+	line = strings.TrimPrefix(line, "##")
+	meta := &MetaLine{}
+	separator := strings.Index(line, "=")
+	if separator < 0 {
+		meta.Key = line
+		return meta
+	}
+	meta.Key, meta.Value = line[:separator], line[separator+1:]
+	if strings.HasPrefix(meta.Value, "<") && strings.HasSuffix(meta.Value, ">") {
+		meta.Fields = parseStructuredMetaValue(meta.Value[1 : len(meta.Value)-1])
+	}
+	return meta
+}
+
+// parseStructuredMetaValue splits the contents of a structured meta-information line into its key-value
+// pairs. Commas inside quoted values or square brackets, as used by the lists of VCF 4.3, do not split fields,
+// and backslash escapes are honored within quotes.
+func parseStructuredMetaValue(value string) map[string]string {
+	fields := make(map[string]string)
+	var key, current strings.Builder
+	inKey, quoted, escaped := true, false, false
+	brackets := 0
+
+	flush := func() {
+		if key.Len() > 0 {
+			fields[strings.TrimSpace(key.String())] = current.String()
+		}
+		key.Reset()
+		current.Reset()
+		inKey = true
+	}
+
+	for _, r := range value {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case inKey && r == '=':
+			inKey = false
+		case !quoted && r == ',' && brackets == 0:
+			flush()
+		case inKey:
+			key.WriteRune(r)
+		default:
+			if !quoted && r == '[' {
+				brackets++
+			} else if !quoted && r == ']' && brackets > 0 {
+				brackets--
+			}
+			current.WriteRune(r)
+		}
+	}
+	flush()
+
+	return fields
+}
+
+// parseMetaValues splits a list of values such as [WholeGenome, Exome].
+func parseMetaValues(list string) []string {
+	list = strings.TrimSuffix(strings.TrimPrefix(list, "["), "]")
+	values := strings.Split(list, ",")
+	for i, value := range values {
+		values[i] = strings.TrimSpace(value)
+	}
+	return values
+}
+
+// fixedColumns are the mandatory columns of the #CHROM line.
+var fixedColumns = []string{"CHROM", "POS", "ID", "REF", "ALT", "QUAL", "FILTER", "INFO"}
+
+// strictHeaderErrors reports the deviations from the spec of a header that the Strict level rejects.
+func strictHeaderErrors(h *Header) []InvalidLine {
+	var invalids []InvalidLine
+	if len(h.MetaLines) == 0 || h.MetaLines[0].Key != "fileformat" || !strings.HasPrefix(h.FileFormat, "VCFv") {
+		invalids = append(invalids, InvalidLine{
+			LineNumber: 1,
+			Err:        &ParseError{Kind: InvalidHeader, Message: "the first line must be ##fileformat=VCFv4.x"},
+		})
+	}
+
+	columnsLine := "#" + strings.Join(h.Columns, "\t")
+	wrongColumns := len(h.Columns) < len(fixedColumns) || (len(h.Columns) > len(fixedColumns) && h.Columns[len(fixedColumns)] != "FORMAT")
+	for i := 0; i < len(fixedColumns) && !wrongColumns; i++ {
+		wrongColumns = h.Columns[i] != fixedColumns[i]
+	}
+	if wrongColumns {
+		invalids = append(invalids, InvalidLine{
+			Line:       columnsLine,
+			LineNumber: h.lineCount,
+			Offset:     h.columnsOffset,
+			Err:        &ParseError{Kind: InvalidHeader, Value: columnsLine, Message: "the #CHROM line must start with the columns " + strings.Join(fixedColumns, ", ") + ", followed by FORMAT when there are samples"},
+		})
+	}
+	return invalids
+}
@@ -0,0 +1,94 @@
+package vcf
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type HeaderSuite struct {
+	suite.Suite
+}
+
+func (s *HeaderSuite) TestMetaLines() {
+	text := `##fileformat=VCFv4.2
+##source=myImputationProgramV3.1
+##ALT=<ID=DEL:ME:ALU,Description="Deletion of ALU element">
+##INFO=<ID=DP,Number=1,Type=Integer,Description="Total Depth, \"raw\"">
+##FILTER=<ID=q10,Description="Quality below 10">
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	NA00001	NA00002
+`
+	header, err := vcfHeader(bufio.NewReader(strings.NewReader(text)))
+
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "VCFv4.2", header.FileFormat)
+	assert.Len(s.T(), header.MetaLines, 5)
+
+	assert.Equal(s.T(), "source", header.MetaLines[1].Key)
+	assert.Equal(s.T(), "myImputationProgramV3.1", header.MetaLines[1].Value)
+	assert.Nil(s.T(), header.MetaLines[1].Fields)
+
+	info := header.MetaLines[3]
+	assert.Equal(s.T(), "INFO", info.Key)
+	assert.Equal(s.T(), "DP", info.Fields["ID"])
+	assert.Equal(s.T(), "Integer", info.Fields["Type"])
+	assert.Equal(s.T(), `Total Depth, "raw"`, info.Fields["Description"])
+
+	alt, found := header.Alts["DEL:ME:ALU"]
+	assert.True(s.T(), found)
+	assert.Equal(s.T(), "Deletion of ALU element", alt.Description)
+
+	filter, found := header.Filters["q10"]
+	assert.True(s.T(), found)
+	assert.Equal(s.T(), "Quality below 10", filter.Description)
+
+	assert.Equal(s.T(), []string{"NA00001", "NA00002"}, header.SampleIDs())
+}
+
+func (s *HeaderSuite) TestColumnLineWithoutNewline() {
+	header, err := ReadHeader(strings.NewReader("#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO"))
+
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), header.Columns, 8)
+	assert.Nil(s.T(), header.SampleIDs())
+}
+
+func (s *HeaderSuite) TestMissingColumnLine() {
+	header, err := ReadHeader(strings.NewReader("##fileformat=VCFv4.2\n"))
+
+	assert.Error(s.T(), err)
+	assert.Nil(s.T(), header)
+}
+
+func (s *HeaderSuite) TestMetaAndSampleLines() {
+	text := `##fileformat=VCFv4.3
+##META=<ID=Assay,Type=String,Number=.,Values=[WholeGenome, Exome]>
+##SAMPLE=<ID=S1,Assay=Exome,Description="Tumor, primary">
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	S1
+`
+	header, err := ReadHeader(strings.NewReader(text))
+
+	assert.NoError(s.T(), err)
+	major, minor := header.Version()
+	assert.Equal(s.T(), []int{4, 3}, []int{major, minor})
+	assert.True(s.T(), header.atLeast(4, 3))
+	assert.False(s.T(), header.atLeast(4, 4))
+
+	assert.Equal(s.T(), &MetaDefinition{ID: "Assay", Type: "String", Number: ".", Values: []string{"WholeGenome", "Exome"}}, header.Metas["Assay"])
+	assert.Equal(s.T(), &SampleDefinition{ID: "S1", Fields: map[string]string{"Assay": "Exome", "Description": "Tumor, primary"}}, header.Samples["S1"])
+}
+
+func (s *HeaderSuite) TestMalformedVersion() {
+	for _, fileFormat := range []string{"", "VCFv4", "VCFvX.2"} {
+		major, minor := (&Header{FileFormat: fileFormat}).Version()
+		assert.Zero(s.T(), major, fileFormat)
+		assert.Zero(s.T(), minor, fileFormat)
+	}
+}
+
+func TestHeaderSuite(t *testing.T) {
+	suite.Run(t, new(HeaderSuite))
+}
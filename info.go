@@ -1,6 +1,7 @@
 package vcf
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 )
@@ -20,76 +21,113 @@ func infoToMap(info string) map[string]interface{} {
 	return infoMap
 }
 
-func buildInfoSubFields(variant *Variant) {
+func buildInfoSubFields(variant *Variant, warn warnFunc) {
 	info := variant.Info
-	variant.Depth = parseIntFromInfoMap("DP", info)
-	variant.AlleleFrequency = parseFloatFromInfoMap("AF", info)
-	variant.AncestralAllele = parseStringFromInfoMap("AA", info)
-	variant.AlleleCount = parseIntFromInfoMap("AC", info)
-	variant.TotalAlleles = parseIntFromInfoMap("AN", info)
-	variant.End = parseIntFromInfoMap("END", info)
-	variant.MAPQ0Reads = parseIntFromInfoMap("MQ0", info)
-	variant.NumberOfSamples = parseIntFromInfoMap("NS", info)
-	variant.MappingQuality = parseFloatFromInfoMap("MQ", info)
-	variant.Cigar = parseStringFromInfoMap("CIGAR", info)
-	variant.InDBSNP = parseBoolFromInfoMap("DB", info)
-	variant.InHapmap2 = parseBoolFromInfoMap("H2", info)
-	variant.InHapmap3 = parseBoolFromInfoMap("H3", info)
-	variant.IsSomatic = parseBoolFromInfoMap("SOMATIC", info)
-	variant.IsValidated = parseBoolFromInfoMap("VALIDATED", info)
-	variant.In1000G = parseBoolFromInfoMap("1000G", info)
-	variant.BaseQuality = parseFloatFromInfoMap("BQ", info)
-	variant.StrandBias = parseFloatFromInfoMap("SB", info)
-	variant.Imprecise = parseBoolFromInfoMap("IMPRECISE", info)
-	variant.Novel = parseBoolFromInfoMap("NOVEL", info)
-
-	if rawSVType := parseStringFromInfoMap("SVTYPE", info); rawSVType != nil {
+	variant.Depth = parseIntFromInfoMap("DP", info, warn)
+	variant.AlleleFrequency = parseFloatFromInfoMap("AF", info, warn)
+	variant.AncestralAllele = parseStringFromInfoMap("AA", info, warn)
+	variant.AlleleCount = parseIntFromInfoMap("AC", info, warn)
+	variant.TotalAlleles = parseIntFromInfoMap("AN", info, warn)
+	variant.End = parseIntFromInfoMap("END", info, warn)
+	variant.MAPQ0Reads = parseIntFromInfoMap("MQ0", info, warn)
+	variant.NumberOfSamples = parseIntFromInfoMap("NS", info, warn)
+	variant.MappingQuality = parseFloatFromInfoMap("MQ", info, warn)
+	variant.Cigar = parseStringFromInfoMap("CIGAR", info, warn)
+	variant.InDBSNP = parseBoolFromInfoMap("DB", info, warn)
+	variant.InHapmap2 = parseBoolFromInfoMap("H2", info, warn)
+	variant.InHapmap3 = parseBoolFromInfoMap("H3", info, warn)
+	variant.IsSomatic = parseBoolFromInfoMap("SOMATIC", info, warn)
+	variant.IsValidated = parseBoolFromInfoMap("VALIDATED", info, warn)
+	variant.In1000G = parseBoolFromInfoMap("1000G", info, warn)
+	variant.BaseQuality = parseFloatFromInfoMap("BQ", info, warn)
+	variant.StrandBias = parseFloatFromInfoMap("SB", info, warn)
+	variant.Imprecise = parseBoolFromInfoMap("IMPRECISE", info, warn)
+	variant.Novel = parseBoolFromInfoMap("NOVEL", info, warn)
+
+	if rawSVType := parseStringFromInfoMap("SVTYPE", info, warn); rawSVType != nil {
 		variant.StructuralVariantType = svTypeFromString(rawSVType)
+		if variant.StructuralVariantType == nil {
+			warn("INFO/SVTYPE", "unknown structural variant type "+*rawSVType)
+		}
+	} else if variant.Breakend != nil {
+		breakend := Breakend
+		variant.StructuralVariantType = &breakend
+	} else if variant.Symbolic != nil {
+		variant.StructuralVariantType = variant.Symbolic.Type()
 	}
 
-	variant.StructuralVariantLength = parseIntFromInfoMap("SVLEN", info)
-	variant.ConfidenceIntervalAroundPosition = parseIntFromInfoMap("CIPOS", info)
-	variant.ConfidenceIntervalAroundEnd = parseIntFromInfoMap("CIEND", info)
+	variant.StructuralVariantLength = parseIntFromInfoMap("SVLEN", info, warn)
+	variant.ConfidenceIntervalAroundPosition = parseIntervalFromInfoMap("CIPOS", info, warn)
+	variant.ConfidenceIntervalAroundEnd = parseIntervalFromInfoMap("CIEND", info, warn)
+	variant.ConfidenceIntervalAroundLength = parseIntervalFromInfoMap("CILEN", info, warn)
+	variant.HomologyLength = parseIntFromInfoMap("HOMLEN", info, warn)
+	variant.HomologySequence = parseStringFromInfoMap("HOMSEQ", info, warn)
+	variant.MateID = parseStringFromInfoMap("MATEID", info, warn)
+	variant.Event = parseStringFromInfoMap("EVENT", info, warn)
+	variant.StructuralVariantClaim = parseStringFromInfoMap("SVCLAIM", info, warn)
 }
 
-func parseIntFromInfoMap(key string, info map[string]interface{}) *int {
-	if value, found := info[key]; found {
+// parseIntFromInfoMap and the functions below return nil for absent values and for '.', which stands for a
+// missing value, warning only about values that cannot be parsed.
+func parseIntFromInfoMap(key string, info map[string]interface{}, warn warnFunc) *int {
+	if value, found := info[key]; found && value != "." {
 		if str, ok := value.(string); ok {
 			intvalue, err := strconv.Atoi(str)
 			if err == nil {
 				return &intvalue
 			}
 		}
+		warn("INFO/"+key, fmt.Sprintf("unable to parse %v as integer, ignoring it", value))
 	}
 	return nil
 }
 
-func parseStringFromInfoMap(key string, info map[string]interface{}) *string {
+func parseIntervalFromInfoMap(key string, info map[string]interface{}, warn warnFunc) *ConfidenceInterval {
+	if value, found := info[key]; found && value != "." {
+		if str, ok := value.(string); ok {
+			bounds := strings.Split(str, ",")
+			if len(bounds) == 2 {
+				lower, lowerErr := strconv.Atoi(bounds[0])
+				upper, upperErr := strconv.Atoi(bounds[1])
+				if lowerErr == nil && upperErr == nil {
+					return &ConfidenceInterval{Lower: lower, Upper: upper}
+				}
+			}
+		}
+		warn("INFO/"+key, fmt.Sprintf("unable to parse %v as an interval of two integers, ignoring it", value))
+	}
+	return nil
+}
+
+func parseStringFromInfoMap(key string, info map[string]interface{}, warn warnFunc) *string {
 	if value, found := info[key]; found {
 		if str, ok := value.(string); ok {
 			return &str
 		}
+		warn("INFO/"+key, "missing value, ignoring it")
 	}
 	return nil
 }
 
-func parseFloatFromInfoMap(key string, info map[string]interface{}) *float64 {
-	if value, found := info[key]; found {
+func parseFloatFromInfoMap(key string, info map[string]interface{}, warn warnFunc) *float64 {
+	if value, found := info[key]; found && value != "." {
 		if str, ok := value.(string); ok {
 			floatvalue, err := strconv.ParseFloat(str, 64)
 			if err == nil {
 				return &floatvalue
 			}
 		}
+		warn("INFO/"+key, fmt.Sprintf("unable to parse %v as float, ignoring it", value))
 	}
 	return nil
 }
 
-func parseBoolFromInfoMap(key string, info map[string]interface{}) *bool {
+func parseBoolFromInfoMap(key string, info map[string]interface{}, warn warnFunc) *bool {
 	if value, found := info[key]; found {
 		if b, ok := value.(bool); ok {
 			return &b
 		}
+		warn("INFO/"+key, fmt.Sprintf("flag with value %v, ignoring it", value))
 	}
 	return nil
 }
@@ -104,6 +142,7 @@ var svTypeMap = map[string]SVType{
 	"DEL:ME":     DeletionMobileElement,
 	"INS:ME":     InsertionMobileElement,
 	"BND":        Breakend,
+	"CNV:TR":     TandemRepeatCopyNumber,
 }
 
 func svTypeFromString(s *string) *SVType {
@@ -113,24 +152,70 @@ func svTypeFromString(s *string) *SVType {
 	return nil
 }
 
-func splitMultipleAltInfos(info map[string]interface{}, numberOfAlternatives int) []map[string]interface{} {
+// reservedInfoNumbers lists the Number of reserved INFO keys whose values are not one per alternate allele,
+// so that they are not split across alternatives when the header does not declare them. Annotations such as
+// the CSQ of VEP and the ANN and EFF of SnpEff hold one comma-separated entry per transcript, and are kept
+// whole for every alternative.
+var reservedInfoNumbers = map[string]string{
+	"CIPOS": "2",
+	"CIEND": "2",
+	"CILEN": "2",
+	"CSQ":   ".",
+	"ANN":   ".",
+	"EFF":   ".",
+}
+
+// infoNumber returns the declared Number of an INFO key, falling back to the reserved keys of the spec.
+// An empty string is returned for keys that are not known.
+func infoNumber(key string, header *Header) string {
+	if header != nil {
+		if definition, found := header.Infos[key]; found {
+			return definition.Number
+		}
+	}
+	return reservedInfoNumbers[key]
+}
+
+// splitMultipleAltInfos distributes the INFO values among the alternatives of a line. Values with one entry
+// per alternate allele (Number=A) or per allele (Number=R) are split, with Number=R values keeping the entry of
+// the reference before the one of the alternative, while flags and other declared values are copied to every
+// alternative. Undeclared values are split whenever they contain commas. Values beyond the number of alternatives
+// are dropped.
+func splitMultipleAltInfos(info map[string]interface{}, numberOfAlternatives int, header *Header, warn warnFunc) []map[string]interface{} {
 	maps := make([]map[string]interface{}, 0, 2)
 	separator := ","
 
 	for key, v := range info {
-		if value, ok := v.(string); ok {
-			if strings.Contains(value, separator) {
-				alternatives := strings.Split(value, separator)
-				for position, alt := range alternatives {
-					maps = insertMapSlice(maps, position, key, alt)
-				}
-			} else {
-				for i := 0; i < numberOfAlternatives; i++ {
-					maps = insertMapSlice(maps, i, key, value)
+		value, ok := v.(string)
+		if !ok {
+			for i := 0; i < numberOfAlternatives; i++ {
+				maps = insertMapSlice(maps, i, key, v)
+			}
+			continue
+		}
+
+		number := infoNumber(key, header)
+		if strings.Contains(value, separator) && (number == "" || number == "A" || number == "R") {
+			alternatives := strings.Split(value, separator)
+			if number == "R" && len(alternatives) == numberOfAlternatives+1 {
+				// each alternative keeps the entry of the reference along with its own
+				for position, alt := range alternatives[1:] {
+					maps = insertMapSlice(maps, position, key, alternatives[0]+separator+alt)
 				}
+				continue
+			} else if number == "R" {
+				alternatives = alternatives[1:]
+			}
+			if number != "" && len(alternatives) != numberOfAlternatives {
+				warn("INFO/"+key, fmt.Sprintf("expected one value per alternative for Number=%s, found %s", number, value))
+			}
+			for position, alt := range alternatives {
+				maps = insertMapSlice(maps, position, key, alt)
 			}
 		} else {
-			maps = insertMapSlice(maps, 0, key, v)
+			for i := 0; i < numberOfAlternatives; i++ {
+				maps = insertMapSlice(maps, i, key, value)
+			}
 		}
 	}
 
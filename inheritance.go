@@ -0,0 +1,472 @@
+package vcf
+
+import (
+	"errors"
+	"fmt"
+)
+
+// InheritanceMode is a pattern of inheritance that variants may follow in a family.
+type InheritanceMode int
+
+const (
+	// AutosomalRecessive is an autosomal variant homozygous on every affected individual and on no unaffected
+	// one, carried by the parents of the affected individuals.
+	AutosomalRecessive InheritanceMode = iota
+	// CompoundHeterozygous is a pair of autosomal variants of the same gene, heterozygous on every affected
+	// individual and not inherited from the same parent, whose pair no unaffected individual carries.
+	CompoundHeterozygous
+	// XLinked is a recessive chrX variant, outside the pseudoautosomal regions, hemizygous on affected males and
+	// homozygous on affected females, which unaffected males do not carry and unaffected females do not have
+	// homozygous. The mothers of affected males must carry it.
+	XLinked
+	// AutosomalDominant is an autosomal variant carried by every affected individual and by no unaffected one.
+	AutosomalDominant
+	// DeNovo is a variant found on an affected child and on neither of its parents.
+	DeNovo
+)
+
+func (m InheritanceMode) String() string {
+	switch m {
+	case AutosomalRecessive:
+		return "autosomal_recessive"
+	case CompoundHeterozygous:
+		return "compound_heterozygous"
+	case XLinked:
+		return "x_linked"
+	case AutosomalDominant:
+		return "autosomal_dominant"
+	case DeNovo:
+		return "de_novo"
+	}
+	return "unknown"
+}
+
+// InheritanceSample explains the genotype of a member of the family on a match. Genotype is nil when the sample
+// has no call.
+type InheritanceSample struct {
+	ID        string
+	Phenotype Phenotype
+	Genotype  *Genotype
+	Reason    string
+}
+
+// InheritanceMatch is a variant following an inheritance mode, along with the members of the family that
+// satisfied it. For CompoundHeterozygous, Gene is the gene shared with the other variants of the pairs, listed on
+// Partners, and a variant paired on several genes has one match per gene.
+type InheritanceMatch struct {
+	Mode     InheritanceMode
+	Variant  *Variant
+	Gene     string
+	Partners []*Variant
+	Samples  []InheritanceSample
+}
+
+// InheritanceOptions configures an InheritanceFilter.
+type InheritanceOptions struct {
+	// PseudoautosomalRegions holds the regions of chrX and chrY inherited as autosomes. When nil, the whole of
+	// chrX is hemizygous on males.
+	PseudoautosomalRegions *IntervalSet
+
+	// Genes returns the genes of a variant, used to pair variants for CompoundHeterozygous. By default the gene
+	// names of the SnpEff annotations of the variant are used, or the SYMBOL of its VEP consequences.
+	Genes func(*Variant) []string
+}
+
+// InheritanceFilter selects the variants of a family VCF that follow an inheritance mode, given the affection
+// status and the relationships of its samples. Samples missing from the pedigree, or with an unknown phenotype,
+// are ignored, and missing calls never exclude a variant, except on affected individuals.
+type InheritanceFilter struct {
+	members []familyMember
+	mendel  *MendelChecker
+	csq     *CSQDecoder
+	options InheritanceOptions
+}
+
+// familyMember is an individual of the pedigree along with its sample column and the ones of its parents, which
+// are -1 when they are not samples of the VCF.
+type familyMember struct {
+	*Individual
+	column, father, mother int
+}
+
+// NewInheritanceFilter matches the individuals of the pedigree with the samples of the header. It fails when no
+// sample is affected.
+func NewInheritanceFilter(header *Header, pedigree *Pedigree, options InheritanceOptions) (*InheritanceFilter, error) {
+	columns := make(map[string]int)
+	for i, sample := range header.SampleIDs() {
+		columns[sample] = i
+	}
+	column := func(id string) int {
+		if i, found := columns[id]; found && id != "" {
+			return i
+		}
+		return -1
+	}
+
+	filter := &InheritanceFilter{options: options}
+	affected := false
+	for _, individual := range pedigree.Individuals() {
+		if i := column(individual.ID); i >= 0 {
+			filter.members = append(filter.members, familyMember{
+				Individual: individual,
+				column:     i,
+				father:     column(individual.Father),
+				mother:     column(individual.Mother),
+			})
+			affected = affected || individual.Phenotype == Affected
+		}
+	}
+	if !affected {
+		return nil, errors.New("no affected individual of the pedigree among the samples")
+	}
+	filter.mendel, _ = NewMendelChecker(header, pedigree, MendelOptions{PseudoautosomalRegions: options.PseudoautosomalRegions})
+	if _, declared := header.Infos["CSQ"]; declared {
+		filter.csq, _ = NewCSQDecoder(header)
+	}
+	return filter, nil
+}
+
+// Select returns a match for each variant following the mode, in the order of the variants. Compound
+// heterozygous pairs are only found among the given variants, so all the candidates of a gene must be selected
+// at once.
+func (f *InheritanceFilter) Select(mode InheritanceMode, variants []*Variant) []*InheritanceMatch {
+	if mode == CompoundHeterozygous {
+		return f.compoundHeterozygous(variants)
+	}
+	var matches []*InheritanceMatch
+	for _, variant := range variants {
+		var match *InheritanceMatch
+		switch mode {
+		case AutosomalRecessive:
+			match = f.recessive(variant)
+		case XLinked:
+			match = f.xLinked(variant)
+		case AutosomalDominant:
+			match = f.dominant(variant)
+		case DeNovo:
+			match = f.deNovo(variant)
+		}
+		if match != nil {
+			matches = append(matches, match)
+		}
+	}
+	return matches
+}
+
+// call is the genotype of a member of the family on a variant, along with the number of copies of its ALT.
+type call struct {
+	genotype *Genotype
+	copies   int
+}
+
+func (c call) called() bool {
+	return calledGenotype(c.genotype)
+}
+
+// homozygous tells whether every allele of the call is the ALT, which includes hemizygous calls.
+func (c call) homozygous() bool {
+	return c.called() && c.copies == len(c.genotype.Alleles)
+}
+
+func memberCall(variant *Variant, column int) call {
+	if column < 0 {
+		return call{}
+	}
+	genotype := sampleGenotype(variant, column)
+	if genotype == nil {
+		return call{}
+	}
+	copies := 0
+	for _, allele := range genotype.Alleles {
+		if allele == variant.AlleleIndex {
+			copies++
+		}
+	}
+	return call{genotype: genotype, copies: copies}
+}
+
+// explain describes the call of every member of the family, with the reasons given for some of them.
+func (f *InheritanceFilter) explain(mode InheritanceMode, variant *Variant, reasons map[string]string) *InheritanceMatch {
+	match := &InheritanceMatch{Mode: mode, Variant: variant}
+	for _, member := range f.members {
+		c := memberCall(variant, member.column)
+		reason, found := reasons[member.ID]
+		if !found {
+			reason = describeCall(c)
+		}
+		match.Samples = append(match.Samples, InheritanceSample{ID: member.ID, Phenotype: member.Phenotype, Genotype: c.genotype, Reason: reason})
+	}
+	return match
+}
+
+func describeCall(c call) string {
+	switch {
+	case !c.called():
+		return "not called"
+	case c.copies == 0:
+		return "does not carry the ALT"
+	case c.homozygous() && len(c.genotype.Alleles) == 1:
+		return "hemizygous for the ALT"
+	case c.homozygous():
+		return "homozygous for the ALT"
+	}
+	return "heterozygous"
+}
+
+func (f *InheritanceFilter) recessive(variant *Variant) *InheritanceMatch {
+	if hemizygousChromosome(variant, f.options.PseudoautosomalRegions) != "" {
+		return nil
+	}
+	for _, member := range f.members {
+		c := memberCall(variant, member.column)
+		switch {
+		case member.Phenotype == Affected && !c.homozygous():
+			return nil
+		case member.Phenotype == Unaffected && c.homozygous():
+			return nil
+		}
+		if member.Phenotype == Affected {
+			for _, parent := range []int{member.father, member.mother} {
+				if p := memberCall(variant, parent); p.called() && p.copies == 0 {
+					return nil
+				}
+			}
+		}
+	}
+	return f.explain(AutosomalRecessive, variant, nil)
+}
+
+func (f *InheritanceFilter) xLinked(variant *Variant) *InheritanceMatch {
+	if hemizygousChromosome(variant, f.options.PseudoautosomalRegions) != "X" {
+		return nil
+	}
+	for _, member := range f.members {
+		c := memberCall(variant, member.column)
+		switch {
+		case member.Phenotype == Affected && !c.homozygous():
+			return nil
+		case member.Phenotype == Unaffected && member.Sex == Male && c.copies > 0:
+			return nil
+		case member.Phenotype == Unaffected && member.Sex != Male && c.homozygous():
+			return nil
+		}
+		if member.Phenotype == Affected && member.Sex == Male {
+			if mother := memberCall(variant, member.mother); mother.called() && mother.copies == 0 {
+				return nil
+			}
+		}
+	}
+	return f.explain(XLinked, variant, nil)
+}
+
+func (f *InheritanceFilter) dominant(variant *Variant) *InheritanceMatch {
+	if hemizygousChromosome(variant, f.options.PseudoautosomalRegions) != "" {
+		return nil
+	}
+	for _, member := range f.members {
+		c := memberCall(variant, member.column)
+		switch {
+		case member.Phenotype == Affected && (!c.called() || c.copies == 0):
+			return nil
+		case member.Phenotype == Unaffected && c.copies > 0:
+			return nil
+		}
+	}
+	return f.explain(AutosomalDominant, variant, nil)
+}
+
+func (f *InheritanceFilter) deNovo(variant *Variant) *InheritanceMatch {
+	if f.mendel == nil {
+		return nil
+	}
+	reasons := make(map[string]string)
+	for _, result := range f.mendel.Check(variant) {
+		if result.Status == DeNovoCandidate && result.Trio.Child.Phenotype == Affected {
+			reasons[result.Trio.Child.ID] = "carries the ALT, absent from both parents"
+		}
+	}
+	if len(reasons) == 0 {
+		return nil
+	}
+	return f.explain(DeNovo, variant, reasons)
+}
+
+// compoundHeterozygous pairs the candidate variants of each gene that are not found in cis.
+func (f *InheritanceFilter) compoundHeterozygous(variants []*Variant) []*InheritanceMatch {
+	var genes []string
+	byGene := make(map[string][]int)
+	variantGenes := make([][]string, len(variants))
+	for i, variant := range variants {
+		if !f.heterozygousCandidate(variant) {
+			continue
+		}
+		variantGenes[i] = f.genes(variant)
+		for _, gene := range variantGenes[i] {
+			if _, found := byGene[gene]; !found {
+				genes = append(genes, gene)
+			}
+			byGene[gene] = append(byGene[gene], i)
+		}
+	}
+
+	type geneMatch struct {
+		variant int
+		gene    string
+	}
+	matches := make(map[geneMatch]*InheritanceMatch)
+	for _, gene := range genes {
+		candidates := byGene[gene]
+		for a := 0; a < len(candidates); a++ {
+			for b := a + 1; b < len(candidates); b++ {
+				first, second := variants[candidates[a]], variants[candidates[b]]
+				if !f.inTrans(first, second) {
+					continue
+				}
+				for _, pair := range [][2]int{{candidates[a], candidates[b]}, {candidates[b], candidates[a]}} {
+					key := geneMatch{variant: pair[0], gene: gene}
+					match, found := matches[key]
+					if !found {
+						match = f.explain(CompoundHeterozygous, variants[pair[0]], f.origins(variants[pair[0]]))
+						match.Gene = gene
+						matches[key] = match
+					}
+					match.Partners = append(match.Partners, variants[pair[1]])
+				}
+			}
+		}
+	}
+
+	var selected []*InheritanceMatch
+	for i := range variants {
+		for _, gene := range variantGenes[i] {
+			if match, found := matches[geneMatch{variant: i, gene: gene}]; found {
+				selected = append(selected, match)
+			}
+		}
+	}
+	return selected
+}
+
+// heterozygousCandidate tells whether the variant may take part on a compound heterozygous pair: it must be
+// autosomal, heterozygous on every affected individual and not homozygous on unaffected ones.
+func (f *InheritanceFilter) heterozygousCandidate(variant *Variant) bool {
+	if hemizygousChromosome(variant, f.options.PseudoautosomalRegions) != "" {
+		return false
+	}
+	for _, member := range f.members {
+		c := memberCall(variant, member.column)
+		switch {
+		case member.Phenotype == Affected && (!c.called() || c.copies != 1 || len(c.genotype.Alleles) != 2):
+			return false
+		case member.Phenotype == Unaffected && c.homozygous():
+			return false
+		}
+	}
+	return true
+}
+
+// inTrans tells whether two candidates may be on different haplotypes of every affected individual, as told by
+// the parent each one is inherited from or, without parents, by phased genotypes of the same phase set.
+// Unaffected individuals must not carry both.
+func (f *InheritanceFilter) inTrans(first, second *Variant) bool {
+	for _, member := range f.members {
+		firstCall, secondCall := memberCall(first, member.column), memberCall(second, member.column)
+		if member.Phenotype == Unaffected && firstCall.copies > 0 && secondCall.copies > 0 {
+			return false
+		}
+		if member.Phenotype != Affected {
+			continue
+		}
+		firstOrigin, secondOrigin := f.origin(first, member), f.origin(second, member)
+		if firstOrigin != "" && firstOrigin == secondOrigin {
+			return false
+		}
+		if (firstOrigin == "" || secondOrigin == "") && phaseSet(first, member.column) == phaseSet(second, member.column) {
+			if haplotype(firstCall, first.AlleleIndex) >= 0 && haplotype(firstCall, first.AlleleIndex) == haplotype(secondCall, second.AlleleIndex) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// origin returns the parent a heterozygous ALT is inherited from, when only one of the parents carries it.
+func (f *InheritanceFilter) origin(variant *Variant, member familyMember) string {
+	father, mother := memberCall(variant, member.father), memberCall(variant, member.mother)
+	switch {
+	case father.called() && mother.called() && father.copies > 0 && mother.copies == 0:
+		return "father"
+	case father.called() && mother.called() && mother.copies > 0 && father.copies == 0:
+		return "mother"
+	}
+	return ""
+}
+
+// haplotype returns the position of the ALT on a phased heterozygous call, or -1 when it is not phased.
+func haplotype(c call, alleleIndex int) int {
+	if !c.called() || !c.genotype.Phased {
+		return -1
+	}
+	for i, allele := range c.genotype.Alleles {
+		if allele == alleleIndex {
+			return i
+		}
+	}
+	return -1
+}
+
+// phaseSet returns the PS of a sample, which is empty when it is missing. Phased calls without PS belong to the
+// same phase set.
+func phaseSet(variant *Variant, column int) string {
+	if column < 0 || column >= len(variant.Samples) {
+		return ""
+	}
+	if ps := variant.Samples[column]["PS"]; ps != "." {
+		return ps
+	}
+	return ""
+}
+
+// origins explains the affected calls of a compound heterozygous candidate by the parent they come from.
+func (f *InheritanceFilter) origins(variant *Variant) map[string]string {
+	reasons := make(map[string]string)
+	for _, member := range f.members {
+		if member.Phenotype != Affected {
+			continue
+		}
+		if origin := f.origin(variant, member); origin != "" {
+			reasons[member.ID] = fmt.Sprintf("heterozygous, inherited from the %s", origin)
+		} else {
+			reasons[member.ID] = "heterozygous, parent of origin unknown"
+		}
+	}
+	return reasons
+}
+
+// genes returns the genes of the variant, without repetitions.
+func (f *InheritanceFilter) genes(variant *Variant) []string {
+	if f.options.Genes != nil {
+		return f.options.Genes(variant)
+	}
+	var genes []string
+	seen := make(map[string]bool)
+	add := func(gene string) {
+		if gene != "" && !seen[gene] {
+			seen[gene] = true
+			genes = append(genes, gene)
+		}
+	}
+	if annotations, err := variant.Annotations(); err == nil {
+		for _, annotation := range annotations {
+			add(annotation.GeneName)
+		}
+	}
+	if len(genes) == 0 && f.csq != nil {
+		if consequences, err := f.csq.VariantConsequences(variant); err == nil {
+			for _, consequence := range consequences {
+				add(consequence.Symbol)
+			}
+		}
+	}
+	return genes
+}
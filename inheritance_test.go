@@ -0,0 +1,167 @@
+package vcf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type InheritanceSuite struct {
+	suite.Suite
+	filter *InheritanceFilter
+}
+
+func (s *InheritanceSuite) SetupTest() {
+	header, err := ReadHeader(strings.NewReader("##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tDAD\tMOM\tKID1\tKID2\n"))
+	assert.NoError(s.T(), err)
+	pedigree, err := ReadPED(strings.NewReader("F DAD 0 0 1 1\nF MOM 0 0 2 1\nF KID1 DAD MOM 1 2\nF KID2 DAD MOM 2 1\n"))
+	assert.NoError(s.T(), err)
+	s.filter, err = NewInheritanceFilter(header, pedigree, InheritanceOptions{})
+	assert.NoError(s.T(), err)
+}
+
+// familyVariant builds a variant of a gene with the genotypes of DAD, MOM, KID1 and KID2.
+func familyVariant(chrom string, pos int, gene string, genotypes ...string) *Variant {
+	variant := &Variant{Chrom: chrom, Pos: pos, Ref: "A", Alt: "G", AlleleIndex: 1, Alternatives: []string{"G"}, Info: map[string]interface{}{}}
+	if gene != "" {
+		variant.Info["ANN"] = "G|missense_variant|MODERATE|" + gene + "|ENSG1|transcript|ENST1|protein_coding|1/2|c.1A>G|p.M1V|1/100|1/90|1/30||"
+	}
+	for _, gt := range genotypes {
+		variant.Samples = append(variant.Samples, map[string]string{"GT": gt})
+	}
+	return variant
+}
+
+func selectedPositions(matches []*InheritanceMatch) []int {
+	var positions []int
+	for _, match := range matches {
+		positions = append(positions, match.Variant.Pos)
+	}
+	return positions
+}
+
+func (s *InheritanceSuite) TestSingleVariantModes() {
+	variants := []*Variant{
+		familyVariant("1", 1, "", "0/1", "0/1", "1/1", "0/1"),
+		familyVariant("1", 2, "", "0/1", "0/1", "1/1", "1/1"),
+		familyVariant("1", 3, "", "0/0", "0/1", "1/1", "0/0"),
+		familyVariant("1", 4, "", "0/0", "0/0", "0/1", "0/0"),
+		familyVariant("X", 5, "", "0", "0/1", "1", "0/1"),
+		familyVariant("X", 6, "", "1", "0/1", "1", "0/1"),
+		familyVariant("X", 7, "", "0", "0/0", "1", "0/0"),
+		familyVariant("1", 8, "", "./.", "0/0", "0/1", "."),
+	}
+	assert.Equal(s.T(), []int{1}, selectedPositions(s.filter.Select(AutosomalRecessive, variants)))
+	assert.Equal(s.T(), []int{4, 8}, selectedPositions(s.filter.Select(AutosomalDominant, variants)))
+	assert.Equal(s.T(), []int{5}, selectedPositions(s.filter.Select(XLinked, variants)))
+	assert.Equal(s.T(), []int{4, 7}, selectedPositions(s.filter.Select(DeNovo, variants)))
+
+	match := s.filter.Select(AutosomalRecessive, variants)[0]
+	assert.Equal(s.T(), AutosomalRecessive, match.Mode)
+	assert.Equal(s.T(), InheritanceSample{ID: "KID1", Phenotype: Affected, Genotype: &Genotype{Alleles: []int{1, 1}, Separators: []byte{'/'}}, Reason: "homozygous for the ALT"}, match.Samples[2])
+	assert.Equal(s.T(), "heterozygous", match.Samples[0].Reason)
+
+	match = s.filter.Select(DeNovo, variants)[0]
+	assert.Equal(s.T(), "carries the ALT, absent from both parents", match.Samples[2].Reason)
+	assert.Equal(s.T(), "does not carry the ALT", match.Samples[0].Reason)
+
+	match = s.filter.Select(XLinked, variants)[0]
+	assert.Equal(s.T(), "hemizygous for the ALT", match.Samples[2].Reason)
+}
+
+func (s *InheritanceSuite) TestCompoundHeterozygous() {
+	variants := []*Variant{
+		familyVariant("2", 100, "GENE1", "0/1", "0/0", "0/1", "0/1"),
+		familyVariant("2", 200, "GENE1", "0/0", "0/1", "0/1", "0/0"),
+		familyVariant("2", 300, "GENE1", "0/1", "0/0", "0/1", "0/0"),
+		familyVariant("3", 100, "GENE2", "0/1", "0/0", "0/1", "0/1"),
+		familyVariant("3", 200, "GENE2", "0/0", "0/1", "0/1", "0/1"),
+		familyVariant("4", 100, "GENE3", "0/1", "0/0", "1/1", "0/0"),
+		familyVariant("4", 200, "GENE3", "0/0", "0/1", "0/1", "0/0"),
+	}
+	matches := s.filter.Select(CompoundHeterozygous, variants)
+	assert.Equal(s.T(), []int{100, 200, 300}, selectedPositions(matches))
+	assert.Equal(s.T(), "GENE1", matches[0].Gene)
+	assert.Equal(s.T(), []*Variant{variants[1]}, matches[0].Partners)
+	assert.Equal(s.T(), []*Variant{variants[0], variants[2]}, matches[1].Partners)
+	assert.Equal(s.T(), []*Variant{variants[1]}, matches[2].Partners)
+	assert.Equal(s.T(), "heterozygous, inherited from the father", matches[0].Samples[2].Reason)
+	assert.Equal(s.T(), "heterozygous, inherited from the mother", matches[1].Samples[2].Reason)
+
+	// without parents, phased calls tell whether the pair is in cis
+	unphased := []*Variant{
+		familyVariant("5", 100, "", ".", ".", "0|1", "0/0"),
+		familyVariant("5", 200, "", ".", ".", "0|1", "0/0"),
+		familyVariant("5", 300, "", ".", ".", "1|0", "0/0"),
+	}
+	s.filter.options.Genes = func(*Variant) []string { return []string{"GENE4"} }
+	matches = s.filter.Select(CompoundHeterozygous, unphased)
+	assert.Equal(s.T(), []int{100, 200, 300}, selectedPositions(matches))
+	assert.Equal(s.T(), []*Variant{unphased[2]}, matches[0].Partners)
+	assert.Equal(s.T(), "heterozygous, parent of origin unknown", matches[0].Samples[2].Reason)
+}
+
+func (s *InheritanceSuite) TestCompoundHeterozygousSharedGenes() {
+	variants := []*Variant{
+		familyVariant("2", 100, "", "0/1", "0/0", "0/1", "0/0"),
+		familyVariant("2", 200, "", "0/0", "0/1", "0/1", "0/0"),
+	}
+	s.filter.options.Genes = func(*Variant) []string { return []string{"GENE1", "GENE1-AS1"} }
+	matches := s.filter.Select(CompoundHeterozygous, variants)
+	assert.Equal(s.T(), []int{100, 100, 200, 200}, selectedPositions(matches))
+	assert.Equal(s.T(), "GENE1", matches[0].Gene)
+	assert.Equal(s.T(), "GENE1-AS1", matches[1].Gene)
+	for _, match := range matches[:2] {
+		assert.Equal(s.T(), []*Variant{variants[1]}, match.Partners)
+	}
+	for _, match := range matches[2:] {
+		assert.Equal(s.T(), []*Variant{variants[0]}, match.Partners)
+	}
+}
+
+func (s *InheritanceSuite) TestCompoundHeterozygousPhaseSets() {
+	variants := []*Variant{
+		familyVariant("5", 100, "", ".", ".", "0|1", "0/0"),
+		familyVariant("5", 200, "", ".", ".", "0|1", "0/0"),
+		familyVariant("5", 300, "", ".", ".", "0|1", "0/0"),
+	}
+	variants[0].Samples[2]["PS"] = "100"
+	variants[1].Samples[2]["PS"] = "100"
+	variants[2].Samples[2]["PS"] = "300"
+	s.filter.options.Genes = func(*Variant) []string { return []string{"GENE4"} }
+
+	// calls on different phase sets may be in trans, while the ones on the same phase set are in cis
+	matches := s.filter.Select(CompoundHeterozygous, variants)
+	assert.Equal(s.T(), []int{100, 200, 300}, selectedPositions(matches))
+	assert.Equal(s.T(), []*Variant{variants[2]}, matches[0].Partners)
+	assert.Equal(s.T(), []*Variant{variants[2]}, matches[1].Partners)
+	assert.Equal(s.T(), []*Variant{variants[0], variants[1]}, matches[2].Partners)
+
+	// a missing PS only matches other calls without it
+	variants[2].Samples[2]["PS"] = "."
+	variants[1].Samples[2]["PS"] = "."
+	matches = s.filter.Select(CompoundHeterozygous, variants)
+	assert.Equal(s.T(), []int{100, 200, 300}, selectedPositions(matches))
+	assert.Equal(s.T(), []*Variant{variants[1], variants[2]}, matches[0].Partners)
+	assert.Equal(s.T(), []*Variant{variants[0]}, matches[1].Partners)
+}
+
+func (s *InheritanceSuite) TestNoAffected() {
+	header, err := ReadHeader(strings.NewReader("##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tDAD\n"))
+	assert.NoError(s.T(), err)
+	pedigree, err := ReadPED(strings.NewReader("F DAD 0 0 1 1\nF KID DAD 0 1 2\n"))
+	assert.NoError(s.T(), err)
+	_, err = NewInheritanceFilter(header, pedigree, InheritanceOptions{})
+	assert.EqualError(s.T(), err, "no affected individual of the pedigree among the samples")
+}
+
+func (s *InheritanceSuite) TestModeString() {
+	assert.Equal(s.T(), "compound_heterozygous", CompoundHeterozygous.String())
+	assert.Equal(s.T(), "x_linked", XLinked.String())
+}
+
+func TestInheritanceSuite(t *testing.T) {
+	suite.Run(t, new(InheritanceSuite))
+}
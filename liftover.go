@@ -0,0 +1,237 @@
+package vcf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// UnmappedReason tells why Liftover could not map a variant to the target assembly.
+type UnmappedReason int
+
+const (
+	// NoChain is a variant outside every chain, such as one on a region missing from the target assembly.
+	NoChain UnmappedReason = iota
+	// SplitByGap is a variant whose bases are only partly aligned, across a gap of a chain.
+	SplitByGap
+	// MultipleTargets is a variant aligned to more than one region of the target assembly.
+	MultipleTargets
+	// ReferenceMismatch is a variant whose REF differs from the bases of the target assembly, which do not match
+	// its ALT either, or do but on an indel or on a line with several alternatives, where swapping them is not
+	// possible.
+	ReferenceMismatch
+	// UnsupportedAllele is a symbolic or breakend ALT, or a record mapped to the reverse strand whose END goes
+	// beyond its REF.
+	UnsupportedAllele
+)
+
+// UnmappedError is returned by Liftover for the variants it cannot map. Pos is 1-based, as written on the file,
+// and TargetBases holds the bases of the target assembly for a ReferenceMismatch.
+type UnmappedError struct {
+	Reason      UnmappedReason
+	Chrom       string
+	Pos         int
+	Ref         string
+	TargetBases string
+}
+
+func (e *UnmappedError) Error() string {
+	var reason string
+	switch e.Reason {
+	case NoChain:
+		reason = "not covered by any chain"
+	case SplitByGap:
+		reason = "spans a gap between chain blocks"
+	case MultipleTargets:
+		reason = "maps to more than one target region"
+	case ReferenceMismatch:
+		reason = fmt.Sprintf("REF %s does not match the target bases %s", e.Ref, e.TargetBases)
+	case UnsupportedAllele:
+		reason = "allele not supported by liftover"
+	}
+	return fmt.Sprintf("%s:%d: %s", e.Chrom, e.Pos, reason)
+}
+
+// Liftover maps variants from the source assembly of a set of chains to its target assembly, checking their
+// alleles against the target reference.
+type Liftover struct {
+	chains *ChainSet
+	target *FASTA
+}
+
+// NewLiftover creates a Liftover from the chains between two assemblies and the reference of the target one.
+func NewLiftover(chains *ChainSet, target *FASTA) *Liftover {
+	return &Liftover{chains: chains, target: target}
+}
+
+// Lift returns a copy of the variant on the target assembly, or an *UnmappedError when it cannot be mapped. Every
+// base of the variant, up to its END when present, must fall within the same ungapped block of a single chain.
+//
+// Variants mapped to the reverse strand have their REF and ALT reverse-complemented, and indels are anchored again
+// on the base before them. When the target reference carries the ALT of an SNV or MNV instead of its REF, as
+// happens when the minor allele of the source assembly became the reference, REF and ALT are swapped: AF becomes
+// 1-AF, AC becomes AN-AC and the genotypes, AD and likelihoods of the samples are switched to the new reference.
+// Indels are never swapped. Alternatives and AlleleIndex are kept as they were on the source line.
+func (l *Liftover) Lift(variant *Variant) (*Variant, error) {
+	unmapped := &UnmappedError{Chrom: variant.Chrom, Pos: variant.Pos + 1, Ref: variant.Ref}
+	if variant.Symbolic != nil || variant.Breakend != nil || isSymbolicAllele(variant.Alt) || isBreakendAllele(variant.Alt) {
+		unmapped.Reason = UnsupportedAllele
+		return nil, unmapped
+	}
+
+	span := variant.end() - variant.Pos
+	mappings, partial := l.chains.mappings(variant.Chrom, variant.Pos, variant.Pos+span)
+	switch {
+	case len(mappings) > 1:
+		unmapped.Reason = MultipleTargets
+		return nil, unmapped
+	case len(mappings) == 0 && partial:
+		unmapped.Reason = SplitByGap
+		return nil, unmapped
+	case len(mappings) == 0:
+		unmapped.Reason = NoChain
+		return nil, unmapped
+	}
+	mapping := mappings[0]
+
+	pos, ref, alt := mapping.start, variant.Ref, variant.Alt
+	if mapping.reverse {
+		if span != len(ref) {
+			unmapped.Reason = UnsupportedAllele
+			return nil, unmapped
+		}
+		ref, alt = reverseComplement(ref), reverseComplement(alt)
+		if len(ref) != len(alt) && ref[len(ref)-1] == alt[len(alt)-1] {
+			// the padding base shared by indels ends up after them, so it is replaced by the base before them
+			anchor, err := l.target.Bases(mapping.chrom, pos-1, pos)
+			if err != nil {
+				return nil, fmt.Errorf("unable to lift %s:%d: %v", variant.Chrom, variant.Pos+1, err)
+			}
+			ref, alt, pos = anchor+ref[:len(ref)-1], anchor+alt[:len(alt)-1], pos-1
+		}
+	}
+
+	bases, err := l.target.Bases(mapping.chrom, pos, pos+len(ref))
+	if err != nil {
+		return nil, fmt.Errorf("unable to lift %s:%d: %v", variant.Chrom, variant.Pos+1, err)
+	}
+	swap := false
+	if !strings.EqualFold(bases, ref) {
+		// only SNVs and MNVs can be swapped: the bases of an indel ALT do not cover the span of its REF, and the
+		// shared anchor base would match the target whatever the rest of the REF is
+		altBases, err := l.target.Bases(mapping.chrom, pos, pos+len(alt))
+		if err != nil || len(alt) != len(ref) || !strings.EqualFold(altBases, alt) || len(variant.Alternatives) > 1 {
+			unmapped.Reason = ReferenceMismatch
+			unmapped.TargetBases = bases
+			return nil, unmapped
+		}
+		swap = true
+	}
+
+	lifted := *variant
+	lifted.Chrom = normalizeChrom(mapping.chrom)
+	lifted.Pos, lifted.Ref, lifted.Alt = pos, ref, alt
+	lifted.Info = make(map[string]interface{}, len(variant.Info))
+	for key, value := range variant.Info {
+		lifted.Info[key] = value
+	}
+	if variant.End != nil {
+		end := pos + span
+		lifted.End = &end
+		lifted.Info["END"] = strconv.Itoa(end)
+	}
+	if swap {
+		lifted.swapReference()
+	}
+	return &lifted, nil
+}
+
+// swapReference makes the ALT of a lifted variant its REF and the other way around, adjusting the allele
+// frequencies and the sample fields that depend on which allele is the reference. The Info map must not be
+// shared with another variant, while the Samples are copied before they change.
+func (v *Variant) swapReference() {
+	v.Ref, v.Alt = v.Alt, v.Ref
+	if v.AlleleFrequency != nil {
+		frequency := 1 - *v.AlleleFrequency
+		v.AlleleFrequency = &frequency
+		v.Info["AF"] = strconv.FormatFloat(frequency, 'g', 6, 64)
+	}
+	if v.AlleleCount != nil && v.TotalAlleles != nil {
+		count := *v.TotalAlleles - *v.AlleleCount
+		v.AlleleCount = &count
+		v.Info["AC"] = strconv.Itoa(count)
+	}
+
+	index := v.AlleleIndex
+	if index < 1 {
+		index = 1
+	}
+	samples := make([]map[string]string, len(v.Samples))
+	for i, sample := range v.Samples {
+		samples[i] = make(map[string]string, len(sample))
+		for key, value := range sample {
+			samples[i][key] = value
+		}
+		if genotype, err := ParseGenotype(sample["GT"]); err == nil {
+			for j, allele := range genotype.Alleles {
+				switch allele {
+				case 0:
+					genotype.Alleles[j] = index
+				case index:
+					genotype.Alleles[j] = 0
+				}
+			}
+			samples[i]["GT"] = genotype.String()
+		}
+		if depths, found := sample["AD"]; found && depths != "." {
+			values := strings.Split(depths, ",")
+			if index < len(values) {
+				values[0], values[index] = values[index], values[0]
+				samples[i]["AD"] = strings.Join(values, ",")
+			}
+		}
+		for _, key := range []string{"PL", "GL", "GP"} {
+			likelihoods, found := sample[key]
+			if !found || likelihoods == "." {
+				continue
+			}
+			// the order of diploid biallelic genotypes, 0/0, 0/1 and 1/1, is reversed by the swap, while other
+			// orders cannot be rearranged without knowing the ploidy
+			values := strings.Split(likelihoods, ",")
+			if len(values) == 3 && index == 1 {
+				samples[i][key] = values[2] + "," + values[1] + "," + values[0]
+			} else {
+				samples[i][key] = "."
+			}
+		}
+	}
+	v.Samples = samples
+}
+
+// reverseComplement returns the bases of the opposite strand, keeping any base other than A, C, G and T.
+func reverseComplement(bases string) string {
+	complement := make([]byte, len(bases))
+	for i := 0; i < len(bases); i++ {
+		base := bases[len(bases)-1-i]
+		switch base {
+		case 'A':
+			base = 'T'
+		case 'C':
+			base = 'G'
+		case 'G':
+			base = 'C'
+		case 'T':
+			base = 'A'
+		case 'a':
+			base = 't'
+		case 'c':
+			base = 'g'
+		case 'g':
+			base = 'c'
+		case 't':
+			base = 'a'
+		}
+		complement[i] = base
+	}
+	return string(complement)
+}
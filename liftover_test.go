@@ -0,0 +1,128 @@
+package vcf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type LiftoverSuite struct {
+	suite.Suite
+	liftover *Liftover
+}
+
+func (s *LiftoverSuite) SetupTest() {
+	chains, err := ReadChains(strings.NewReader(chainText))
+	assert.NoError(s.T(), err)
+	target, err := ReadFASTA(strings.NewReader(">chr1\n" + strings.Repeat("ACGT", 10) + "\n>chr3\n" + strings.Repeat("ACGT", 8) + "\n"))
+	assert.NoError(s.T(), err)
+	s.liftover = NewLiftover(chains, target)
+}
+
+func (s *LiftoverSuite) TestForwardStrand() {
+	end := 12
+	variant := &Variant{Chrom: "1", Pos: 10, Ref: "T", Alt: "C", End: &end, Info: map[string]interface{}{"END": "12", "DP": "7"}}
+
+	lifted, err := s.liftover.Lift(variant)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "1", lifted.Chrom)
+	assert.Equal(s.T(), 15, lifted.Pos)
+	assert.Equal(s.T(), "T", lifted.Ref)
+	assert.Equal(s.T(), "C", lifted.Alt)
+	assert.Equal(s.T(), 17, *lifted.End)
+	assert.Equal(s.T(), map[string]interface{}{"END": "17", "DP": "7"}, lifted.Info)
+
+	// the original variant is untouched
+	assert.Equal(s.T(), 10, variant.Pos)
+	assert.Equal(s.T(), "12", variant.Info["END"])
+}
+
+func (s *LiftoverSuite) TestReverseStrand() {
+	lifted, err := s.liftover.Lift(&Variant{Chrom: "2", Pos: 5, Ref: "T", Alt: "G"})
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "3", lifted.Chrom)
+	assert.Equal(s.T(), 24, lifted.Pos)
+	assert.Equal(s.T(), "A", lifted.Ref)
+	assert.Equal(s.T(), "C", lifted.Alt)
+
+	lifted, err = s.liftover.Lift(&Variant{Chrom: "2", Pos: 5, Ref: "TAC", Alt: "T"})
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 21, lifted.Pos)
+	assert.Equal(s.T(), "CGT", lifted.Ref)
+	assert.Equal(s.T(), "C", lifted.Alt)
+
+	lifted, err = s.liftover.Lift(&Variant{Chrom: "2", Pos: 5, Ref: "T", Alt: "TGG"})
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 23, lifted.Pos)
+	assert.Equal(s.T(), "T", lifted.Ref)
+	assert.Equal(s.T(), "TCC", lifted.Alt)
+}
+
+func (s *LiftoverSuite) TestSwappedReference() {
+	frequency, count, total := 0.1, 1, 10
+	variant := &Variant{
+		Chrom: "1", Pos: 12, Ref: "G", Alt: "C",
+		AlleleFrequency: &frequency, AlleleCount: &count, TotalAlleles: &total,
+		Info:         map[string]interface{}{"AF": "0.1", "AC": "1", "AN": "10"},
+		AlleleIndex:  1,
+		Alternatives: []string{"C"},
+		Samples: []map[string]string{
+			{"GT": "0/1", "AD": "5,3", "PL": "10,0,100"},
+			{"GT": "1|1", "AD": "0,8", "PL": "."},
+			{"GT": "./0", "AD": ".", "GL": "0,-1,-2,-3"},
+		},
+	}
+
+	lifted, err := s.liftover.Lift(variant)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 17, lifted.Pos)
+	assert.Equal(s.T(), "C", lifted.Ref)
+	assert.Equal(s.T(), "G", lifted.Alt)
+	assert.Equal(s.T(), 0.9, *lifted.AlleleFrequency)
+	assert.Equal(s.T(), 9, *lifted.AlleleCount)
+	assert.Equal(s.T(), map[string]interface{}{"AF": "0.9", "AC": "9", "AN": "10"}, lifted.Info)
+	assert.Equal(s.T(), []map[string]string{
+		{"GT": "1/0", "AD": "3,5", "PL": "100,0,10"},
+		{"GT": "0|0", "AD": "8,0", "PL": "."},
+		{"GT": "./1", "AD": ".", "GL": "."},
+	}, lifted.Samples)
+
+	assert.Equal(s.T(), "0/1", variant.Samples[0]["GT"])
+	assert.Equal(s.T(), 0.1, *variant.AlleleFrequency)
+}
+
+func (s *LiftoverSuite) TestUnmapped() {
+	for _, unmapped := range []struct {
+		variant  *Variant
+		reason   UnmappedReason
+		expected string
+	}{
+		{&Variant{Chrom: "1", Pos: 22, Ref: "G", Alt: "A"}, NoChain, "1:23: not covered by any chain"},
+		{&Variant{Chrom: "X", Pos: 22, Ref: "G", Alt: "A"}, NoChain, "X:23: not covered by any chain"},
+		{&Variant{Chrom: "1", Pos: 18, Ref: "GTACG", Alt: "G"}, SplitByGap, "1:19: spans a gap between chain blocks"},
+		{&Variant{Chrom: "1", Pos: 32, Ref: "G", Alt: "A"}, MultipleTargets, "1:33: maps to more than one target region"},
+		{&Variant{Chrom: "1", Pos: 10, Ref: "A", Alt: "G"}, ReferenceMismatch, "1:11: REF A does not match the target bases T"},
+		{&Variant{Chrom: "1", Pos: 12, Ref: "G", Alt: "C", Alternatives: []string{"C", "A"}}, ReferenceMismatch, "1:13: REF G does not match the target bases C"},
+		{&Variant{Chrom: "1", Pos: 10, Ref: "A", Alt: ""}, ReferenceMismatch, "1:11: REF A does not match the target bases T"},
+		{&Variant{Chrom: "1", Pos: 10, Ref: "TC", Alt: "T"}, ReferenceMismatch, "1:11: REF TC does not match the target bases TA"},
+		{&Variant{Chrom: "1", Pos: 10, Ref: "TG", Alt: "TAC"}, ReferenceMismatch, "1:11: REF TG does not match the target bases TA"},
+		{&Variant{Chrom: "1", Pos: 10, Ref: "T", Alt: "<DEL>"}, UnsupportedAllele, "1:11: allele not supported by liftover"},
+	} {
+		lifted, err := s.liftover.Lift(unmapped.variant)
+		assert.Nil(s.T(), lifted)
+		if assert.IsType(s.T(), &UnmappedError{}, err) {
+			assert.Equal(s.T(), unmapped.reason, err.(*UnmappedError).Reason)
+			assert.EqualError(s.T(), err, unmapped.expected)
+		}
+	}
+}
+
+func (s *LiftoverSuite) TestReverseComplement() {
+	assert.Equal(s.T(), "NACGTacgt", reverseComplement("acgtACGTN"))
+}
+
+func TestLiftoverSuite(t *testing.T) {
+	suite.Run(t, new(LiftoverSuite))
+}
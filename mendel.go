@@ -0,0 +1,208 @@
+package vcf
+
+import (
+	"errors"
+	"strings"
+)
+
+// MendelStatus tells whether the genotypes of a trio follow Mendelian inheritance.
+type MendelStatus int
+
+const (
+	// MendelConsistent is a child whose alleles can all be inherited from its parents.
+	MendelConsistent MendelStatus = iota
+	// MendelMissing is a trio that cannot be checked, because of missing genotypes or a chromosome the child
+	// does not inherit, such as chrY on females.
+	MendelMissing
+	// MendelViolation is a child with alleles that cannot be inherited from its parents.
+	MendelViolation
+	// DeNovoCandidate is a violation where the child carries a single copy of the ALT, which neither parent has.
+	DeNovoCandidate
+)
+
+func (s MendelStatus) String() string {
+	switch s {
+	case MendelConsistent:
+		return "consistent"
+	case MendelMissing:
+		return "missing"
+	case MendelViolation:
+		return "violation"
+	case DeNovoCandidate:
+		return "de_novo"
+	}
+	return "unknown"
+}
+
+// MendelResult is the outcome of checking a variant on a trio. Genotypes are nil when absent from the sample.
+type MendelResult struct {
+	Trio           Trio
+	Status         MendelStatus
+	ChildGenotype  *Genotype
+	FatherGenotype *Genotype
+	MotherGenotype *Genotype
+}
+
+// MendelOptions configures a MendelChecker.
+type MendelOptions struct {
+	// PseudoautosomalRegions holds the regions of chrX and chrY inherited as autosomes, which differ between
+	// assemblies. When nil, the whole of chrX and chrY is hemizygous on males.
+	PseudoautosomalRegions *IntervalSet
+}
+
+// MendelChecker flags the genotypes of trios that do not follow Mendelian inheritance. Outside the
+// pseudoautosomal regions, males inherit chrX from their mother only and chrY from their father only, and
+// females inherit the single chrX of their father. Mitochondrial variants are inherited from the mother.
+type MendelChecker struct {
+	trios   []mendelTrio
+	options MendelOptions
+}
+
+// mendelTrio is a trio along with the sample columns of its members.
+type mendelTrio struct {
+	Trio
+	child, father, mother int
+}
+
+// NewMendelChecker finds the trios of the pedigree whose members are all samples of the VCF. It fails when there
+// are none.
+func NewMendelChecker(header *Header, pedigree *Pedigree, options MendelOptions) (*MendelChecker, error) {
+	columns := make(map[string]int)
+	for i, sample := range header.SampleIDs() {
+		columns[sample] = i
+	}
+	checker := &MendelChecker{options: options}
+	for _, trio := range pedigree.Trios() {
+		child, childFound := columns[trio.Child.ID]
+		father, fatherFound := columns[trio.Father.ID]
+		mother, motherFound := columns[trio.Mother.ID]
+		if childFound && fatherFound && motherFound {
+			checker.trios = append(checker.trios, mendelTrio{Trio: trio, child: child, father: father, mother: mother})
+		}
+	}
+	if len(checker.trios) == 0 {
+		return nil, errors.New("no trio of the pedigree has all of its members among the samples")
+	}
+	return checker, nil
+}
+
+// Trios returns the trios checked, in the order of the pedigree.
+func (c *MendelChecker) Trios() []Trio {
+	trios := make([]Trio, len(c.trios))
+	for i, trio := range c.trios {
+		trios[i] = trio.Trio
+	}
+	return trios
+}
+
+// Check returns the status of the variant on each trio, in the order of Trios. Genotypes are compared with the
+// indexes of every allele of the line, so that a variant split from a multiallelic line gets the same status as
+// its siblings, except for de novo candidates, which are only flagged on the variant of the new allele.
+func (c *MendelChecker) Check(variant *Variant) []MendelResult {
+	results := make([]MendelResult, len(c.trios))
+	for i, trio := range c.trios {
+		result := MendelResult{
+			Trio:           trio.Trio,
+			ChildGenotype:  sampleGenotype(variant, trio.child),
+			FatherGenotype: sampleGenotype(variant, trio.father),
+			MotherGenotype: sampleGenotype(variant, trio.mother),
+		}
+		result.Status = c.status(variant, trio.Child.Sex, result.ChildGenotype, result.FatherGenotype, result.MotherGenotype)
+		results[i] = result
+	}
+	return results
+}
+
+func sampleGenotype(variant *Variant, column int) *Genotype {
+	if column >= len(variant.Samples) {
+		return nil
+	}
+	genotype, err := ParseGenotype(variant.Samples[column]["GT"])
+	if err != nil {
+		return nil
+	}
+	return genotype
+}
+
+func (c *MendelChecker) status(variant *Variant, sex Sex, child, father, mother *Genotype) MendelStatus {
+	switch hemizygousChromosome(variant, c.options.PseudoautosomalRegions) {
+	case "M":
+		return hemizygousStatus(variant.AlleleIndex, child, mother)
+	case "X":
+		switch sex {
+		case Male:
+			return hemizygousStatus(variant.AlleleIndex, child, mother)
+		case Female:
+			return diploidStatus(variant.AlleleIndex, child, father, mother)
+		}
+		return MendelMissing
+	case "Y":
+		if sex == Male {
+			return hemizygousStatus(variant.AlleleIndex, child, father)
+		}
+		return MendelMissing
+	}
+	return diploidStatus(variant.AlleleIndex, child, father, mother)
+}
+
+// hemizygousChromosome returns X or Y for variants on those chromosomes outside the pseudoautosomal regions, M
+// for mitochondrial variants and an empty string for variants inherited as autosomes.
+func hemizygousChromosome(variant *Variant, pseudoautosomal *IntervalSet) string {
+	chrom := strings.ToUpper(normalizeChrom(variant.Chrom))
+	switch chrom {
+	case "M", "MT":
+		return "M"
+	case "X", "Y":
+		if pseudoautosomal == nil || !pseudoautosomal.OverlapsVariant(variant) {
+			return chrom
+		}
+	}
+	return ""
+}
+
+// hemizygousStatus checks a child inheriting a single copy from one parent. Diploid calls of the child are
+// accepted when homozygous.
+func hemizygousStatus(alleleIndex int, child, parent *Genotype) MendelStatus {
+	if !calledGenotype(child) || !calledGenotype(parent) {
+		return MendelMissing
+	}
+	if !child.allEqual(child.Alleles[0]) {
+		return MendelViolation
+	}
+	if parent.HasAllele(child.Alleles[0]) {
+		return MendelConsistent
+	}
+	if child.Alleles[0] == alleleIndex && alleleIndex > 0 && !parent.HasAllele(alleleIndex) {
+		return DeNovoCandidate
+	}
+	return MendelViolation
+}
+
+// diploidStatus checks a child inheriting one allele from each parent.
+func diploidStatus(alleleIndex int, child, father, mother *Genotype) MendelStatus {
+	if !calledGenotype(child) || !calledGenotype(father) || !calledGenotype(mother) || len(child.Alleles) != 2 {
+		return MendelMissing
+	}
+	first, second := child.Alleles[0], child.Alleles[1]
+	if (father.HasAllele(first) && mother.HasAllele(second)) || (father.HasAllele(second) && mother.HasAllele(first)) {
+		return MendelConsistent
+	}
+	copies := 0
+	for _, allele := range child.Alleles {
+		if allele == alleleIndex {
+			copies++
+		}
+	}
+	if alleleIndex > 0 && copies == 1 && !father.HasAllele(alleleIndex) && !mother.HasAllele(alleleIndex) {
+		return DeNovoCandidate
+	}
+	return MendelViolation
+}
+
+// calledGenotype tells whether every allele of the genotype is called.
+func calledGenotype(genotype *Genotype) bool {
+	if genotype == nil || len(genotype.Alleles) == 0 {
+		return false
+	}
+	return !genotype.HasAllele(MissingAllele)
+}
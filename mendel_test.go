@@ -0,0 +1,118 @@
+package vcf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type MendelSuite struct {
+	suite.Suite
+	header   *Header
+	pedigree *Pedigree
+}
+
+func (s *MendelSuite) SetupTest() {
+	var err error
+	s.header, err = ReadHeader(strings.NewReader("##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tMOM\tSON\tDAD\tDAUGHTER\n"))
+	assert.NoError(s.T(), err)
+	s.pedigree, err = ReadPED(strings.NewReader("F SON DAD MOM 1 2\nF DAUGHTER DAD MOM 2 1\nF OTHER DAD AUNT 2 1\n"))
+	assert.NoError(s.T(), err)
+}
+
+// variant builds a variant with the genotypes of MOM, SON, DAD and DAUGHTER.
+func mendelVariant(chrom string, alleleIndex int, genotypes ...string) *Variant {
+	variant := &Variant{Chrom: chrom, Pos: 1000, Ref: "A", Alt: "G", AlleleIndex: alleleIndex}
+	for _, gt := range genotypes {
+		variant.Samples = append(variant.Samples, map[string]string{"GT": gt})
+	}
+	return variant
+}
+
+func (s *MendelSuite) statuses(checker *MendelChecker, variant *Variant) []MendelStatus {
+	var statuses []MendelStatus
+	for _, result := range checker.Check(variant) {
+		statuses = append(statuses, result.Status)
+	}
+	return statuses
+}
+
+func (s *MendelSuite) TestAutosomes() {
+	checker, err := NewMendelChecker(s.header, s.pedigree, MendelOptions{})
+	assert.NoError(s.T(), err)
+	trios := checker.Trios()
+	assert.Len(s.T(), trios, 2)
+	assert.Equal(s.T(), "SON", trios[0].Child.ID)
+	assert.Equal(s.T(), "DAUGHTER", trios[1].Child.ID)
+
+	for _, test := range []struct {
+		alleleIndex int
+		genotypes   []string
+		expected    []MendelStatus
+	}{
+		{1, []string{"0/1", "1/1", "0/1", "0/0"}, []MendelStatus{MendelConsistent, MendelConsistent}},
+		{1, []string{"0/0", "0/1", "0/0", "1/1"}, []MendelStatus{DeNovoCandidate, MendelViolation}},
+		{1, []string{"1/1", "0/0", "0/1", "./1"}, []MendelStatus{MendelViolation, MendelMissing}},
+		{1, []string{"0|1", "1|0", "0/0", "1"}, []MendelStatus{MendelConsistent, MendelMissing}},
+		{2, []string{"0/1", "1/2", "0/1", "0/0"}, []MendelStatus{DeNovoCandidate, MendelConsistent}},
+		{1, []string{"0/1", "1/2", "0/1", "0/0"}, []MendelStatus{MendelViolation, MendelConsistent}},
+	} {
+		variant := mendelVariant("1", test.alleleIndex, test.genotypes...)
+		assert.Equal(s.T(), test.expected, s.statuses(checker, variant), "%v", test.genotypes)
+	}
+
+	results := checker.Check(mendelVariant("chr1", 1, "0/0", "0/1", "0/0", "0/0"))
+	assert.Equal(s.T(), "0/1", results[0].ChildGenotype.String())
+	assert.Equal(s.T(), "0/0", results[0].FatherGenotype.String())
+	assert.Equal(s.T(), "0/0", results[0].MotherGenotype.String())
+}
+
+func (s *MendelSuite) TestSexChromosomes() {
+	checker, err := NewMendelChecker(s.header, s.pedigree, MendelOptions{
+		PseudoautosomalRegions: NewIntervalSet([]Interval{{Chrom: "chrX", Start: 0, End: 500}}),
+	})
+	assert.NoError(s.T(), err)
+
+	for _, test := range []struct {
+		chrom     string
+		genotypes []string
+		expected  []MendelStatus
+	}{
+		// the son inherits chrX from his mother, and the daughter gets the single chrX of her father
+		{"X", []string{"0/1", "1", "0", "0/1"}, []MendelStatus{MendelConsistent, MendelConsistent}},
+		{"chrX", []string{"0/1", "1/1", "1", "0/0"}, []MendelStatus{MendelConsistent, MendelViolation}},
+		{"X", []string{"0/0", "1", "1", "1/1"}, []MendelStatus{DeNovoCandidate, MendelViolation}},
+		{"X", []string{"0/0", "0/1", "0", "0/1"}, []MendelStatus{MendelViolation, DeNovoCandidate}},
+		// chrY comes from the father and is not checked on daughters
+		{"Y", []string{".", "1", "1", "."}, []MendelStatus{MendelConsistent, MendelMissing}},
+		{"Y", []string{".", "1", "0", "."}, []MendelStatus{DeNovoCandidate, MendelMissing}},
+		// mitochondria come from the mother
+		{"MT", []string{"1", "1", "0", "0"}, []MendelStatus{MendelConsistent, MendelViolation}},
+	} {
+		variant := mendelVariant(test.chrom, 1, test.genotypes...)
+		assert.Equal(s.T(), test.expected, s.statuses(checker, variant), "%s %v", test.chrom, test.genotypes)
+	}
+
+	// pseudoautosomal regions are diploid
+	variant := mendelVariant("X", 1, "0/0", "0/1", "0/1", "0/0")
+	variant.Pos = 100
+	assert.Equal(s.T(), []MendelStatus{MendelConsistent, MendelConsistent}, s.statuses(checker, variant))
+}
+
+func (s *MendelSuite) TestNoTrio() {
+	pedigree, err := ReadPED(strings.NewReader("F KID P1 P2 0 0\n"))
+	assert.NoError(s.T(), err)
+	_, err = NewMendelChecker(s.header, pedigree, MendelOptions{})
+	assert.EqualError(s.T(), err, "no trio of the pedigree has all of its members among the samples")
+}
+
+func (s *MendelSuite) TestStatusString() {
+	assert.Equal(s.T(), "de_novo", DeNovoCandidate.String())
+	assert.Equal(s.T(), "violation", MendelViolation.String())
+}
+
+func TestMendelSuite(t *testing.T) {
+	suite.Run(t, new(MendelSuite))
+}
@@ -0,0 +1,418 @@
+package vcf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// InfoRule tells how Merge combines the values of an INFO key that is present on more than one input.
+type InfoRule int
+
+const (
+	// InfoFirst keeps the value of the first input carrying the record. It is the default rule.
+	InfoFirst InfoRule = iota
+	// InfoSum adds numeric values up.
+	InfoSum
+	// InfoMax keeps the largest numeric value.
+	InfoMax
+	// InfoJoin joins the distinct values with commas.
+	InfoJoin
+)
+
+// MergeOptions configures Merge.
+type MergeOptions struct {
+	// InfoRules maps INFO keys to the rule used to combine them. Keys that are not listed use InfoFirst.
+	// InfoSum and InfoMax fall back to InfoFirst when any of the values is not numeric.
+	InfoRules map[string]InfoRule
+
+	// FailOnConflict makes Merge fail when the headers declare the same INFO, FORMAT or contig in different
+	// ways. Otherwise the first definition is kept and the conflicts are only reported.
+	FailOnConflict bool
+}
+
+// HeaderConflict is an INFO, FORMAT or contig definition declared differently by two inputs.
+type HeaderConflict struct {
+	Key   string
+	ID    string
+	First string
+	Other string
+}
+
+func (c HeaderConflict) Error() string {
+	return fmt.Sprintf("conflicting %s definitions for %s: %s and %s", c.Key, c.ID, c.First, c.Other)
+}
+
+// Merge combines sorted VCFs with different samples into a single multi-sample VCF written to w.
+//
+// Headers are unified: INFO, FORMAT, FILTER, ALT and contig definitions are declared once, and definitions
+// of the same ID that disagree on Number, Type or length are returned as conflicts. Sample IDs must be
+// unique across inputs.
+//
+// Records are joined on CHROM, POS, REF and ALT. Multiple alternatives are split, so every output record is
+// biallelic, with per-allele INFO and sample fields restricted to its alternative. Samples of inputs that do
+// not have the record are written with missing genotypes. QUAL is the highest one among the inputs, FILTER
+// combines the filters of every input and INFO values are combined according to the options.
+//
+// Lines that cannot be parsed are skipped and reported in the returned error once the output is written, and so
+// are records out of order, since inputs must be sorted for their records to be joined.
+func Merge(readers []io.Reader, w io.Writer, options MergeOptions) ([]HeaderConflict, error) {
+	if len(readers) == 0 {
+		return nil, errors.New("no VCF to merge")
+	}
+
+	streams, err := openVariantStreams(readers, CheckOrder())
+	if err != nil {
+		return nil, err
+	}
+	headers := make([]*Header, len(streams))
+	for i, stream := range streams {
+		headers[i] = stream.header
+	}
+
+	header, conflicts := mergeHeaders(headers)
+	header.Columns, err = mergedColumns(headers)
+	if err == nil && options.FailOnConflict && len(conflicts) > 0 {
+		err = conflicts[0]
+	}
+	if err != nil {
+		closeVariantStreams(streams)
+		return conflicts, err
+	}
+	writer, err := NewWriter(w, header)
+	if err != nil {
+		closeVariantStreams(streams)
+		return conflicts, err
+	}
+
+	order := newContigOrder(headers...)
+	for {
+		chrom, pos, found := nextLocus(streams, order)
+		if !found {
+			break
+		}
+
+		sites := make([][]*Variant, len(streams))
+		keys := make([]alleleKey, 0)
+		seen := make(map[alleleKey]bool)
+		for i, stream := range streams {
+			sites[i] = stream.popLocus(chrom, pos, order)
+			for _, variant := range sites[i] {
+				if key := keyOf(variant); !seen[key] {
+					seen[key] = true
+					keys = append(keys, key)
+				}
+			}
+		}
+
+		sort.Slice(keys, func(i, j int) bool { return keys[i].less(keys[j]) })
+		for _, key := range keys {
+			if err := writer.Write(mergeSite(headers, sites, key, options)); err != nil {
+				closeVariantStreams(streams)
+				return conflicts, err
+			}
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		closeVariantStreams(streams)
+		return conflicts, err
+	}
+	return conflicts, closeVariantStreams(streams)
+}
+
+func mergeSite(headers []*Header, sites [][]*Variant, key alleleKey, options MergeOptions) *Variant {
+	carriers := make([]*Variant, len(sites))
+	var first *Variant
+	for i := range sites {
+		for _, variant := range sites[i] {
+			if keyOf(variant) == key {
+				carriers[i] = variant
+				break
+			}
+		}
+		if first == nil {
+			first = carriers[i]
+		}
+	}
+
+	merged := &Variant{
+		Chrom:        first.Chrom,
+		Pos:          first.Pos,
+		Ref:          key.ref,
+		Alt:          key.alt,
+		AlleleIndex:  1,
+		Alternatives: []string{key.alt},
+		Info:         make(map[string]interface{}),
+		Samples:      make([]map[string]string, 0),
+	}
+
+	filters := make([]string, 0)
+	infoValues := make(map[string][]interface{})
+	infoKeys := make([]string, 0)
+	formatSeen := make(map[string]bool)
+	for i, carrier := range carriers {
+		if carrier == nil {
+			continue
+		}
+		if merged.ID == "" && carrier.ID != "." {
+			merged.ID = carrier.ID
+		}
+		if carrier.Qual != nil && (merged.Qual == nil || *carrier.Qual > *merged.Qual) {
+			qual := *carrier.Qual
+			merged.Qual = &qual
+		}
+		filters = append(filters, strings.Split(carrier.Filter, ";")...)
+		for infoKey, value := range biallelicInfo(carrier, headers[i]) {
+			if _, found := infoValues[infoKey]; !found {
+				infoKeys = append(infoKeys, infoKey)
+			}
+			infoValues[infoKey] = append(infoValues[infoKey], value)
+		}
+		for _, formatKey := range carrier.Format {
+			if !formatSeen[formatKey] {
+				formatSeen[formatKey] = true
+				merged.Format = append(merged.Format, formatKey)
+			}
+		}
+	}
+
+	merged.Filter = mergeFilters(filters)
+	for _, infoKey := range infoKeys {
+		merged.Info[infoKey] = combineInfoValues(infoValues[infoKey], options.InfoRules[infoKey])
+	}
+	if len(merged.Format) == 0 {
+		merged.Format = []string{"GT"}
+	}
+
+	for i, header := range headers {
+		for sample := range header.SampleIDs() {
+			merged.Samples = append(merged.Samples, mergedSampleCall(header, carriers[i], sites[i], sample, merged.Format))
+		}
+	}
+
+	return merged
+}
+
+// mergedSampleCall restricts the call of a sample to the alternative of the merged record. Samples of an input
+// that has other alternatives at the same position keep their reference alleles, and everything else is missing.
+func mergedSampleCall(header *Header, carrier *Variant, sites []*Variant, sample int, format []string) map[string]string {
+	call := make(map[string]string)
+
+	source, alleleIndex := carrier, MissingAllele
+	if carrier != nil {
+		alleleIndex = carrier.AlleleIndex
+	} else if len(sites) > 0 {
+		source = sites[0]
+	}
+	if source == nil || sample >= len(source.Samples) {
+		call["GT"] = "./."
+		return call
+	}
+
+	fields := source.Samples[sample]
+	if genotype, err := ParseGenotype(fields["GT"]); err == nil {
+		call["GT"] = genotype.biallelic(alleleIndex).String()
+	}
+	if carrier == nil {
+		return call
+	}
+	for _, key := range format {
+		value, found := fields[key]
+		if !found || key == "GT" {
+			continue
+		}
+		call[key] = biallelicValue(value, formatNumber(key, header), alleleIndex)
+	}
+	return call
+}
+
+// biallelicValue restricts a per-allele value to the reference and the given alternative, according to the
+// declared Number: A keeps the value of the alternative, R the values of the reference and the alternative,
+// and G the likelihoods of the three diploid genotypes made of both. Values that do not have the expected
+// number of entries are kept as they are.
+func biallelicValue(value, number string, alleleIndex int) string {
+	entries := strings.Split(value, ",")
+	var positions []int
+	switch number {
+	case "A":
+		positions = []int{alleleIndex - 1}
+	case "R":
+		positions = []int{0, alleleIndex}
+	case "G":
+		positions = []int{0, alleleIndex * (alleleIndex + 1) / 2, alleleIndex*(alleleIndex+1)/2 + alleleIndex}
+	default:
+		return value
+	}
+
+	restricted := make([]string, len(positions))
+	for i, position := range positions {
+		if position < 0 || position >= len(entries) {
+			return value
+		}
+		restricted[i] = entries[position]
+	}
+	return strings.Join(restricted, ",")
+}
+
+// mergeFilters keeps every distinct filter, dropping PASS when any other filter is present.
+func mergeFilters(filters []string) string {
+	distinct := make([]string, 0, len(filters))
+	seen := make(map[string]bool)
+	passed := false
+	for _, filter := range filters {
+		switch {
+		case filter == "" || filter == ".":
+		case filter == "PASS":
+			passed = true
+		case !seen[filter]:
+			seen[filter] = true
+			distinct = append(distinct, filter)
+		}
+	}
+	if len(distinct) > 0 {
+		return strings.Join(distinct, ";")
+	}
+	if passed {
+		return "PASS"
+	}
+	return "."
+}
+
+func combineInfoValues(values []interface{}, rule InfoRule) interface{} {
+	if len(values) == 1 {
+		return values[0]
+	}
+	strs := make([]string, 0, len(values))
+	for _, value := range values {
+		if str, ok := value.(string); ok {
+			strs = append(strs, str)
+		}
+	}
+	if len(strs) != len(values) {
+		// flags and other non string values are kept from the first input
+		return values[0]
+	}
+
+	switch rule {
+	case InfoSum, InfoMax:
+		if combined, ok := combineNumbers(strs, rule); ok {
+			return combined
+		}
+	case InfoJoin:
+		distinct := make([]string, 0, len(strs))
+		seen := make(map[string]bool)
+		for _, str := range strs {
+			if !seen[str] {
+				seen[str] = true
+				distinct = append(distinct, str)
+			}
+		}
+		return strings.Join(distinct, ",")
+	}
+	return values[0]
+}
+
+func combineNumbers(values []string, rule InfoRule) (string, bool) {
+	integers := true
+	numbers := make([]float64, len(values))
+	for i, value := range values {
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", false
+		}
+		if _, err := strconv.Atoi(value); err != nil {
+			integers = false
+		}
+		numbers[i] = number
+	}
+
+	result := numbers[0]
+	for _, number := range numbers[1:] {
+		if rule == InfoSum {
+			result += number
+		} else if number > result {
+			result = number
+		}
+	}
+	if integers {
+		return strconv.Itoa(int(result)), true
+	}
+	return strconv.FormatFloat(result, 'f', -1, 64), true
+}
+
+// highestFileFormat returns the latest version declared by the headers, and VCFv4.2 when none is later. Values
+// read from VCF 4.3 files are percent-decoded, so they must be written under a header that encodes them again.
+func highestFileFormat(headers []*Header) string {
+	fileformat, major, minor := "VCFv4.2", 4, 2
+	for _, header := range headers {
+		declaredMajor, declaredMinor := header.Version()
+		if declaredMajor > major || (declaredMajor == major && declaredMinor > minor) {
+			fileformat, major, minor = header.FileFormat, declaredMajor, declaredMinor
+		}
+	}
+	return fileformat
+}
+
+// mergeHeaders unifies the meta-information lines of several inputs, declaring each definition once and
+// reporting the ones that disagree between inputs. The columns of the merged header are left empty.
+func mergeHeaders(headers []*Header) (*Header, []HeaderConflict) {
+	merged := newHeader()
+	var conflicts []HeaderConflict
+
+	merged.AddMetaLine("##fileformat=" + highestFileFormat(headers))
+
+	seen := make(map[string]*MetaLine)
+	for _, header := range headers {
+		for _, meta := range header.MetaLines {
+			if meta.Key == "fileformat" {
+				continue
+			}
+			id, structured := meta.Fields["ID"]
+			identity := meta.String()
+			if structured {
+				identity = meta.Key + "=" + id
+			}
+			previous, found := seen[identity]
+			if !found {
+				seen[identity] = meta
+				merged.addMetaLine(meta)
+				continue
+			}
+			if definitionsConflict(previous, meta) {
+				conflicts = append(conflicts, HeaderConflict{Key: meta.Key, ID: id, First: previous.String(), Other: meta.String()})
+			}
+		}
+	}
+
+	return merged, conflicts
+}
+
+func definitionsConflict(first, other *MetaLine) bool {
+	switch first.Key {
+	case "INFO", "FORMAT":
+		return first.Fields["Number"] != other.Fields["Number"] || first.Fields["Type"] != other.Fields["Type"]
+	case "contig":
+		return first.Fields["length"] != "" && other.Fields["length"] != "" && first.Fields["length"] != other.Fields["length"]
+	}
+	return false
+}
+
+// mergedColumns builds the #CHROM line of a merged file, with the samples of every input in order.
+func mergedColumns(headers []*Header) ([]string, error) {
+	columns := []string{"CHROM", "POS", "ID", "REF", "ALT", "QUAL", "FILTER", "INFO", "FORMAT"}
+	samples := make(map[string]bool)
+	for _, header := range headers {
+		for _, sample := range header.SampleIDs() {
+			if samples[sample] {
+				return nil, errors.New("sample present on more than one input: " + sample)
+			}
+			samples[sample] = true
+			columns = append(columns, sample)
+		}
+	}
+	return columns, nil
+}
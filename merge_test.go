@@ -0,0 +1,123 @@
+package vcf
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+const familyA = `##fileformat=VCFv4.2
+##contig=<ID=1,length=1000>
+##INFO=<ID=DP,Number=1,Type=Integer,Description="Depth">
+##INFO=<ID=AF,Number=A,Type=Float,Description="Allele frequency">
+##INFO=<ID=RD,Number=R,Type=Integer,Description="Read depths">
+##INFO=<ID=GLI,Number=G,Type=Float,Description="Site likelihoods">
+##FORMAT=<ID=GT,Number=1,Type=String,Description="Genotype">
+##FORMAT=<ID=AD,Number=R,Type=Integer,Description="Allelic depths">
+##FORMAT=<ID=PL,Number=G,Type=Integer,Description="Likelihoods">
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	A1	A2
+1	100	rs1	A	G	50	PASS	DP=10;AF=0.25	GT:AD	0/1:6,4	0/0:10,0
+1	200	.	C	T,G	30	q10	DP=20;AF=0.1,0.2;RD=10,5,5;GLI=0,1,2,3,4,5	GT:AD:PL	1/2:0,5,5:90,50,40,60,0,70	0/1:3,3,0:20,0,30,40,50,60
+`
+
+const familyB = `##fileformat=VCFv4.2
+##contig=<ID=1,length=1000>
+##INFO=<ID=DP,Number=1,Type=Integer,Description="Depth">
+##INFO=<ID=AF,Number=A,Type=Float,Description="Allele frequency">
+##INFO=<ID=SRC,Number=1,Type=String,Description="Source">
+##FORMAT=<ID=GT,Number=1,Type=String,Description="Genotype">
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	B1
+1	100	.	A	G	70	PASS	DP=5;AF=0.5;SRC=b	GT	1/1
+1	150	.	T	C	20	PASS	DP=7;AF=0.5	GT	0/1
+`
+
+type MergeSuite struct {
+	suite.Suite
+}
+
+func (s *MergeSuite) records(output string) []string {
+	records := make([]string, 0)
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			records = append(records, line)
+		}
+	}
+	return records
+}
+
+func (s *MergeSuite) TestMerge() {
+	var buffer bytes.Buffer
+	conflicts, err := Merge([]io.Reader{strings.NewReader(familyA), strings.NewReader(familyB)}, &buffer, MergeOptions{
+		InfoRules: map[string]InfoRule{"DP": InfoSum, "AF": InfoMax},
+	})
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), conflicts)
+
+	output := buffer.String()
+	assert.Contains(s.T(), output, "#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tA1\tA2\tB1\n")
+	assert.Equal(s.T(), 1, strings.Count(output, "##INFO=<ID=DP"), "definitions should be declared once")
+	assert.Contains(s.T(), output, "##INFO=<ID=SRC")
+
+	assert.Equal(s.T(), []string{
+		"1\t100\trs1\tA\tG\t70\tPASS\tDP=15;AF=0.5;SRC=b\tGT:AD\t0/1:6,4\t0/0:10,0\t1/1:.",
+		"1\t150\t.\tT\tC\t20\tPASS\tDP=7;AF=0.5\tGT\t./.\t./.\t0/1",
+		"1\t200\t.\tC\tG\t30\tq10\tDP=20;AF=0.2;RD=10,5;GLI=0,3,5\tGT:AD:PL\t./1:0,5:90,60,70\t0/.:3,0:20,40,60\t./.:.:.",
+		"1\t200\t.\tC\tT\t30\tq10\tDP=20;AF=0.1;RD=10,5;GLI=0,1,2\tGT:AD:PL\t1/.:0,5:90,50,40\t0/1:3,3:20,0,30\t./.:.:.",
+	}, s.records(output))
+}
+
+func (s *MergeSuite) TestUnsorted() {
+	unsorted := familyB + "1\t120\t.\tA\tC\t20\tPASS\t.\tGT\t0/1\n"
+	var buffer bytes.Buffer
+	_, err := Merge([]io.Reader{strings.NewReader(familyA), strings.NewReader(unsorted)}, &buffer, MergeOptions{})
+	assert.EqualError(s.T(), err, "1 lines could not be parsed, the first one failed with: line 10: position 120 comes after 150")
+	assert.NotContains(s.T(), buffer.String(), "\t120\t")
+}
+
+func (s *MergeSuite) TestInfoRules() {
+	assert.Equal(s.T(), "12", combineInfoValues([]interface{}{"5", "7"}, InfoSum))
+	assert.Equal(s.T(), "1.5", combineInfoValues([]interface{}{"0.5", "1"}, InfoSum))
+	assert.Equal(s.T(), "7", combineInfoValues([]interface{}{"5", "7"}, InfoMax))
+	assert.Equal(s.T(), "a,b", combineInfoValues([]interface{}{"a", "b", "a"}, InfoJoin))
+	assert.Equal(s.T(), "a", combineInfoValues([]interface{}{"a", "3"}, InfoSum), "non numeric values fall back to first")
+	assert.Equal(s.T(), "5", combineInfoValues([]interface{}{"5", "7"}, InfoFirst))
+	assert.Equal(s.T(), true, combineInfoValues([]interface{}{true, true}, InfoJoin))
+}
+
+func (s *MergeSuite) TestFilters() {
+	assert.Equal(s.T(), "PASS", mergeFilters([]string{"PASS", "PASS"}))
+	assert.Equal(s.T(), "q10;s50", mergeFilters([]string{"PASS", "q10", "s50", "q10"}))
+	assert.Equal(s.T(), ".", mergeFilters([]string{".", ""}))
+}
+
+func (s *MergeSuite) TestConflicts() {
+	conflicting := strings.Replace(familyB, "##INFO=<ID=DP,Number=1,Type=Integer", "##INFO=<ID=DP,Number=1,Type=Float", 1)
+	conflicting = strings.Replace(conflicting, "##contig=<ID=1,length=1000>", "##contig=<ID=1,length=2000>", 1)
+
+	var buffer bytes.Buffer
+	conflicts, err := Merge([]io.Reader{strings.NewReader(familyA), strings.NewReader(conflicting)}, &buffer, MergeOptions{})
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), conflicts, 2)
+	assert.Equal(s.T(), "contig", conflicts[0].Key)
+	assert.Equal(s.T(), "INFO", conflicts[1].Key)
+	assert.Equal(s.T(), "DP", conflicts[1].ID)
+	assert.Contains(s.T(), buffer.String(), "##INFO=<ID=DP,Number=1,Type=Integer", "first definition should be kept")
+
+	buffer.Reset()
+	_, err = Merge([]io.Reader{strings.NewReader(familyA), strings.NewReader(conflicting)}, &buffer, MergeOptions{FailOnConflict: true})
+	assert.Error(s.T(), err)
+}
+
+func (s *MergeSuite) TestDuplicateSamples() {
+	var buffer bytes.Buffer
+	_, err := Merge([]io.Reader{strings.NewReader(familyB), strings.NewReader(familyB)}, &buffer, MergeOptions{})
+	assert.Error(s.T(), err)
+}
+
+func TestMergeSuite(t *testing.T) {
+	suite.Run(t, new(MergeSuite))
+}
@@ -0,0 +1,139 @@
+package vcf
+
+// ReaderOption configures optional behavior of ToChannel.
+type ReaderOption func(*readerOptions)
+
+type readerOptions struct {
+	dropNonRefAllele bool
+	headerHandler    func(*Header)
+	checkOrder       bool
+	warningHandler   func(Warning)
+	strictness       Strictness
+	includeRegions   *IntervalSet
+	excludeRegions   *IntervalSet
+	samplesSelected  bool
+	selectedSamples  []string
+	excludedSamples  []string
+	recomputeCounts  bool
+}
+
+func newReaderOptions(options []ReaderOption) *readerOptions {
+	config := &readerOptions{strictness: Standard}
+	for _, option := range options {
+		option(config)
+	}
+	return config
+}
+
+// DropNonRefAllele makes ToChannel skip the <NON_REF> and <*> alternatives of gVCF variant sites, so only
+// the called alleles are reported. Reference blocks, whose only alternative is <NON_REF>, are still reported.
+func DropNonRefAllele() ReaderOption {
+	return func(config *readerOptions) {
+		config.dropNonRefAllele = true
+	}
+}
+
+// WithHeaderHandler makes ToChannel call handler with the parsed header, before any variant is sent.
+func WithHeaderHandler(handler func(*Header)) ReaderOption {
+	return func(config *readerOptions) {
+		config.headerHandler = handler
+	}
+}
+
+// CheckOrder makes ToChannel verify that the records are sorted: positions must not decrease within a
+// chromosome, the records of a chromosome must be contiguous, and chromosomes declared on ##contig lines
+// must follow the declared order. Exact duplicate records are rejected as well. Records breaking any of these
+// rules are reported as InvalidLine, with an *OrderError, instead of being sent to the output.
+func CheckOrder() ReaderOption {
+	return func(config *readerOptions) {
+		config.checkOrder = true
+	}
+}
+
+// WithWarningHandler makes ToChannel call handler for the problems that do not prevent a line from being
+// parsed, such as a QUAL or an INFO value that cannot be parsed and is ignored, an unknown SVTYPE, or a sample
+// with more values than FORMAT keys. Without a handler these problems are silently ignored.
+func WithWarningHandler(handler func(Warning)) ReaderOption {
+	return func(config *readerOptions) {
+		config.warningHandler = handler
+	}
+}
+
+// IncludeRegions makes ToChannel send only the variants that overlap the regions, such as the targets of a
+// capture kit read with ReadBED. Other variants are skipped, without being reported as InvalidLine.
+func IncludeRegions(regions *IntervalSet) ReaderOption {
+	return func(config *readerOptions) {
+		config.includeRegions = regions
+	}
+}
+
+// ExcludeRegions makes ToChannel skip the variants that overlap the regions, such as low complexity or
+// blacklisted regions. It can be combined with IncludeRegions.
+func ExcludeRegions(regions *IntervalSet) ReaderOption {
+	return func(config *readerOptions) {
+		config.excludeRegions = regions
+	}
+}
+
+// SelectSamples makes ToChannel report only the given samples, in the given order. The columns of the other
+// samples are never parsed, and the header passed to WithHeaderHandler lists the selected samples only, so that
+// Header.SampleIDs matches the Samples of each Variant. ToChannel fails when a sample is not on the header.
+func SelectSamples(ids ...string) ReaderOption {
+	return func(config *readerOptions) {
+		config.samplesSelected = true
+		config.selectedSamples = ids
+	}
+}
+
+// ExcludeSamples makes ToChannel skip the given samples, as SelectSamples does with the samples not selected.
+// Samples that are not on the header are ignored. It can be combined with SelectSamples.
+func ExcludeSamples(ids ...string) ReaderOption {
+	return func(config *readerOptions) {
+		config.excludedSamples = ids
+	}
+}
+
+// RecomputeAlleleCounts makes ToChannel set the AC, AN and AF INFO values of each variant from the genotypes
+// of the samples reported, so that they describe the samples kept by SelectSamples or ExcludeSamples. Records
+// without GT keep their values, and the INFO fields are declared on the header when missing.
+func RecomputeAlleleCounts() ReaderOption {
+	return func(config *readerOptions) {
+		config.recomputeCounts = true
+	}
+}
+
+// inRegions tells whether a variant passes the regions of IncludeRegions and ExcludeRegions.
+func (config *readerOptions) inRegions(variant *Variant) bool {
+	if config.includeRegions != nil && !config.includeRegions.OverlapsVariant(variant) {
+		return false
+	}
+	return config.excludeRegions == nil || !config.excludeRegions.OverlapsVariant(variant)
+}
+
+// Strictness tells how ToChannel handles lines that deviate from the spec.
+type Strictness int
+
+const (
+	// Lenient parses whatever can be parsed: records missing the QUAL, FILTER or INFO columns are accepted,
+	// as are malformed breakend alternatives, which are kept as raw ALTs. Only records without a valid CHROM,
+	// POS, REF or ALT are reported as InvalidLine. Every deviation is reported as a warning.
+	Lenient Strictness = iota
+	// Standard, the default, reports records without the fixed columns, or with values that cannot be
+	// parsed on them, as InvalidLine. Problems on optional values, such as INFO values that cannot be parsed,
+	// are reported as warnings and the values ignored.
+	Standard
+	// Strict reports any deviation from the spec as InvalidLine: the problems reported as warnings on the
+	// other levels, a header without the ##fileformat line or with wrong fixed columns, records whose number of
+	// samples differs from the #CHROM line, reported as WrongColumnCount, and records failing the checks of
+	// Validate, such as a REF with other bases than A, C, G, T and N, INFO, FORMAT or FILTER values not declared
+	// on the header, values not matching their declared Type and Number, or genotypes referring to missing
+	// alleles.
+	Strict
+)
+
+// WithStrictness sets the strictness level of ToChannel, Standard by default.
+func WithStrictness(level Strictness) ReaderOption {
+	return func(config *readerOptions) {
+		config.strictness = level
+	}
+}
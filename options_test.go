@@ -0,0 +1,167 @@
+package vcf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+const strictnessLines = `##fileformat=VCFv4.2
+##INFO=<ID=DP,Number=1,Type=Integer,Description="Depth">
+##FORMAT=<ID=GT,Number=1,Type=String,Description="Genotype">
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	S1
+1	100	.	A	G	50	PASS	DP=10	GT	0/1
+1	200	.	A	G
+1	300	.	G	G[1:x[	50	PASS	.	GT	0/1
+1	400	.	A	G	high	PASS	.	GT	0/1
+1	500	.	A	G	50	PASS	.	GT	0/1:extra
+`
+
+type StrictnessSuite struct {
+	suite.Suite
+}
+
+func (s *StrictnessSuite) read(vcfLines string, level Strictness) ([]*Variant, []InvalidLine, []Warning) {
+	output := make(chan *Variant, 10)
+	invalidChannel := make(chan InvalidLine, 10)
+	warnings := make([]Warning, 0)
+	err := ToChannel(strings.NewReader(vcfLines), output, invalidChannel, WithStrictness(level), WithWarningHandler(func(warning Warning) {
+		warnings = append(warnings, warning)
+	}))
+	assert.NoError(s.T(), err)
+
+	variants := make([]*Variant, 0)
+	for variant := range output {
+		variants = append(variants, variant)
+	}
+	invalids := make([]InvalidLine, 0)
+	for invalid := range invalidChannel {
+		invalids = append(invalids, invalid)
+	}
+	return variants, invalids, warnings
+}
+
+func (s *StrictnessSuite) kinds(invalids []InvalidLine) []ParseErrorKind {
+	kinds := make([]ParseErrorKind, len(invalids))
+	for i, invalid := range invalids {
+		kinds[i] = invalid.Err.(*ParseError).Kind
+	}
+	return kinds
+}
+
+func (s *StrictnessSuite) TestLenient() {
+	variants, invalids, warnings := s.read(strictnessLines, Lenient)
+
+	assert.Len(s.T(), variants, 5)
+	assert.Empty(s.T(), invalids)
+	assert.Len(s.T(), warnings, 5)
+
+	assert.Equal(s.T(), 199, variants[1].Pos)
+	assert.Equal(s.T(), ".", variants[1].Filter)
+	assert.Nil(s.T(), variants[2].Breakend)
+	assert.Equal(s.T(), "G[1:x[", variants[2].Alt, "malformed breakends should be kept raw")
+	assert.Equal(s.T(), Warning{LineNumber: 6, Field: "columns", Message: "found 5 columns, filling the missing ones with '.'"}, warnings[0])
+}
+
+func (s *StrictnessSuite) TestStandard() {
+	variants, invalids, warnings := s.read(strictnessLines, Standard)
+
+	assert.Len(s.T(), variants, 2)
+	assert.Equal(s.T(), []ParseErrorKind{WrongColumnCount, InvalidBreakend, ExtraSampleFields}, s.kinds(invalids))
+	assert.Len(s.T(), warnings, 1, "QUAL")
+}
+
+func (s *StrictnessSuite) TestStrict() {
+	variants, invalids, warnings := s.read(strictnessLines, Strict)
+
+	assert.Len(s.T(), variants, 1)
+	assert.Empty(s.T(), warnings, "warnings become errors")
+	assert.Equal(s.T(), []ParseErrorKind{WrongColumnCount, InvalidBreakend, SpecViolation, ExtraSampleFields}, s.kinds(invalids))
+	assert.Equal(s.T(), 6, invalids[2].Column)
+	assert.EqualError(s.T(), invalids[2].Err, "QUAL: unable to parse quality as float, setting as nil")
+	assert.Equal(s.T(), 10, invalids[3].Column)
+	assert.Equal(s.T(), 9, invalids[3].LineNumber)
+}
+
+func (s *StrictnessSuite) TestStrictMissingValues() {
+	vcfLines := "##fileformat=VCFv4.2\n" +
+		"##INFO=<ID=DP,Number=1,Type=Integer,Description=\"Depth\">\n" +
+		"##INFO=<ID=AF,Number=A,Type=Float,Description=\"Allele frequency\">\n" +
+		"##INFO=<ID=CIPOS,Number=2,Type=Integer,Description=\"Confidence interval around POS\">\n" +
+		"##INFO=<ID=END,Number=1,Type=Integer,Description=\"End position\">\n" +
+		"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n" +
+		"1\t100\t.\tA\t<DEL>\t.\tPASS\tDP=.;AF=.;CIPOS=.;END=200\n"
+
+	variants, invalids, _ := s.read(vcfLines, Strict)
+	assert.Empty(s.T(), invalids, "'.' is a missing value, not a malformed one")
+	assert.Len(s.T(), variants, 1)
+	assert.Nil(s.T(), variants[0].Depth)
+	assert.Nil(s.T(), variants[0].AlleleFrequency)
+	assert.Nil(s.T(), variants[0].ConfidenceIntervalAroundPosition)
+	assert.Equal(s.T(), ".", variants[0].Info["DP"])
+}
+
+func (s *StrictnessSuite) TestStrictRecordChecks() {
+	vcfLines := "##fileformat=VCFv4.2\n" +
+		"##INFO=<ID=DP,Number=1,Type=Integer,Description=\"Depth\">\n" +
+		"##FILTER=<ID=LowQual,Description=\"Low quality\">\n" +
+		"##FORMAT=<ID=GT,Number=1,Type=String,Description=\"Genotype\">\n" +
+		"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tS1\tS2\n" +
+		"1\t100\t.\tA\tG\t50\tLowQual\tDP=10\tGT\t0/1\t0/0\n" +
+		"1\t200\t.\tXYZ\tG\t50\tPASS\tDP=10\tGT\t0/1\t0/0\n" +
+		"1\t300\t.\tA\tG\t50\tPASS\tFOO=1\tGT\t0/1\t0/0\n" +
+		"1\t400\t.\tA\tG\t50\tNOTDECL\tDP=10\tGT\t0/1\t0/0\n" +
+		"1\t500\t.\tA\tG\t50\tPASS\tDP=10\tGT\t0/1\t0/5\n" +
+		"1\t600\t.\tA\tG\t50\tPASS\tDP=10\tGT:AB\t0/1:1\t0/0:1\n"
+
+	variants, invalids, _ := s.read(vcfLines, Strict)
+	assert.Len(s.T(), variants, 1)
+	assert.Equal(s.T(), 99, variants[0].Pos)
+	assert.Equal(s.T(), []ParseErrorKind{SpecViolation, SpecViolation, SpecViolation, SpecViolation, SpecViolation}, s.kinds(invalids))
+	for i, expected := range []struct {
+		lineNumber, column int
+		message            string
+	}{
+		{7, 4, `ref-bases: REF must be made of the bases A, C, G, T and N, found "XYZ"`},
+		{8, 8, "undefined-info: INFO FOO is not declared on the header"},
+		{9, 7, "undefined-filter: FILTER NOTDECL is not declared on the header"},
+		{10, 0, "genotype-index: sample S2: allele 5 of 0/5 exceeds the 1 alternatives"},
+		{11, 9, "undefined-format: FORMAT AB is not declared on the header"},
+	} {
+		assert.Equal(s.T(), expected.lineNumber, invalids[i].LineNumber)
+		assert.Equal(s.T(), expected.column, invalids[i].Column)
+		assert.EqualError(s.T(), invalids[i].Err, expected.message)
+	}
+
+	variants, invalids, _ = s.read(vcfLines, Standard)
+	assert.Len(s.T(), variants, 6, "the checks of Validate only apply to the Strict level")
+	assert.Empty(s.T(), invalids)
+
+	// the samples left out by SelectSamples are still checked against the header of the file
+	output := make(chan *Variant, 10)
+	invalidChannel := make(chan InvalidLine, 10)
+	err := ToChannel(strings.NewReader(vcfLines), output, invalidChannel, WithStrictness(Strict), SelectSamples("S1"))
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), output, 1)
+	assert.Len(s.T(), invalidChannel, 5)
+}
+
+func (s *StrictnessSuite) TestStrictHeader() {
+	vcfLines := "##source=lab\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tS1\n1\t100\t.\tA\tG\t50\tPASS\t.\n"
+
+	variants, invalids, _ := s.read(vcfLines, Strict)
+	assert.Len(s.T(), variants, 1)
+	assert.Equal(s.T(), []ParseErrorKind{InvalidHeader, InvalidHeader}, s.kinds(invalids))
+	assert.Equal(s.T(), 1, invalids[0].LineNumber)
+	assert.Equal(s.T(), 2, invalids[1].LineNumber)
+	assert.Equal(s.T(), int64(13), invalids[1].Offset)
+
+	_, invalids, _ = s.read(vcfLines, Standard)
+	assert.Empty(s.T(), invalids, "header deviations are only reported by the Strict level")
+}
+
+func TestStrictnessSuite(t *testing.T) {
+	suite.Run(t, new(StrictnessSuite))
+}
@@ -0,0 +1,170 @@
+package vcf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// contigOrder ranks chromosomes by the order of the ##contig lines of one or more headers. Chromosomes that
+// are not declared come after the declared ones, in natural chromosome order.
+type contigOrder struct {
+	ranks map[string]int
+}
+
+func newContigOrder(headers ...*Header) *contigOrder {
+	order := &contigOrder{ranks: make(map[string]int)}
+	for _, header := range headers {
+		for _, contig := range header.Contigs {
+			chrom := normalizeChrom(contig.ID)
+			if _, found := order.ranks[chrom]; !found {
+				order.ranks[chrom] = len(order.ranks)
+			}
+		}
+	}
+	return order
+}
+
+// compare returns a negative number when chromA comes before chromB, a positive one when it comes after,
+// and zero when they are the same chromosome. Names are compared after stripping the "chr" prefix.
+func (o *contigOrder) compare(chromA, chromB string) int {
+	chromA, chromB = normalizeChrom(chromA), normalizeChrom(chromB)
+	if chromA == chromB {
+		return 0
+	}
+	rankA, declaredA := o.ranks[chromA]
+	rankB, declaredB := o.ranks[chromB]
+	switch {
+	case declaredA && declaredB:
+		return rankA - rankB
+	case declaredA:
+		return -1
+	case declaredB:
+		return 1
+	}
+	return compareChromosomeNames(chromA, chromB)
+}
+
+// compareLoci orders two positions by chromosome and then by position.
+func (o *contigOrder) compareLoci(chromA string, posA int, chromB string, posB int) int {
+	if c := o.compare(chromA, chromB); c != 0 {
+		return c
+	}
+	return posA - posB
+}
+
+// compareChromosomeNames implements the natural chromosome order: numbered chromosomes first, in numeric
+// order, followed by X, Y and the mitochondrial chromosome, and then any other name in lexicographic order.
+func compareChromosomeNames(chromA, chromB string) int {
+	rankA, rankB := chromosomeRank(chromA), chromosomeRank(chromB)
+	if rankA != rankB {
+		return rankA - rankB
+	}
+	return strings.Compare(chromA, chromB)
+}
+
+func chromosomeRank(chrom string) int {
+	chrom = normalizeChrom(chrom)
+	if number, err := strconv.Atoi(chrom); err == nil && number > 0 {
+		return number
+	}
+	switch strings.ToUpper(chrom) {
+	case "X":
+		return 1000
+	case "Y":
+		return 1001
+	case "M", "MT":
+		return 1002
+	}
+	return 1003
+}
+
+// OrderViolation tells which ordering rule an OrderError breaks.
+type OrderViolation int
+
+const (
+	// PositionDecreased is a record before the previous one on the same chromosome.
+	PositionDecreased OrderViolation = iota
+	// ContigOutOfOrder is a chromosome declared on the ##contig lines before the previous chromosome.
+	ContigOutOfOrder
+	// ContigRepeated is a chromosome found again after records of other chromosomes.
+	ContigRepeated
+	// DuplicateRecord is a record identical to a previous one.
+	DuplicateRecord
+)
+
+// OrderError is reported for the records that break the order checked by the CheckOrder option.
+// Positions are 1-based, as written on the file.
+type OrderError struct {
+	LineNumber    int
+	Violation     OrderViolation
+	Chrom         string
+	Pos           int
+	PreviousChrom string
+	PreviousPos   int
+}
+
+func (e *OrderError) Error() string {
+	var reason string
+	switch e.Violation {
+	case PositionDecreased:
+		reason = fmt.Sprintf("position %d comes after %d", e.Pos, e.PreviousPos)
+	case ContigOutOfOrder:
+		reason = fmt.Sprintf("chromosome %s is declared before %s", e.Chrom, e.PreviousChrom)
+	case ContigRepeated:
+		reason = fmt.Sprintf("chromosome %s found again after %s", e.Chrom, e.PreviousChrom)
+	case DuplicateRecord:
+		reason = fmt.Sprintf("duplicate record at %s:%d", e.Chrom, e.Pos)
+	}
+	return fmt.Sprintf("line %d: %s", e.LineNumber, reason)
+}
+
+// orderChecker implements the CheckOrder option, remembering the chromosomes already read and the records
+// at the last position.
+type orderChecker struct {
+	order   *contigOrder
+	seen    map[string]bool
+	chrom   string
+	pos     int
+	records map[string]bool
+}
+
+func newOrderChecker(header *Header) *orderChecker {
+	return &orderChecker{order: newContigOrder(header), seen: make(map[string]bool)}
+}
+
+// check validates a record against the previous ones, given its normalized chromosome and 1-based position.
+// Records that fail are not taken into account for the following checks.
+func (c *orderChecker) check(line, chrom string, pos, lineNumber int) error {
+	violation := OrderViolation(-1)
+	_, declared := c.order.ranks[chrom]
+	_, previousDeclared := c.order.ranks[c.chrom]
+	switch {
+	case chrom != c.chrom && c.seen[chrom]:
+		violation = ContigRepeated
+	case chrom != c.chrom && declared && previousDeclared && c.order.compare(chrom, c.chrom) < 0:
+		violation = ContigOutOfOrder
+	case chrom == c.chrom && pos < c.pos:
+		violation = PositionDecreased
+	case chrom == c.chrom && pos == c.pos && c.records[strings.TrimSpace(line)]:
+		violation = DuplicateRecord
+	}
+	if violation >= 0 {
+		return &OrderError{
+			LineNumber:    lineNumber,
+			Violation:     violation,
+			Chrom:         chrom,
+			Pos:           pos,
+			PreviousChrom: c.chrom,
+			PreviousPos:   c.pos,
+		}
+	}
+
+	if chrom != c.chrom || pos != c.pos {
+		c.seen[chrom] = true
+		c.chrom, c.pos = chrom, pos
+		c.records = make(map[string]bool)
+	}
+	c.records[strings.TrimSpace(line)] = true
+	return nil
+}
@@ -0,0 +1,106 @@
+package vcf
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ContigOrderSuite struct {
+	suite.Suite
+}
+
+func (s *ContigOrderSuite) TestNaturalOrder() {
+	chroms := []string{"MT", "chr10", "Y", "GL000192.1", "2", "X", "1", "chr1_random"}
+	order := newContigOrder()
+	sort.Slice(chroms, func(i, j int) bool { return order.compare(chroms[i], chroms[j]) < 0 })
+
+	assert.Equal(s.T(), []string{"1", "2", "10", "X", "Y", "MT", "1_random", "GL000192.1"}, normalizeAll(chroms))
+}
+
+func (s *ContigOrderSuite) TestHeaderOrderTakesPrecedence() {
+	header := newHeader()
+	header.AddMetaLine("##contig=<ID=chrX>")
+	header.AddMetaLine("##contig=<ID=chr2>")
+
+	order := newContigOrder(header)
+	assert.True(s.T(), order.compare("X", "2") < 0)
+	assert.True(s.T(), order.compare("chr2", "1") < 0, "declared contigs come before undeclared ones")
+	assert.Equal(s.T(), 0, order.compare("chrX", "X"))
+	assert.True(s.T(), order.compareLoci("X", 500, "X", 10) > 0)
+}
+
+func (s *ContigOrderSuite) TestCheckOrder() {
+	vcfLines := `##fileformat=VCFv4.2
+##contig=<ID=chr1>
+##contig=<ID=chr2>
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO
+chr1	100	.	A	G	.	.	.
+chr1	100	.	A	C	.	.	.
+chr1	100	.	A	G	.	.	.
+chr1	50	.	A	G	.	.	.
+chr2	10	.	A	G	.	.	.
+chr1	200	.	A	G	.	.	.
+chrX	5	.	A	G	.	.	.
+chr2	20	.	A	G	.	.	.
+chr3	5	.	A	G	.	.	.
+`
+	output := make(chan *Variant, 10)
+	invalids := make(chan InvalidLine, 10)
+	err := ToChannel(strings.NewReader(vcfLines), output, invalids, CheckOrder())
+	assert.NoError(s.T(), err)
+
+	loci := make([]string, 0)
+	for variant := range output {
+		loci = append(loci, variant.Chrom+":"+variant.Alt)
+	}
+	assert.Equal(s.T(), []string{"1:G", "1:C", "2:G", "X:G", "3:G"}, loci)
+
+	errs := make([]*OrderError, 0)
+	for invalid := range invalids {
+		orderErr, ok := invalid.Err.(*OrderError)
+		assert.True(s.T(), ok)
+		errs = append(errs, orderErr)
+	}
+	assert.Len(s.T(), errs, 4)
+	assert.Equal(s.T(), &OrderError{LineNumber: 7, Violation: DuplicateRecord, Chrom: "1", Pos: 100, PreviousChrom: "1", PreviousPos: 100}, errs[0])
+	assert.Equal(s.T(), PositionDecreased, errs[1].Violation)
+	assert.Equal(s.T(), "line 8: position 50 comes after 100", errs[1].Error())
+	assert.Equal(s.T(), "line 10: chromosome 1 found again after 2", errs[2].Error())
+	assert.Equal(s.T(), "line 12: chromosome 2 found again after X", errs[3].Error())
+}
+
+func (s *ContigOrderSuite) TestCheckContigOrder() {
+	vcfLines := "##contig=<ID=chr1>\n##contig=<ID=chr2>\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n" +
+		"2\t100\t.\tA\tG\t.\t.\t.\n1\t50\t.\tA\tG\t.\t.\t.\n"
+	output := make(chan *Variant, 10)
+	invalids := make(chan InvalidLine, 10)
+	assert.NoError(s.T(), ToChannel(strings.NewReader(vcfLines), output, invalids, CheckOrder()))
+	assert.Len(s.T(), output, 1)
+	invalid := <-invalids
+	assert.EqualError(s.T(), invalid.Err, "line 5: chromosome 1 is declared before 2")
+}
+
+func (s *ContigOrderSuite) TestOrderIsNotCheckedByDefault() {
+	vcfLines := "#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n1\t100\t.\tA\tG\t.\t.\t.\n1\t50\t.\tA\tG\t.\t.\t.\n"
+	output := make(chan *Variant, 10)
+	invalids := make(chan InvalidLine, 10)
+	assert.NoError(s.T(), ToChannel(strings.NewReader(vcfLines), output, invalids))
+	assert.Len(s.T(), output, 2)
+	assert.Len(s.T(), invalids, 0)
+}
+
+func normalizeAll(chroms []string) []string {
+	normalized := make([]string, len(chroms))
+	for i, chrom := range chroms {
+		normalized[i] = normalizeChrom(chrom)
+	}
+	return normalized
+}
+
+func TestContigOrderSuite(t *testing.T) {
+	suite.Run(t, new(ContigOrderSuite))
+}
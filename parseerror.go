@@ -0,0 +1,38 @@
+package vcf
+
+// ParseErrorKind classifies the reasons a VCF line cannot be parsed.
+//
+//go:generate stringer -type=ParseErrorKind
+type ParseErrorKind int
+
+const (
+	// WrongColumnCount is a line without the eight fixed columns.
+	WrongColumnCount ParseErrorKind = iota
+	// InvalidPosition is a POS that is not an integer.
+	InvalidPosition
+	// InvalidBreakend is an ALT that uses the breakend notation wrongly.
+	InvalidBreakend
+	// InvalidHeader is a header that does not follow the spec, reported with the Strict level.
+	InvalidHeader
+	// SpecViolation is a problem reported as a warning by the other strictness levels, turned into an error
+	// by the Strict level.
+	SpecViolation
+	// ExtraSampleFields is a sample column with more values than the FORMAT keys.
+	ExtraSampleFields
+)
+
+// ParseError is the error reported on InvalidLine for lines that cannot be parsed.
+type ParseError struct {
+	Kind ParseErrorKind
+
+	// Column is the 1-based column that could not be parsed, such as 2 for POS, or zero when the error
+	// concerns the whole line. Value holds the content of that column.
+	Column int
+	Value  string
+
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	return e.Message
+}
@@ -0,0 +1,204 @@
+package vcf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Sex is the sex of an individual, as coded on PED files.
+type Sex int
+
+const (
+	// UnknownSex is an individual whose sex is not given.
+	UnknownSex Sex = iota
+	// Male is coded as 1 on PED files.
+	Male
+	// Female is coded as 2 on PED files.
+	Female
+)
+
+// Phenotype is the affection status of an individual, as coded on PED files.
+type Phenotype int
+
+const (
+	// UnknownPhenotype is an individual whose affection status is not given.
+	UnknownPhenotype Phenotype = iota
+	// Unaffected is coded as 1 on PED files.
+	Unaffected
+	// Affected is coded as 2 on PED files.
+	Affected
+)
+
+// Individual is a member of a Pedigree. Father and Mother are empty when unknown, and ID is the sample ID used on
+// the VCF.
+type Individual struct {
+	Family    string
+	ID        string
+	Father    string
+	Mother    string
+	Sex       Sex
+	Phenotype Phenotype
+}
+
+// Trio is a child along with both of its parents.
+type Trio struct {
+	Child  *Individual
+	Father *Individual
+	Mother *Individual
+}
+
+// Pedigree holds the relationships between individuals, read from a PED file with ReadPED or from the
+// ##PEDIGREE lines of a header with Header.Pedigree.
+type Pedigree struct {
+	individuals map[string]*Individual
+	order       []*Individual
+}
+
+func newPedigree() *Pedigree {
+	return &Pedigree{individuals: make(map[string]*Individual)}
+}
+
+// individual returns the individual with the given ID, adding it when the pedigree does not have it yet.
+func (p *Pedigree) individual(id string) *Individual {
+	individual, found := p.individuals[id]
+	if !found {
+		individual = &Individual{ID: id}
+		p.individuals[id] = individual
+		p.order = append(p.order, individual)
+	}
+	return individual
+}
+
+// ReadPED reads a PED file, whose first six columns are the family, individual, father and mother IDs, the sex
+// and the phenotype. Parents coded as 0 are unknown, and comments and columns after the sixth are ignored.
+// Parents that are not listed as individuals are added with the sex implied by their role.
+func ReadPED(reader io.Reader) (*Pedigree, error) {
+	pedigree := newPedigree()
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 100*1024), 10*1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 6 {
+			return nil, fmt.Errorf("line %d: expected at least 6 columns, found %d", lineNumber, len(fields))
+		}
+		if _, found := pedigree.individuals[fields[1]]; found {
+			return nil, fmt.Errorf("line %d: individual %s is listed more than once", lineNumber, fields[1])
+		}
+		individual := pedigree.individual(fields[1])
+		individual.Family = fields[0]
+		individual.Father = pedigreeParent(fields[2])
+		individual.Mother = pedigreeParent(fields[3])
+		switch fields[4] {
+		case "1":
+			individual.Sex = Male
+		case "2":
+			individual.Sex = Female
+		}
+		switch fields[5] {
+		case "1":
+			individual.Phenotype = Unaffected
+		case "2":
+			individual.Phenotype = Affected
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	pedigree.addParents()
+	return pedigree, nil
+}
+
+func pedigreeParent(id string) string {
+	if id == "0" || id == "." {
+		return ""
+	}
+	return id
+}
+
+// addParents adds the parents that are not listed as individuals, and sets the sex of the ones without one.
+func (p *Pedigree) addParents() {
+	for _, individual := range append([]*Individual{}, p.order...) {
+		for _, parent := range []struct {
+			id  string
+			sex Sex
+		}{{individual.Father, Male}, {individual.Mother, Female}} {
+			if parent.id == "" {
+				continue
+			}
+			added := p.individual(parent.id)
+			if added.Family == "" {
+				added.Family = individual.Family
+			}
+			if added.Sex == UnknownSex {
+				added.Sex = parent.sex
+			}
+		}
+	}
+}
+
+// Pedigree builds a Pedigree from the ##PEDIGREE lines of the header, written as <ID=Child,Father=...,Mother=...>
+// or, as in VCF 4.1, <Child=...,Father=...,Mother=...>. Lines relating derived genomes, such as tumours, to
+// their original ones are ignored. The sex of each sample is taken from the Sex field of its ##SAMPLE line when
+// present, and otherwise implied by its role as a parent.
+func (h *Header) Pedigree() *Pedigree {
+	pedigree := newPedigree()
+	for _, meta := range h.MetaLines {
+		if meta.Key != "PEDIGREE" || meta.Fields == nil {
+			continue
+		}
+		id := meta.Fields["ID"]
+		if id == "" {
+			id = meta.Fields["Child"]
+		}
+		if id == "" || (meta.Fields["Father"] == "" && meta.Fields["Mother"] == "") {
+			continue
+		}
+		child := pedigree.individual(id)
+		child.Father = pedigreeParent(meta.Fields["Father"])
+		child.Mother = pedigreeParent(meta.Fields["Mother"])
+	}
+	pedigree.addParents()
+	for _, individual := range pedigree.order {
+		if sample, found := h.Samples[individual.ID]; found {
+			switch strings.ToLower(sample.Fields["Sex"]) {
+			case "m", "male", "1":
+				individual.Sex = Male
+			case "f", "female", "2":
+				individual.Sex = Female
+			}
+		}
+	}
+	return pedigree
+}
+
+// Individual returns the individual with the given ID.
+func (p *Pedigree) Individual(id string) (*Individual, bool) {
+	individual, found := p.individuals[id]
+	return individual, found
+}
+
+// Individuals returns every individual of the pedigree, in the order they were first found.
+func (p *Pedigree) Individuals() []*Individual {
+	return append([]*Individual{}, p.order...)
+}
+
+// Trios returns a Trio for each individual whose father and mother are both known.
+func (p *Pedigree) Trios() []Trio {
+	var trios []Trio
+	for _, individual := range p.order {
+		if individual.Father != "" && individual.Mother != "" {
+			trios = append(trios, Trio{
+				Child:  individual,
+				Father: p.individuals[individual.Father],
+				Mother: p.individuals[individual.Mother],
+			})
+		}
+	}
+	return trios
+}
@@ -0,0 +1,81 @@
+package vcf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type PedigreeSuite struct {
+	suite.Suite
+}
+
+func (s *PedigreeSuite) TestReadPED() {
+	ped := "# family\tindividual\tfather\tmother\tsex\tphenotype\n" +
+		"FAM1 CHILD DAD MOM 2 2\n" +
+		"FAM1\tDAD\t0\t0\t1\t1\n" +
+		"FAM1 SIB DAD MOM2 0 -9 extra columns\n\n"
+
+	pedigree, err := ReadPED(strings.NewReader(ped))
+	assert.NoError(s.T(), err)
+
+	child, found := pedigree.Individual("CHILD")
+	assert.True(s.T(), found)
+	assert.Equal(s.T(), &Individual{Family: "FAM1", ID: "CHILD", Father: "DAD", Mother: "MOM", Sex: Female, Phenotype: Affected}, child)
+	sibling, _ := pedigree.Individual("SIB")
+	assert.Equal(s.T(), UnknownSex, sibling.Sex)
+	assert.Equal(s.T(), UnknownPhenotype, sibling.Phenotype)
+	mother, _ := pedigree.Individual("MOM")
+	assert.Equal(s.T(), &Individual{Family: "FAM1", ID: "MOM", Sex: Female}, mother)
+
+	var ids []string
+	for _, individual := range pedigree.Individuals() {
+		ids = append(ids, individual.ID)
+	}
+	assert.Equal(s.T(), []string{"CHILD", "DAD", "SIB", "MOM", "MOM2"}, ids)
+
+	trios := pedigree.Trios()
+	assert.Len(s.T(), trios, 2)
+	assert.Equal(s.T(), "CHILD", trios[0].Child.ID)
+	assert.Equal(s.T(), "DAD", trios[0].Father.ID)
+	assert.Equal(s.T(), "MOM", trios[0].Mother.ID)
+	assert.Equal(s.T(), "MOM2", trios[1].Mother.ID)
+}
+
+func (s *PedigreeSuite) TestInvalidPED() {
+	for ped, message := range map[string]string{
+		"FAM1 CHILD DAD MOM 2\n":                 "line 1: expected at least 6 columns, found 5",
+		"FAM1 DAD 0 0 1 1\n\nFAM1 DAD 0 0 1 1\n": "line 3: individual DAD is listed more than once",
+	} {
+		_, err := ReadPED(strings.NewReader(ped))
+		assert.EqualError(s.T(), err, message)
+	}
+}
+
+func (s *PedigreeSuite) TestHeaderPedigree() {
+	header, err := ReadHeader(strings.NewReader(`##fileformat=VCFv4.3
+##PEDIGREE=<ID=CHILD,Father=DAD,Mother=MOM>
+##PEDIGREE=<Child=CHILD2,Mother=MOM,Father=DAD>
+##PEDIGREE=<ID=TUMOR,Original=CHILD>
+##SAMPLE=<ID=CHILD,Sex=M>
+##SAMPLE=<ID=CHILD2,Sex=female>
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	CHILD	DAD	MOM	CHILD2
+`))
+	assert.NoError(s.T(), err)
+
+	pedigree := header.Pedigree()
+	trios := pedigree.Trios()
+	assert.Len(s.T(), trios, 2)
+	assert.Equal(s.T(), Male, trios[0].Child.Sex)
+	assert.Equal(s.T(), Female, trios[1].Child.Sex)
+	assert.Equal(s.T(), Male, trios[1].Father.Sex)
+	assert.Equal(s.T(), Female, trios[1].Mother.Sex)
+	_, found := pedigree.Individual("TUMOR")
+	assert.False(s.T(), found)
+}
+
+func TestPedigreeSuite(t *testing.T) {
+	suite.Run(t, new(PedigreeSuite))
+}
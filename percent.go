@@ -0,0 +1,91 @@
+package vcf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// percentEncoded lists the characters that VCF 4.3 and later require to be percent-encoded inside INFO and
+// FORMAT values, since they have a special meaning on the line.
+var percentEncoded = map[byte]bool{
+	':':  true,
+	';':  true,
+	'=':  true,
+	'%':  true,
+	',':  true,
+	'\r': true,
+	'\n': true,
+	'\t': true,
+}
+
+// PercentEncode encodes the special characters of a single INFO or FORMAT value, such as ';' as %3B, following
+// VCF 4.3. Since commas are encoded too, lists must be encoded value by value.
+func PercentEncode(value string) string {
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		if percentEncoded[value[i]] {
+			fmt.Fprintf(&builder, "%%%02X", value[i])
+		} else {
+			builder.WriteByte(value[i])
+		}
+	}
+	return builder.String()
+}
+
+// PercentDecode decodes the percent-encoded characters of an INFO or FORMAT value, such as %3A back to ':'.
+// Percent signs that are not followed by two hex digits are kept as they are.
+func PercentDecode(value string) string {
+	if !strings.Contains(value, "%") {
+		return value
+	}
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '%' && i+2 < len(value) {
+			if decoded, err := strconv.ParseUint(value[i+1:i+3], 16, 8); err == nil {
+				builder.WriteByte(byte(decoded))
+				i += 2
+				continue
+			}
+		}
+		builder.WriteByte(value[i])
+	}
+	return builder.String()
+}
+
+// percentEncodeValue encodes an INFO or FORMAT value declared with the given Number. Lists are encoded entry by
+// entry, keeping the commas that separate them, while single values, and undeclared ones, are encoded whole.
+func percentEncodeValue(value, number string) string {
+	switch number {
+	case "", "0", "1":
+		return PercentEncode(value)
+	}
+	return percentEncodeList(value)
+}
+
+// percentEncodeList encodes each value of a comma-separated list, keeping the commas that separate them.
+func percentEncodeList(values string) string {
+	parts := strings.Split(values, ",")
+	for i, part := range parts {
+		parts[i] = PercentEncode(part)
+	}
+	return strings.Join(parts, ",")
+}
+
+// decodeInfoValues decodes the string values of an INFO map in place.
+func decodeInfoValues(info map[string]interface{}) {
+	for key, value := range info {
+		if str, ok := value.(string); ok {
+			info[key] = PercentDecode(str)
+		}
+	}
+}
+
+// decodeSampleValues decodes the values of the sample maps in place.
+func decodeSampleValues(samples []map[string]string) {
+	for _, sample := range samples {
+		for key, value := range sample {
+			sample[key] = PercentDecode(value)
+		}
+	}
+}
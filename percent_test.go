@@ -0,0 +1,97 @@
+package vcf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type PercentEncodingSuite struct {
+	suite.Suite
+}
+
+func (s *PercentEncodingSuite) TestEncodeAndDecode() {
+	assert.Equal(s.T(), "a%3Ab%3Bc%3Dd%25e%2Cf%09", PercentEncode("a:b;c=d%e,f\t"))
+	assert.Equal(s.T(), "a:b;c=d%e,f\t", PercentDecode("a%3Ab%3Bc%3Dd%25e%2Cf%09"))
+	assert.Equal(s.T(), "100%", PercentDecode("100%"), "incomplete sequences should be kept")
+	assert.Equal(s.T(), "%zz", PercentDecode("%zz"))
+}
+
+func (s *PercentEncodingSuite) TestDecodedFromVersion43() {
+	line := "1\t100\t.\tA\tG,T\t.\tPASS\tNOTE=a%3Bb,c%2Cd\tGT:XS\t0/1:x%3Ay"
+
+	header := newHeader()
+	header.AddMetaLine("##fileformat=VCFv4.3")
+	result, err := parseVcfLine(line, header, Standard, nil)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "a;b", result[0].Info["NOTE"])
+	assert.Equal(s.T(), "c,d", result[1].Info["NOTE"], "encoded commas should not split alternatives")
+	assert.Equal(s.T(), "x:y", result[0].Samples[0]["XS"])
+
+	header = newHeader()
+	header.AddMetaLine("##fileformat=VCFv4.2")
+	result, err = parseVcfLine(line, header, Standard, nil)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "a%3Bb", result[0].Info["NOTE"], "older versions are kept raw")
+}
+
+func (s *PercentEncodingSuite) TestWriterEncodesFromVersion43() {
+	header := newHeader()
+	header.AddMetaLine("##fileformat=VCFv4.3")
+	header.AddMetaLine(`##INFO=<ID=NOTE,Number=.,Type=String,Description="Notes">`)
+	header.Columns = append(append([]string{}, fixedColumns...), "FORMAT", "S1")
+	variant := &Variant{
+		Chrom:   "1",
+		Pos:     99,
+		Ref:     "A",
+		Alt:     "G",
+		Info:    map[string]interface{}{"NOTE": "a;b,c=d", "OTHER": "e,f"},
+		Format:  []string{"GT", "XS"},
+		Samples: []map[string]string{{"GT": "0|1", "XS": "x:y"}},
+	}
+
+	var buffer bytes.Buffer
+	writer, err := NewWriter(&buffer, header)
+	assert.NoError(s.T(), err)
+	assert.NoError(s.T(), writer.Write(variant))
+	assert.NoError(s.T(), writer.Flush())
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Equal(s.T(), "1\t100\t.\tA\tG\t.\t.\tNOTE=a%3Bb,c%3Dd;OTHER=e%2Cf\tGT:XS\t0|1:x%3Ay", lines[len(lines)-1],
+		"lists are encoded entry by entry, and undeclared values whole")
+}
+
+func (s *PercentEncodingSuite) TestRoundTrip() {
+	vcfLines := "##fileformat=VCFv4.3\n" +
+		"##INFO=<ID=D,Number=1,Type=String,Description=\"Description\">\n" +
+		"##INFO=<ID=NOTES,Number=.,Type=String,Description=\"Notes\">\n" +
+		"##FORMAT=<ID=GT,Number=1,Type=String,Description=\"Genotype\">\n" +
+		"##FORMAT=<ID=XS,Number=1,Type=String,Description=\"Sample description\">\n" +
+		"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tS1\n" +
+		"1\t100\t.\tA\tG\t.\tPASS\tD=a%2Cb%3Bc;NOTES=x%3Dy,z\tGT:XS\t0/1:u%2Cv\n"
+
+	output := make(chan *Variant, 10)
+	invalids := make(chan InvalidLine, 10)
+	var header *Header
+	err := ToChannel(strings.NewReader(vcfLines), output, invalids, WithHeaderHandler(func(h *Header) { header = h }))
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), invalids)
+	variant := <-output
+	assert.Equal(s.T(), "a,b;c", variant.Info["D"])
+	assert.Equal(s.T(), "x=y,z", variant.Info["NOTES"])
+	assert.Equal(s.T(), "u,v", variant.Samples[0]["XS"])
+
+	var buffer bytes.Buffer
+	writer, err := NewWriter(&buffer, header)
+	assert.NoError(s.T(), err)
+	assert.NoError(s.T(), writer.Write(variant))
+	assert.NoError(s.T(), writer.Flush())
+	assert.Equal(s.T(), vcfLines, buffer.String())
+}
+
+func TestPercentEncodingSuite(t *testing.T) {
+	suite.Run(t, new(PercentEncodingSuite))
+}
@@ -0,0 +1,132 @@
+package vcf
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// sampleSelection maps the samples kept by SelectSamples and ExcludeSamples to their columns on the file.
+type sampleSelection struct {
+	// columns holds the 0-based index, among the sample columns of the file, of each sample kept.
+	columns []int
+	// total is the number of sample columns declared on the #CHROM line of the file.
+	total int
+}
+
+// selectSamples restricts the header to the samples chosen by SelectSamples and ExcludeSamples, remembering
+// the columns they come from so that the others are never parsed.
+func (config *readerOptions) selectSamples(header *Header) error {
+	if !config.samplesSelected && len(config.excludedSamples) == 0 {
+		return nil
+	}
+	ids := header.SampleIDs()
+	columns := make(map[string]int, len(ids))
+	for i, id := range ids {
+		columns[id] = i
+	}
+
+	selected := ids
+	if config.samplesSelected {
+		selected = config.selectedSamples
+		seen := make(map[string]bool, len(selected))
+		for _, id := range selected {
+			if _, found := columns[id]; !found {
+				return fmt.Errorf("sample %s not found on the header", id)
+			}
+			if seen[id] {
+				return fmt.Errorf("sample %s selected more than once", id)
+			}
+			seen[id] = true
+		}
+	}
+	excluded := make(map[string]bool, len(config.excludedSamples))
+	for _, id := range config.excludedSamples {
+		excluded[id] = true
+	}
+
+	selection := &sampleSelection{columns: make([]int, 0, len(selected)), total: len(ids)}
+	headerColumns := append([]string{}, header.Columns[:len(header.Columns)-len(ids)]...)
+	for _, id := range selected {
+		if excluded[id] {
+			continue
+		}
+		selection.columns = append(selection.columns, columns[id])
+		headerColumns = append(headerColumns, id)
+	}
+	header.Columns = headerColumns
+	header.selection = selection
+	return nil
+}
+
+// sampleColumns returns the columns of the samples kept by SelectSamples and ExcludeSamples, nil when every
+// sample is kept, along with the number of sample columns declared on the file.
+func (h *Header) sampleColumns() (columns []int, total int) {
+	if h == nil {
+		return nil, 0
+	}
+	if h.selection != nil {
+		return h.selection.columns, h.selection.total
+	}
+	return nil, len(h.SampleIDs())
+}
+
+// alleleCountDefinitions are the ##INFO lines declaring the values set by RecomputeAlleleCounts.
+var alleleCountDefinitions = []struct {
+	id   string
+	line string
+}{
+	{"AC", `##INFO=<ID=AC,Number=A,Type=Integer,Description="Allele count in genotypes, for each ALT allele, in the same order as listed">`},
+	{"AN", `##INFO=<ID=AN,Number=1,Type=Integer,Description="Total number of alleles in called genotypes">`},
+	{"AF", `##INFO=<ID=AF,Number=A,Type=Float,Description="Allele Frequency, for each ALT allele, in the same order as listed">`},
+}
+
+// addAlleleCountDefinitions declares the AC, AN and AF INFO fields on the header when it does not have them.
+func addAlleleCountDefinitions(header *Header) {
+	for _, definition := range alleleCountDefinitions {
+		if _, found := header.Infos[definition.id]; !found {
+			header.AddMetaLine(definition.line)
+		}
+	}
+}
+
+// recomputeAlleleCounts sets AC, AN and AF from the genotypes of the samples of the variant. AF is removed when
+// no allele is called. Variants without GT are left as they are.
+func (v *Variant) recomputeAlleleCounts() {
+	hasGenotypes := false
+	for _, key := range v.Format {
+		hasGenotypes = hasGenotypes || key == "GT"
+	}
+	if !hasGenotypes {
+		return
+	}
+	count, total := 0, 0
+	for _, sample := range v.Samples {
+		genotype, err := ParseGenotype(sample["GT"])
+		if err != nil {
+			continue
+		}
+		for _, allele := range genotype.Alleles {
+			if allele == MissingAllele {
+				continue
+			}
+			total++
+			if allele == v.AlleleIndex {
+				count++
+			}
+		}
+	}
+	if v.Info == nil {
+		v.Info = make(map[string]interface{})
+	}
+	v.AlleleCount, v.TotalAlleles = &count, &total
+	v.Info["AC"] = strconv.Itoa(count)
+	v.Info["AN"] = strconv.Itoa(total)
+	if total == 0 {
+		v.AlleleFrequency = nil
+		delete(v.Info, "AF")
+		return
+	}
+	frequency := float64(count) / float64(total)
+	v.AlleleFrequency = &frequency
+	v.Info["AF"] = strconv.FormatFloat(frequency, 'g', 6, 64)
+}
@@ -0,0 +1,129 @@
+package vcf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+const cohortLines = `##fileformat=VCFv4.2
+##INFO=<ID=AC,Number=A,Type=Integer,Description="Allele count">
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	S1	S2	S3	S4
+1	100	.	A	G,T	50	PASS	AC=3,2;AN=8	GT:DP	0/1:10	1/1:12	2/2:8	0/0:9
+1	200	.	C	G	50	PASS	.	GT	./.	0/1	0|0	1|1
+1	300	.	C	G	50	PASS	DP=40	DP	10	12	8	9
+`
+
+type SampleSelectionSuite struct {
+	suite.Suite
+}
+
+func (s *SampleSelectionSuite) read(vcfLines string, options ...ReaderOption) (*Header, []*Variant, []InvalidLine, error) {
+	output := make(chan *Variant, 10)
+	invalidChannel := make(chan InvalidLine, 10)
+	var header *Header
+	options = append(options, WithHeaderHandler(func(h *Header) { header = h }))
+	err := ToChannel(strings.NewReader(vcfLines), output, invalidChannel, options...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	variants := make([]*Variant, 0)
+	for variant := range output {
+		variants = append(variants, variant)
+	}
+	invalids := make([]InvalidLine, 0)
+	for invalid := range invalidChannel {
+		invalids = append(invalids, invalid)
+	}
+	return header, variants, invalids, nil
+}
+
+func (s *SampleSelectionSuite) TestSelectSamples() {
+	header, variants, invalids, err := s.read(cohortLines, SelectSamples("S4", "S2"))
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), invalids)
+	assert.Equal(s.T(), []string{"S4", "S2"}, header.SampleIDs())
+	assert.Equal(s.T(), "#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tS4\tS2", strings.Split(header.String(), "\n")[2])
+	assert.Len(s.T(), variants, 4)
+	assert.Equal(s.T(), []map[string]string{{"GT": "0/0", "DP": "9"}, {"GT": "1/1", "DP": "12"}}, variants[0].Samples)
+	assert.Equal(s.T(), []map[string]string{{"GT": "1|1"}, {"GT": "0/1"}}, variants[2].Samples)
+
+	// the counts of the file are kept without RecomputeAlleleCounts
+	assert.Equal(s.T(), 3, *variants[0].AlleleCount)
+	assert.Equal(s.T(), 8, *variants[0].TotalAlleles)
+}
+
+func (s *SampleSelectionSuite) TestExcludeSamples() {
+	header, variants, _, err := s.read(cohortLines, ExcludeSamples("S1", "S3", "UNKNOWN"))
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"S2", "S4"}, header.SampleIDs())
+	assert.Equal(s.T(), []map[string]string{{"DP": "12"}, {"DP": "9"}}, variants[3].Samples)
+
+	header, variants, _, err = s.read(cohortLines, SelectSamples("S3", "S2", "S1"), ExcludeSamples("S2"))
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"S3", "S1"}, header.SampleIDs())
+	assert.Equal(s.T(), []map[string]string{{"GT": "0|0"}, {"GT": "./."}}, variants[2].Samples)
+
+	header, variants, _, err = s.read(cohortLines, SelectSamples())
+	assert.NoError(s.T(), err)
+	assert.Nil(s.T(), header.SampleIDs())
+	assert.Empty(s.T(), variants[0].Samples)
+}
+
+func (s *SampleSelectionSuite) TestUnknownSample() {
+	_, _, _, err := s.read(cohortLines, SelectSamples("S1", "S5"))
+	assert.EqualError(s.T(), err, "sample S5 not found on the header")
+
+	_, _, _, err = s.read(cohortLines, SelectSamples("S1", "S1"))
+	assert.EqualError(s.T(), err, "sample S1 selected more than once")
+}
+
+func (s *SampleSelectionSuite) TestUnselectedColumnsNotParsed() {
+	lines := "##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tS1\tS2\tS3\n" +
+		"1\t100\t.\tA\tG\t50\tPASS\t.\tGT\t0/1\t0/1:extra\n"
+	var warnings []Warning
+	_, variants, invalids, err := s.read(lines, SelectSamples("S1", "S3"), WithWarningHandler(func(warning Warning) {
+		warnings = append(warnings, warning)
+	}))
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), invalids)
+	assert.Equal(s.T(), []map[string]string{{"GT": "0/1"}, {"GT": "."}}, variants[0].Samples)
+	assert.Len(s.T(), warnings, 1)
+	assert.Equal(s.T(), "expected 3 samples as declared on the #CHROM line, found 2", warnings[0].Message)
+}
+
+func (s *SampleSelectionSuite) TestRecomputeAlleleCounts() {
+	header, variants, _, err := s.read(cohortLines, SelectSamples("S1", "S2"), RecomputeAlleleCounts())
+	assert.NoError(s.T(), err)
+	assert.Contains(s.T(), header.Infos, "AN")
+	assert.Contains(s.T(), header.Infos, "AF")
+
+	assert.Equal(s.T(), 3, *variants[0].AlleleCount)
+	assert.Equal(s.T(), 4, *variants[0].TotalAlleles)
+	assert.Equal(s.T(), 0.75, *variants[0].AlleleFrequency)
+	assert.Equal(s.T(), "3", variants[0].Info["AC"])
+	assert.Equal(s.T(), "0.75", variants[0].Info["AF"])
+	assert.Equal(s.T(), 0, *variants[1].AlleleCount)
+	assert.Equal(s.T(), 0.0, *variants[1].AlleleFrequency)
+
+	// missing calls are left out of AN
+	assert.Equal(s.T(), 1, *variants[2].AlleleCount)
+	assert.Equal(s.T(), 2, *variants[2].TotalAlleles)
+
+	// records without GT keep their values
+	assert.Nil(s.T(), variants[3].AlleleCount)
+	assert.Nil(s.T(), variants[3].Info["AC"])
+
+	_, variants, _, err = s.read(cohortLines, SelectSamples("S1"), RecomputeAlleleCounts())
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 0, *variants[2].TotalAlleles)
+	assert.Nil(s.T(), variants[2].AlleleFrequency)
+	assert.NotContains(s.T(), variants[2].Info, "AF")
+}
+
+func TestSampleSelectionSuite(t *testing.T) {
+	suite.Run(t, new(SampleSelectionSuite))
+}
@@ -0,0 +1,190 @@
+package vcf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Annotation is a functional annotation written by SnpEff, either on the standard ANN INFO field or on the legacy
+// EFF one. Numbers that are absent from the annotation are zero, and every column is kept on Fields, by the name
+// used on the ANN or EFF specification.
+type Annotation struct {
+	// Allele is the ALT the annotation refers to. Legacy EFF annotations may identify it by its number instead,
+	// starting at 1, in which case AlleleNumber is set and Allele is empty.
+	Allele       string
+	AlleleNumber int
+
+	// Terms holds the Sequence Ontology terms of the annotation, such as missense_variant, or the effect of EFF,
+	// such as NON_SYNONYMOUS_CODING.
+	Terms []string
+
+	Impact      string
+	GeneName    string
+	GeneID      string
+	FeatureType string
+	FeatureID   string
+	Biotype     string
+	Rank        string
+
+	// HGVSc and HGVSp hold the HGVS notations of ANN. For EFF they hold the Codon_Change and Amino_Acid_Change
+	// columns, which follow HGVS only when SnpEff was run with -hgvs.
+	HGVSc string
+	HGVSp string
+
+	CDNAPosition, CDNALength       int
+	CDSPosition, CDSLength         int
+	ProteinPosition, ProteinLength int
+	Distance                       int
+
+	// Messages holds the errors, warnings and information codes of the annotation, such as
+	// WARNING_TRANSCRIPT_INCOMPLETE.
+	Messages []string
+
+	Fields map[string]string
+}
+
+// annFields are the columns of the ANN field, as defined by its specification.
+var annFields = []string{
+	"Allele", "Annotation", "Annotation_Impact", "Gene_Name", "Gene_ID", "Feature_Type", "Feature_ID",
+	"Transcript_BioType", "Rank", "HGVS.c", "HGVS.p", "cDNA.pos / cDNA.length", "CDS.pos / CDS.length",
+	"AA.pos / AA.length", "Distance", "ERRORS / WARNINGS / INFO",
+}
+
+// effFields are the columns of the legacy EFF field, enclosed in parentheses after the effect. The last two are
+// optional.
+var effFields = []string{
+	"Effect_Impact", "Functional_Class", "Codon_Change", "Amino_Acid_Change", "Amino_Acid_Length", "Gene_Name",
+	"Transcript_BioType", "Gene_Coding", "Transcript_ID", "Exon_Rank", "Genotype", "ERRORS", "WARNINGS",
+}
+
+// ParseANN splits a raw ANN value into its annotations, one for each comma-separated entry.
+func ParseANN(value string) ([]*Annotation, error) {
+	if value == "" || value == "." {
+		return nil, nil
+	}
+	entries := strings.Split(value, ",")
+	annotations := make([]*Annotation, 0, len(entries))
+	for _, entry := range entries {
+		columns := strings.Split(entry, "|")
+		if len(columns) < len(annFields)-1 || len(columns) > len(annFields) {
+			return nil, fmt.Errorf("ANN entry with %d columns, expected %d: %s", len(columns), len(annFields), entry)
+		}
+		annotation := &Annotation{Fields: make(map[string]string, len(columns))}
+		for i, column := range columns {
+			annotation.Fields[annFields[i]] = column
+		}
+		annotation.Allele = columns[0]
+		annotation.Terms = splitNonEmpty(columns[1], "&")
+		annotation.Impact = columns[2]
+		annotation.GeneName = columns[3]
+		annotation.GeneID = columns[4]
+		annotation.FeatureType = columns[5]
+		annotation.FeatureID = columns[6]
+		annotation.Biotype = columns[7]
+		annotation.Rank = columns[8]
+		annotation.HGVSc = columns[9]
+		annotation.HGVSp = columns[10]
+		annotation.CDNAPosition, annotation.CDNALength = parsePositionAndLength(columns[11])
+		annotation.CDSPosition, annotation.CDSLength = parsePositionAndLength(columns[12])
+		annotation.ProteinPosition, annotation.ProteinLength = parsePositionAndLength(columns[13])
+		annotation.Distance, _ = strconv.Atoi(columns[14])
+		if len(columns) == len(annFields) {
+			annotation.Messages = splitNonEmpty(columns[15], "&")
+		}
+		annotations = append(annotations, annotation)
+	}
+	return annotations, nil
+}
+
+// ParseEFF splits a raw EFF value, such as NON_SYNONYMOUS_CODING(MODERATE|MISSENSE|Gcc/Acc|A41T|...), into its
+// annotations, one for each comma-separated entry.
+func ParseEFF(value string) ([]*Annotation, error) {
+	if value == "" || value == "." {
+		return nil, nil
+	}
+	entries := strings.Split(value, ",")
+	annotations := make([]*Annotation, 0, len(entries))
+	for _, entry := range entries {
+		open := strings.Index(entry, "(")
+		if open <= 0 || !strings.HasSuffix(entry, ")") {
+			return nil, fmt.Errorf("EFF entry is not written as Effect(...): %s", entry)
+		}
+		columns := strings.Split(entry[open+1:len(entry)-1], "|")
+		if len(columns) < len(effFields)-2 || len(columns) > len(effFields) {
+			return nil, fmt.Errorf("EFF entry with %d columns, expected %d to %d: %s", len(columns), len(effFields)-2, len(effFields), entry)
+		}
+		annotation := &Annotation{Terms: []string{entry[:open]}, Fields: map[string]string{"Effect": entry[:open]}}
+		for i, column := range columns {
+			annotation.Fields[effFields[i]] = column
+		}
+		annotation.Impact = columns[0]
+		annotation.HGVSc = columns[2]
+		annotation.HGVSp = columns[3]
+		annotation.ProteinLength, _ = strconv.Atoi(columns[4])
+		annotation.GeneName = columns[5]
+		annotation.Biotype = columns[6]
+		annotation.FeatureID = columns[8]
+		if annotation.FeatureID != "" {
+			annotation.FeatureType = "transcript"
+		}
+		annotation.Rank = columns[9]
+		if number, err := strconv.Atoi(columns[10]); err == nil {
+			annotation.AlleleNumber = number
+		} else {
+			annotation.Allele = columns[10]
+		}
+		for _, message := range columns[11:] {
+			annotation.Messages = append(annotation.Messages, splitNonEmpty(message, "&")...)
+		}
+		annotations = append(annotations, annotation)
+	}
+	return annotations, nil
+}
+
+// Annotations decodes the SnpEff annotations of the variant, from ANN or, when it is absent, from EFF, and keeps
+// the ones of its own ALT, along with the ones that do not name an allele. Since ToChannel splits multiple
+// alternatives into separate variants, each one still carries the annotations of its siblings.
+func (v *Variant) Annotations() ([]*Annotation, error) {
+	var annotations []*Annotation
+	var err error
+	if value, ok := v.Info["ANN"].(string); ok {
+		annotations, err = ParseANN(value)
+	} else if value, ok := v.Info["EFF"].(string); ok {
+		annotations, err = ParseEFF(value)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	alt := v.Alt
+	if index := v.AlleleIndex - 1; index >= 0 && index < len(v.Alternatives) {
+		// SnpEff writes the ALT as it is on the line, before the suffix shared with the REF is trimmed
+		alt = v.Alternatives[index]
+	}
+	matching := make([]*Annotation, 0, len(annotations))
+	for _, annotation := range annotations {
+		if (annotation.AlleleNumber > 0 && annotation.AlleleNumber == v.AlleleIndex) ||
+			(annotation.AlleleNumber == 0 && (annotation.Allele == "" || strings.EqualFold(annotation.Allele, alt))) {
+			matching = append(matching, annotation)
+		}
+	}
+	return matching, nil
+}
+
+// parsePositionAndLength parses the pos / length columns of ANN, such as 123/1000.
+func parsePositionAndLength(value string) (position, length int) {
+	parts := strings.SplitN(value, "/", 2)
+	position, _ = strconv.Atoi(strings.TrimSpace(parts[0]))
+	if len(parts) == 2 {
+		length, _ = strconv.Atoi(strings.TrimSpace(parts[1]))
+	}
+	return position, length
+}
+
+func splitNonEmpty(value, separator string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, separator)
+}
@@ -0,0 +1,86 @@
+package vcf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SnpEffSuite struct {
+	suite.Suite
+}
+
+func (s *SnpEffSuite) TestParseANN() {
+	annotations, err := ParseANN("T|missense_variant&splice_region_variant|MODERATE|CCT8L2|ENSG00000198445|transcript|ENST00000359963|protein_coding|1/1|c.1406G>A|p.Gly469Glu|1666/2034|1406/1674|469/557|12|WARNING_TRANSCRIPT_NO_START_CODON")
+
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), annotations, 1)
+	annotation := annotations[0]
+	assert.Equal(s.T(), "T", annotation.Allele)
+	assert.Equal(s.T(), []string{"missense_variant", "splice_region_variant"}, annotation.Terms)
+	assert.Equal(s.T(), "MODERATE", annotation.Impact)
+	assert.Equal(s.T(), "CCT8L2", annotation.GeneName)
+	assert.Equal(s.T(), "ENSG00000198445", annotation.GeneID)
+	assert.Equal(s.T(), "transcript", annotation.FeatureType)
+	assert.Equal(s.T(), "ENST00000359963", annotation.FeatureID)
+	assert.Equal(s.T(), "protein_coding", annotation.Biotype)
+	assert.Equal(s.T(), "1/1", annotation.Rank)
+	assert.Equal(s.T(), "c.1406G>A", annotation.HGVSc)
+	assert.Equal(s.T(), "p.Gly469Glu", annotation.HGVSp)
+	assert.Equal(s.T(), []int{1666, 2034, 1406, 1674, 469, 557}, []int{annotation.CDNAPosition, annotation.CDNALength, annotation.CDSPosition, annotation.CDSLength, annotation.ProteinPosition, annotation.ProteinLength})
+	assert.Equal(s.T(), 12, annotation.Distance)
+	assert.Equal(s.T(), []string{"WARNING_TRANSCRIPT_NO_START_CODON"}, annotation.Messages)
+	assert.Equal(s.T(), "1666/2034", annotation.Fields["cDNA.pos / cDNA.length"])
+
+	_, err = ParseANN("T|missense_variant|MODERATE")
+	assert.EqualError(s.T(), err, "ANN entry with 3 columns, expected 16: T|missense_variant|MODERATE")
+}
+
+func (s *SnpEffSuite) TestParseEFF() {
+	annotations, err := ParseEFF("NON_SYNONYMOUS_CODING(MODERATE|MISSENSE|Ggg/Agg|G469R|557|CCT8L2|protein_coding|CODING|ENST00000359963|1|2),INTERGENIC(MODIFIER||||||||||T|ERROR_CHROMOSOME_NOT_FOUND)")
+
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), annotations, 2)
+	assert.Equal(s.T(), []string{"NON_SYNONYMOUS_CODING"}, annotations[0].Terms)
+	assert.Equal(s.T(), "MODERATE", annotations[0].Impact)
+	assert.Equal(s.T(), "G469R", annotations[0].HGVSp)
+	assert.Equal(s.T(), 557, annotations[0].ProteinLength)
+	assert.Equal(s.T(), "CCT8L2", annotations[0].GeneName)
+	assert.Equal(s.T(), "ENST00000359963", annotations[0].FeatureID)
+	assert.Equal(s.T(), "MISSENSE", annotations[0].Fields["Functional_Class"])
+	assert.Equal(s.T(), 2, annotations[0].AlleleNumber)
+	assert.Empty(s.T(), annotations[0].Allele)
+
+	assert.Equal(s.T(), "T", annotations[1].Allele)
+	assert.Equal(s.T(), []string{"ERROR_CHROMOSOME_NOT_FOUND"}, annotations[1].Messages)
+
+	_, err = ParseEFF("NON_SYNONYMOUS_CODING")
+	assert.EqualError(s.T(), err, "EFF entry is not written as Effect(...): NON_SYNONYMOUS_CODING")
+}
+
+func (s *SnpEffSuite) TestAnnotationsOfEachAlternative() {
+	variants, err := parseVcfLine("1\t100\t.\tAC\tTC,A\t.\tPASS\tANN=TC|missense_variant|MODERATE|G|G|transcript|T1|protein_coding|||||||,A|frameshift_variant|HIGH|G|G|transcript|T1|protein_coding|||||||", defaultHeader, Standard, nil)
+	assert.NoError(s.T(), err)
+
+	annotations, err := variants[0].Annotations()
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), annotations, 1)
+	assert.Equal(s.T(), []string{"missense_variant"}, annotations[0].Terms, "the ALT is matched before its suffix is trimmed")
+
+	annotations, err = variants[1].Annotations()
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), annotations, 1)
+	assert.Equal(s.T(), []string{"frameshift_variant"}, annotations[0].Terms)
+
+	variants, err = parseVcfLine("1\t100\t.\tA\tG,T\t.\tPASS\tEFF=SYNONYMOUS_CODING(LOW|SILENT|||||||||1),STOP_GAINED(HIGH|NONSENSE|||||||||2)", defaultHeader, Standard, nil)
+	assert.NoError(s.T(), err)
+	annotations, err = variants[1].Annotations()
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), annotations, 1)
+	assert.Equal(s.T(), []string{"STOP_GAINED"}, annotations[0].Terms, "EFF genotype numbers select the alternative")
+}
+
+func TestSnpEffSuite(t *testing.T) {
+	suite.Run(t, new(SnpEffSuite))
+}
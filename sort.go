@@ -0,0 +1,246 @@
+package vcf
+
+import (
+	"bufio"
+	"container/heap"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DefaultSortMemoryLimit is the memory budget used by Sort when none is given.
+const DefaultSortMemoryLimit = 256 << 20
+
+// SortOptions configures Sort.
+type SortOptions struct {
+	// MemoryLimit is the approximate amount of bytes of records held in memory. When it is exceeded, the
+	// records read so far are sorted and written to a temporary file. Zero means DefaultSortMemoryLimit.
+	MemoryLimit int
+
+	// TempDir is the directory of the temporary files, the default directory for temporary files when empty.
+	TempDir string
+}
+
+// recordOverhead approximates the memory used by a sortRecord besides the line itself.
+const recordOverhead = 96
+
+// Sort writes the records of a VCF to w ordered by chromosome, following the ##contig lines of the header and
+// the natural chromosome order for undeclared ones, then by position, REF and ALT. Records that compare equal
+// keep their input order.
+//
+// Records are copied as they are, without being parsed beyond CHROM, POS, REF and ALT. When they do not fit
+// in the memory budget, sorted runs are written to temporary files, which are merged back and removed.
+func Sort(reader io.Reader, w io.Writer, options SortOptions) error {
+	limit := options.MemoryLimit
+	if limit <= 0 {
+		limit = DefaultSortMemoryLimit
+	}
+
+	bufferedReader := bufio.NewReaderSize(reader, 100*1024)
+	header, err := vcfHeader(bufferedReader)
+	if err != nil {
+		return err
+	}
+	order := newContigOrder(header)
+
+	sorter := &externalSorter{order: order, tempDir: options.TempDir}
+	defer sorter.removeRuns()
+
+	var records []*sortRecord
+	size := 0
+	lineNumber := header.lineCount
+	for {
+		line, readError := bufferedReader.ReadString('\n')
+		if readError != nil && readError != io.EOF {
+			return readError
+		}
+		if line == "" && readError == io.EOF {
+			break
+		}
+		lineNumber++
+		if !isHeaderLine(line) {
+			record, err := newSortRecord(line)
+			if err != nil {
+				return fmt.Errorf("line %d: %v", lineNumber, err)
+			}
+			records = append(records, record)
+			size += len(line) + recordOverhead
+			if size >= limit {
+				if err := sorter.spill(records); err != nil {
+					return err
+				}
+				records, size = nil, 0
+			}
+		}
+		if readError == io.EOF {
+			break
+		}
+	}
+
+	writer := bufio.NewWriterSize(w, 100*1024)
+	if _, err := writer.WriteString(header.String()); err != nil {
+		return err
+	}
+	if len(sorter.runs) == 0 {
+		sorter.sort(records)
+		for _, record := range records {
+			if _, err := writer.WriteString(record.line); err != nil {
+				return err
+			}
+		}
+		return writer.Flush()
+	}
+
+	if len(records) > 0 {
+		if err := sorter.spill(records); err != nil {
+			return err
+		}
+	}
+	if err := sorter.merge(writer); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+type sortRecord struct {
+	chrom string
+	pos   int
+	key   alleleKey
+	line  string
+
+	// run is the index of the temporary file the record was read from, used to keep the merge stable
+	run int
+}
+
+func newSortRecord(line string) (*sortRecord, error) {
+	chrom, pos, key, err := recordLocus(line)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(line, "\n") {
+		line += "\n"
+	}
+	return &sortRecord{chrom: chrom, pos: pos, key: key, line: line}, nil
+}
+
+// externalSorter keeps the sorted runs written to temporary files.
+type externalSorter struct {
+	order   *contigOrder
+	tempDir string
+	runs    []*os.File
+}
+
+func (s *externalSorter) less(a, b *sortRecord) bool {
+	if c := s.order.compareLoci(a.chrom, a.pos, b.chrom, b.pos); c != 0 {
+		return c < 0
+	}
+	return a.key.less(b.key)
+}
+
+func (s *externalSorter) sort(records []*sortRecord) {
+	sort.SliceStable(records, func(i, j int) bool { return s.less(records[i], records[j]) })
+}
+
+// spill sorts the records and writes them to a new temporary file.
+func (s *externalSorter) spill(records []*sortRecord) error {
+	s.sort(records)
+	file, err := os.CreateTemp(s.tempDir, "vcfsort-*.vcf")
+	if err != nil {
+		return err
+	}
+	s.runs = append(s.runs, file)
+
+	writer := bufio.NewWriterSize(file, 100*1024)
+	for _, record := range records {
+		if _, err := writer.WriteString(record.line); err != nil {
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	_, err = file.Seek(0, io.SeekStart)
+	return err
+}
+
+// merge writes the records of every run to w, in order.
+func (s *externalSorter) merge(w io.Writer) error {
+	readers := make([]*bufio.Reader, len(s.runs))
+	heads := &recordHeap{sorter: s}
+	for i, file := range s.runs {
+		readers[i] = bufio.NewReaderSize(file, 64*1024)
+		if err := heads.pushNext(readers[i], i); err != nil {
+			return err
+		}
+	}
+
+	for heads.Len() > 0 {
+		record := heap.Pop(heads).(*sortRecord)
+		if _, err := io.WriteString(w, record.line); err != nil {
+			return err
+		}
+		if err := heads.pushNext(readers[record.run], record.run); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeRuns closes and removes the temporary files.
+func (s *externalSorter) removeRuns() {
+	for _, file := range s.runs {
+		file.Close()
+		os.Remove(file.Name())
+	}
+	s.runs = nil
+}
+
+// recordHeap holds the next record of each run, smallest first. Ties are broken by run, since runs hold
+// records in input order.
+type recordHeap struct {
+	sorter  *externalSorter
+	records []*sortRecord
+}
+
+func (h *recordHeap) Len() int { return len(h.records) }
+
+func (h *recordHeap) Less(i, j int) bool {
+	a, b := h.records[i], h.records[j]
+	if h.sorter.less(a, b) {
+		return true
+	}
+	if h.sorter.less(b, a) {
+		return false
+	}
+	return a.run < b.run
+}
+
+func (h *recordHeap) Swap(i, j int) { h.records[i], h.records[j] = h.records[j], h.records[i] }
+
+func (h *recordHeap) Push(x interface{}) { h.records = append(h.records, x.(*sortRecord)) }
+
+func (h *recordHeap) Pop() interface{} {
+	last := h.records[len(h.records)-1]
+	h.records = h.records[:len(h.records)-1]
+	return last
+}
+
+// pushNext reads the next record of a run into the heap, if there is one.
+func (h *recordHeap) pushNext(reader *bufio.Reader, run int) error {
+	line, err := reader.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return nil
+	}
+	if err != nil && err != io.EOF {
+		return err
+	}
+	record, err := newSortRecord(line)
+	if err != nil {
+		return err
+	}
+	record.run = run
+	heap.Push(h, record)
+	return nil
+}
@@ -0,0 +1,89 @@
+package vcf
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+const unsortedHeader = `##fileformat=VCFv4.2
+##contig=<ID=chr2,length=1000>
+##contig=<ID=chr1,length=1000>
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO
+`
+
+type SortSuite struct {
+	suite.Suite
+}
+
+func (s *SortSuite) records(output string) []string {
+	records := make([]string, 0)
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			records = append(records, line)
+		}
+	}
+	return records
+}
+
+func (s *SortSuite) TestSort() {
+	input := unsortedHeader +
+		"chr1\t10\tfirst\tA\tG\t.\t.\t.\n" +
+		"chrX\t5\t.\tA\tG\t.\t.\t.\n" +
+		"chr1\t10\t.\tA\tC\t.\t.\t.\n" +
+		"chr10\t5\t.\tA\tG\t.\t.\t.\n" +
+		"chr2\t100\t.\tA\tG\t.\t.\t.\n" +
+		"chr1\t10\tsecond\tA\tG\t.\t.\t.\n" +
+		"chr3\t5\t.\tA\tG\t.\t.\t.\n" +
+		"chr2\t20\t.\tA\tG\t.\t.\t." // no newline at the end
+
+	var buffer bytes.Buffer
+	assert.NoError(s.T(), Sort(strings.NewReader(input), &buffer, SortOptions{}))
+	assert.True(s.T(), strings.HasPrefix(buffer.String(), unsortedHeader))
+	assert.Equal(s.T(), []string{
+		"chr2\t20\t.\tA\tG\t.\t.\t.",
+		"chr2\t100\t.\tA\tG\t.\t.\t.",
+		"chr1\t10\t.\tA\tC\t.\t.\t.",
+		"chr1\t10\tfirst\tA\tG\t.\t.\t.",
+		"chr1\t10\tsecond\tA\tG\t.\t.\t.",
+		"chr3\t5\t.\tA\tG\t.\t.\t.",
+		"chr10\t5\t.\tA\tG\t.\t.\t.",
+		"chrX\t5\t.\tA\tG\t.\t.\t.",
+	}, s.records(buffer.String()))
+}
+
+func (s *SortSuite) TestSpillToTemporaryFiles() {
+	random := rand.New(rand.NewSource(1))
+	lines := make([]string, 2000)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("chr%d\t%d\tid%d\tA\tG\t.\t.\t.\n", random.Intn(3)+1, random.Intn(100)+1, i)
+	}
+	input := unsortedHeader + strings.Join(lines, "")
+
+	var inMemory, spilled bytes.Buffer
+	assert.NoError(s.T(), Sort(strings.NewReader(input), &inMemory, SortOptions{}))
+
+	tempDir := s.T().TempDir()
+	assert.NoError(s.T(), Sort(strings.NewReader(input), &spilled, SortOptions{MemoryLimit: 10 * 1024, TempDir: tempDir}))
+	assert.Equal(s.T(), inMemory.String(), spilled.String(), "merged runs should keep the same stable order")
+
+	files, err := os.ReadDir(tempDir)
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), files, "temporary files should be removed")
+}
+
+func (s *SortSuite) TestInvalidRecord() {
+	input := unsortedHeader + "chr1\t10\t.\tA\tG\t.\t.\t.\nchr1\tten\t.\tA\tG\t.\t.\t.\n"
+	err := Sort(strings.NewReader(input), &bytes.Buffer{}, SortOptions{})
+	assert.EqualError(s.T(), err, "line 6: unable to parse POS: ten")
+}
+
+func TestSortSuite(t *testing.T) {
+	suite.Run(t, new(SortSuite))
+}
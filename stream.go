@@ -0,0 +1,137 @@
+package vcf
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// variantStream wraps ToChannel running on its own goroutine, so that the variants of a reader can be
+// consumed one at a time, with lookahead. It is the building block of the components that walk several
+// files in coordinate order.
+type variantStream struct {
+	header   *Header
+	variants chan *Variant
+	done     chan error
+	head     *Variant
+
+	mutex    sync.Mutex
+	invalids int
+	invalid  *InvalidLine
+	finished chan struct{}
+}
+
+func newVariantStream(reader io.Reader, options ...ReaderOption) (*variantStream, error) {
+	stream := &variantStream{
+		variants: make(chan *Variant, 1024),
+		done:     make(chan error, 1),
+		finished: make(chan struct{}),
+	}
+	invalids := make(chan InvalidLine, 1024)
+	headers := make(chan *Header, 1)
+	options = append(options, WithHeaderHandler(func(header *Header) { headers <- header }))
+
+	go func() {
+		stream.done <- ToChannel(reader, stream.variants, invalids, options...)
+	}()
+
+	select {
+	case stream.header = <-headers:
+	case err := <-stream.done:
+		return nil, err
+	}
+
+	go func() {
+		for invalid := range invalids {
+			stream.mutex.Lock()
+			if stream.invalid == nil {
+				first := invalid
+				stream.invalid = &first
+			}
+			stream.invalids++
+			stream.mutex.Unlock()
+		}
+		close(stream.finished)
+	}()
+
+	return stream, nil
+}
+
+// peek returns the next variant without consuming it, or nil when the stream is exhausted.
+func (s *variantStream) peek() *Variant {
+	if s.head == nil {
+		s.head = <-s.variants
+	}
+	return s.head
+}
+
+// next consumes and returns the next variant, or nil when the stream is exhausted.
+func (s *variantStream) next() *Variant {
+	variant := s.peek()
+	s.head = nil
+	return variant
+}
+
+// close consumes any remaining variant and reports read errors, as well as lines that could not be parsed.
+func (s *variantStream) close() error {
+	for range s.variants {
+	}
+	err := <-s.done
+	<-s.finished
+	if err != nil {
+		return err
+	}
+	if s.invalid != nil {
+		return fmt.Errorf("%d lines could not be parsed, the first one failed with: %v", s.invalids, s.invalid.Err)
+	}
+	return nil
+}
+
+// openVariantStreams opens a stream for each reader. When any of them fails, the ones already opened are
+// closed and the error is returned.
+func openVariantStreams(readers []io.Reader, options ...ReaderOption) ([]*variantStream, error) {
+	streams := make([]*variantStream, 0, len(readers))
+	for _, reader := range readers {
+		stream, err := newVariantStream(reader, options...)
+		if err != nil {
+			closeVariantStreams(streams)
+			return nil, err
+		}
+		streams = append(streams, stream)
+	}
+	return streams, nil
+}
+
+// closeVariantStreams closes every stream, returning the first error found.
+func closeVariantStreams(streams []*variantStream) error {
+	var firstErr error
+	for _, stream := range streams {
+		if err := stream.close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// nextLocus returns the smallest position among the next variants of the streams.
+func nextLocus(streams []*variantStream, order *contigOrder) (chrom string, pos int, found bool) {
+	for _, stream := range streams {
+		variant := stream.peek()
+		if variant == nil {
+			continue
+		}
+		if !found || order.compareLoci(variant.Chrom, variant.Pos, chrom, pos) < 0 {
+			chrom, pos, found = variant.Chrom, variant.Pos, true
+		}
+	}
+	return chrom, pos, found
+}
+
+// popLocus consumes and returns every variant of the stream at the given position.
+func (s *variantStream) popLocus(chrom string, pos int, order *contigOrder) []*Variant {
+	var variants []*Variant
+	for variant := s.peek(); variant != nil && order.compareLoci(variant.Chrom, variant.Pos, chrom, pos) == 0; variant = s.peek() {
+		variants = append(variants, s.next())
+	}
+	return variants
+}
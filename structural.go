@@ -0,0 +1,77 @@
+package vcf
+
+import "fmt"
+
+// ConfidenceInterval is a pair of offsets bounding the uncertainty of a position or length, such as
+// CIPOS=-10,62. Lower is usually negative or zero, and Upper positive or zero.
+type ConfidenceInterval struct {
+	Lower int
+	Upper int
+}
+
+// OuterSpan returns the widest region a structural variant may cover, from the leftmost possible start to
+// the rightmost possible end, according to CIPOS and CIEND.
+//
+// The span is 0-based and half-open, like Pos: start is Pos shifted by the lower CIPOS offset and end is
+// END shifted by the upper CIEND offset. When END is missing, the end of the REF allele is used instead.
+func (v *Variant) OuterSpan() (start, end int) {
+	start, end = v.Pos, v.end()
+	if v.ConfidenceIntervalAroundPosition != nil {
+		start += v.ConfidenceIntervalAroundPosition.Lower
+	}
+	if v.ConfidenceIntervalAroundEnd != nil {
+		end += v.ConfidenceIntervalAroundEnd.Upper
+	}
+	if start < 0 {
+		start = 0
+	}
+	return start, end
+}
+
+// InnerSpan returns the region a structural variant certainly covers, from the rightmost possible start to
+// the leftmost possible end, according to CIPOS and CIEND. Coordinates follow the same convention as
+// OuterSpan. When the confidence intervals overlap there is no such region, and ok is false.
+func (v *Variant) InnerSpan() (start, end int, ok bool) {
+	start, end = v.Pos, v.end()
+	if v.ConfidenceIntervalAroundPosition != nil {
+		start += v.ConfidenceIntervalAroundPosition.Upper
+	}
+	if v.ConfidenceIntervalAroundEnd != nil {
+		end += v.ConfidenceIntervalAroundEnd.Lower
+	}
+	if start >= end {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// applySVLength follows the SVLEN of VCF 4.4, which is the positive length of the variant. For symbolic alleles
+// that span the reference, such as <DEL> or <CNV:TR>, the END may be omitted and is derived from it.
+func applySVLength(v *Variant, warn warnFunc) {
+	if v.StructuralVariantLength == nil {
+		return
+	}
+	length := *v.StructuralVariantLength
+	if length < 0 {
+		warn("INFO/SVLEN", fmt.Sprintf("SVLEN must be positive since VCFv4.4, found %d", length))
+		length = -length
+	}
+	if v.End != nil || v.Symbolic == nil || v.StructuralVariantType == nil {
+		return
+	}
+	switch *v.StructuralVariantType {
+	case Insertion, InsertionMobileElement, Breakend:
+		return
+	}
+	// the variant starts after the padding base at POS, so END is POS + SVLEN
+	end := v.Pos + 1 + length
+	v.End = &end
+}
+
+// end returns the 0-based exclusive end of the variant, which is the same number as the 1-based END.
+func (v *Variant) end() int {
+	if v.End != nil {
+		return *v.End
+	}
+	return v.Pos + len(v.Ref)
+}
@@ -0,0 +1,120 @@
+package vcf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type StructuralSpanSuite struct {
+	suite.Suite
+}
+
+func (s *StructuralSpanSuite) TestConfidenceIntervalsAreNotSplitAcrossAlternatives() {
+	result, err := parseVcfLine("1\t2827694\trs2376870\tCGTGGATGCGGGGAC\t<DEL>,<DUP>\t.\tPASS\tSVLEN=-14,14;CIPOS=-10,62;CIEND=-5,8;CILEN=-2,3;HOMLEN=2,4;HOMSEQ=AC,ACGT;END=2827708", defaultHeader, Standard, nil)
+
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), result, 2)
+
+	for _, variant := range result {
+		assert.Equal(s.T(), ConfidenceInterval{Lower: -10, Upper: 62}, *variant.ConfidenceIntervalAroundPosition)
+		assert.Equal(s.T(), ConfidenceInterval{Lower: -5, Upper: 8}, *variant.ConfidenceIntervalAroundEnd)
+		assert.Equal(s.T(), ConfidenceInterval{Lower: -2, Upper: 3}, *variant.ConfidenceIntervalAroundLength)
+	}
+
+	assert.Equal(s.T(), -14, *result[0].StructuralVariantLength)
+	assert.Equal(s.T(), 2, *result[0].HomologyLength)
+	assert.Equal(s.T(), "AC", *result[0].HomologySequence)
+	assert.Equal(s.T(), 14, *result[1].StructuralVariantLength)
+	assert.Equal(s.T(), 4, *result[1].HomologyLength)
+	assert.Equal(s.T(), "ACGT", *result[1].HomologySequence)
+}
+
+func (s *StructuralSpanSuite) TestSVLengthFromVersion44() {
+	header := newHeader()
+	header.AddMetaLine("##fileformat=VCFv4.4")
+	warnings := make([]string, 0)
+	warn := func(field, message string) { warnings = append(warnings, field+": "+message) }
+
+	result, err := parseVcfLine("1\t1000\t.\tC\t<CNV:TR>,<INS>\t.\tPASS\tSVLEN=300,50;SVCLAIM=D,J", header, Standard, warn)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), TandemRepeatCopyNumber, *result[0].StructuralVariantType)
+	assert.Equal(s.T(), 1300, *result[0].End, "END should be POS + SVLEN")
+	assert.Equal(s.T(), "D", *result[0].StructuralVariantClaim)
+	assert.Nil(s.T(), result[1].End, "insertions do not span the reference")
+	assert.Equal(s.T(), "J", *result[1].StructuralVariantClaim)
+
+	result, err = parseVcfLine("1\t1000\t.\tC\t<DEL>\t.\tPASS\tSVLEN=-100", header, Standard, warn)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 1100, *result[0].End)
+	assert.Equal(s.T(), []string{"INFO/SVLEN: SVLEN must be positive since VCFv4.4, found -100"}, warnings)
+
+	result, err = parseVcfLine("1\t1000\t.\tC\t<DEL>\t.\tPASS\tSVLEN=-100", defaultHeader, Standard, nil)
+	assert.NoError(s.T(), err)
+	assert.Nil(s.T(), result[0].End, "older versions require END")
+}
+
+func (s *StructuralSpanSuite) TestMalformedIntervalsAreNil() {
+	result, err := parseVcfLine("1\t2827694\t.\tC\t<DEL>\t.\tPASS\tCIPOS=10;CIEND=a,b", defaultHeader, Standard, nil)
+
+	assert.NoError(s.T(), err)
+	assert.Nil(s.T(), result[0].ConfidenceIntervalAroundPosition)
+	assert.Nil(s.T(), result[0].ConfidenceIntervalAroundEnd)
+}
+
+func (s *StructuralSpanSuite) TestHeaderNumberDrivesSplitting() {
+	header := newHeader()
+	header.addMetaLine(parseMetaLine(`##INFO=<ID=AD,Number=R,Type=Integer,Description="Allelic depths">`))
+	header.addMetaLine(parseMetaLine(`##INFO=<ID=XY,Number=2,Type=Integer,Description="Pair">`))
+
+	result, err := parseVcfLine("1\t100\t.\tC\tA,T\t.\tPASS\tAD=10,4,6;XY=1,2;AC=3,4;DB", header, Standard, nil)
+
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "10,4", result[0].Info["AD"], "the reference entry of Number=R should be kept")
+	assert.Equal(s.T(), "10,6", result[1].Info["AD"])
+	assert.Equal(s.T(), "1,2", result[0].Info["XY"])
+	assert.Equal(s.T(), "1,2", result[1].Info["XY"])
+	assert.Equal(s.T(), "3", result[0].Info["AC"])
+	assert.Equal(s.T(), "4", result[1].Info["AC"])
+	assert.Equal(s.T(), true, result[1].Info["DB"], "flags should be present on every alternative")
+}
+
+func (s *StructuralSpanSuite) TestSpans() {
+	end := 2000
+	variant := &Variant{
+		Pos:                              999,
+		Ref:                              "C",
+		End:                              &end,
+		ConfidenceIntervalAroundPosition: &ConfidenceInterval{Lower: -10, Upper: 62},
+		ConfidenceIntervalAroundEnd:      &ConfidenceInterval{Lower: -5, Upper: 8},
+	}
+
+	start, stop := variant.OuterSpan()
+	assert.Equal(s.T(), 989, start)
+	assert.Equal(s.T(), 2008, stop)
+
+	start, stop, ok := variant.InnerSpan()
+	assert.True(s.T(), ok)
+	assert.Equal(s.T(), 1061, start)
+	assert.Equal(s.T(), 1995, stop)
+}
+
+func (s *StructuralSpanSuite) TestSpansWithoutIntervalsOrEnd() {
+	variant := &Variant{Pos: 99, Ref: "CAT"}
+
+	start, stop := variant.OuterSpan()
+	assert.Equal(s.T(), 99, start)
+	assert.Equal(s.T(), 102, stop)
+
+	variant.ConfidenceIntervalAroundPosition = &ConfidenceInterval{Lower: -200, Upper: 5}
+	start, _ = variant.OuterSpan()
+	assert.Equal(s.T(), 0, start, "outer span should not start before the chromosome")
+
+	_, _, ok := variant.InnerSpan()
+	assert.False(s.T(), ok, "inner span should be empty when intervals overlap")
+}
+
+func TestStructuralSpanSuite(t *testing.T) {
+	suite.Run(t, new(StructuralSpanSuite))
+}
@@ -4,9 +4,9 @@ package vcf
 
 import "fmt"
 
-const _SVType_name = "DeletionDuplicationInsertionInversionCopyNumberVariationTandemDuplicationDeletionMobileElementInsertionMobileElementBreakend"
+const _SVType_name = "DeletionDuplicationInsertionInversionCopyNumberVariationTandemDuplicationDeletionMobileElementInsertionMobileElementBreakendTandemRepeatCopyNumber"
 
-var _SVType_index = [...]uint8{0, 8, 19, 28, 37, 56, 73, 94, 116, 124}
+var _SVType_index = [...]uint8{0, 8, 19, 28, 37, 56, 73, 94, 116, 124, 146}
 
 func (i SVType) String() string {
 	if i < 0 || i >= SVType(len(_SVType_index)-1) {
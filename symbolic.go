@@ -0,0 +1,69 @@
+package vcf
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SymbolicAllele is an ALT enclosed in angle brackets, such as <DEL>, <DEL:ME:ALU> or <CN2>.
+//
+// Symbolic IDs are hierarchical, with levels separated by colons. Components holds each of these levels,
+// from the most generic to the most specific one.
+type SymbolicAllele struct {
+	ID         string
+	Components []string
+
+	// Definition is the ##ALT header definition for this allele. When the exact ID is not declared, the
+	// definition of the most specific declared ancestor is used, so <DEL:ME:ALU> resolves to <DEL:ME>
+	// or <DEL>. Definition is nil when none of them is declared.
+	Definition *AltDefinition
+}
+
+// Type derives the structural variant type from the symbolic ID, using its most specific known level.
+// Copy number alleles such as <CN0> or <CN2> are reported as CopyNumberVariation. For IDs that do not
+// match any structural variant type, nil is returned.
+func (a *SymbolicAllele) Type() *SVType {
+	for i := len(a.Components); i > 0; i-- {
+		id := strings.Join(a.Components[:i], ":")
+		if svType := svTypeFromString(&id); svType != nil {
+			return svType
+		}
+	}
+	if isCopyNumberID(a.Components[0]) {
+		cnv := CopyNumberVariation
+		return &cnv
+	}
+	return nil
+}
+
+// Unspecified tells whether the allele stands for any allele not listed on the record, written as <*> since
+// VCF 4.3 and as <NON_REF> by GATK.
+func (a *SymbolicAllele) Unspecified() bool {
+	return isNonRefAllele("<" + a.ID + ">")
+}
+
+func isCopyNumberID(id string) bool {
+	if !strings.HasPrefix(id, "CN") || len(id) == 2 {
+		return false
+	}
+	_, err := strconv.Atoi(id[2:])
+	return err == nil
+}
+
+func isSymbolicAllele(alt string) bool {
+	return len(alt) > 2 && strings.HasPrefix(alt, "<") && strings.HasSuffix(alt, ">")
+}
+
+func parseSymbolicAllele(alt string, header *Header) *SymbolicAllele {
+	id := alt[1 : len(alt)-1]
+	allele := &SymbolicAllele{
+		ID:         id,
+		Components: strings.Split(id, ":"),
+	}
+	if header != nil {
+		for i := len(allele.Components); i > 0 && allele.Definition == nil; i-- {
+			allele.Definition = header.Alts[strings.Join(allele.Components[:i], ":")]
+		}
+	}
+	return allele
+}
@@ -0,0 +1,76 @@
+package vcf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SymbolicSuite struct {
+	suite.Suite
+}
+
+func (s *SymbolicSuite) TestComponentsAndType() {
+	allele := parseSymbolicAllele("<DEL:ME:ALU>", nil)
+
+	assert.Equal(s.T(), "DEL:ME:ALU", allele.ID)
+	assert.Equal(s.T(), []string{"DEL", "ME", "ALU"}, allele.Components)
+	assert.Nil(s.T(), allele.Definition)
+	assert.Equal(s.T(), DeletionMobileElement, *allele.Type())
+
+	assert.Equal(s.T(), TandemDuplication, *parseSymbolicAllele("<DUP:TANDEM>", nil).Type())
+	assert.Equal(s.T(), InsertionMobileElement, *parseSymbolicAllele("<INS:ME:L1>", nil).Type())
+	assert.Equal(s.T(), CopyNumberVariation, *parseSymbolicAllele("<CN2>", nil).Type())
+	assert.Equal(s.T(), TandemRepeatCopyNumber, *parseSymbolicAllele("<CNV:TR>", nil).Type())
+	assert.Nil(s.T(), parseSymbolicAllele("<NON_REF>", nil).Type())
+	assert.Nil(s.T(), parseSymbolicAllele("<CNX>", nil).Type())
+
+	assert.True(s.T(), parseSymbolicAllele("<*>", nil).Unspecified())
+	assert.True(s.T(), parseSymbolicAllele("<NON_REF>", nil).Unspecified())
+	assert.False(s.T(), parseSymbolicAllele("<DEL>", nil).Unspecified())
+}
+
+func (s *SymbolicSuite) TestDefinitionResolvesToDeclaredAncestor() {
+	header := newHeader()
+	header.addMetaLine(parseMetaLine(`##ALT=<ID=DEL:ME,Description="Deletion of mobile element">`))
+	header.addMetaLine(parseMetaLine(`##ALT=<ID=CN2,Description="Copy number 2">`))
+
+	allele := parseSymbolicAllele("<DEL:ME:ALU>", header)
+	assert.NotNil(s.T(), allele.Definition)
+	assert.Equal(s.T(), "DEL:ME", allele.Definition.ID)
+
+	allele = parseSymbolicAllele("<CN2>", header)
+	assert.Equal(s.T(), "Copy number 2", allele.Definition.Description)
+
+	allele = parseSymbolicAllele("<INV>", header)
+	assert.Nil(s.T(), allele.Definition)
+}
+
+func (s *SymbolicSuite) TestParseVcfLineWithSymbolicAlternatives() {
+	result, err := parseVcfLine("2\t321682\t.\tT\t<del:me:alu>,<DUP:TANDEM>\t6\tPASS\tEND=321887", defaultHeader, Standard, nil)
+
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), result, 2)
+
+	assert.Equal(s.T(), "<del:me:alu>", result[0].Alt, "symbolic ALT should be kept as is")
+	assert.NotNil(s.T(), result[0].Symbolic)
+	assert.Nil(s.T(), result[0].StructuralVariantType, "lower-case IDs do not match any SV type")
+
+	assert.NotNil(s.T(), result[1].StructuralVariantType, "SVTYPE should be derived from the symbolic ALT")
+	assert.Equal(s.T(), TandemDuplication, *result[1].StructuralVariantType)
+
+	fixed := fixRefAltSuffix(&Variant{Ref: "TC", Alt: "<DUP:TANDEM>", Symbolic: result[1].Symbolic})
+	assert.Equal(s.T(), "TC", fixed.Ref, "suffix trimming should not touch symbolic alleles")
+}
+
+func (s *SymbolicSuite) TestInfoSVTypeTakesPrecedence() {
+	result, err := parseVcfLine("2\t321682\t.\tT\t<CN0>\t6\tPASS\tSVTYPE=DEL", defaultHeader, Standard, nil)
+
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), Deletion, *result[0].StructuralVariantType)
+}
+
+func TestSymbolicSuite(t *testing.T) {
+	suite.Run(t, new(SymbolicSuite))
+}
@@ -0,0 +1,307 @@
+package vcf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Tabix indexes BGZF files of tab-separated records sorted by position, such as VCF or BED files, so that the
+// records overlapping a region can be read without scanning the whole file. Records are grouped in the bins of a
+// hierarchical binning scheme, each one holding the chunks of the file, as pairs of virtual offsets, with its
+// records, and a linear index keeps the first record of every 16 KiB window.
+
+const (
+	tabixGeneric = 0
+	tabixVCF     = 2
+
+	// tabixUCSC flags formats whose start column is 0-based
+	tabixUCSC = 0x10000
+
+	tabixMinShift = 14
+
+	// tabixMetaBin is the pseudo-bin htslib uses to store the span and the record count of a reference
+	tabixMetaBin = 37450
+)
+
+type tabixChunk struct {
+	begin uint64
+	end   uint64
+}
+
+type tabixReference struct {
+	bins   map[uint32][]tabixChunk
+	linear []uint64
+}
+
+type tabixIndex struct {
+	format int32
+
+	// the 1-based columns of the sequence name and of the start and end of the records, which has no end column
+	// when colEnd is zero
+	colSeq, colBeg, colEnd int32
+
+	// lines starting with meta, and the first skip lines, are not records
+	meta byte
+	skip int32
+
+	names      []string
+	references []*tabixReference
+}
+
+// tabixBins returns every bin that may hold records overlapping the 0-based half-open region.
+func tabixBins(start, end int) []uint32 {
+	if end <= start {
+		end = start + 1
+	}
+	end--
+	bins := []uint32{0}
+	for _, level := range []struct{ offset, shift int }{{1, 26}, {9, 23}, {73, 20}, {585, 17}, {4681, 14}} {
+		for bin := level.offset + start>>level.shift; bin <= level.offset+end>>level.shift; bin++ {
+			bins = append(bins, uint32(bin))
+		}
+	}
+	return bins
+}
+
+// isMeta tells whether a line is a header line rather than a record.
+func (index *tabixIndex) isMeta(line string, lineNumber int) bool {
+	return lineNumber <= int(index.skip) || line == "" || line[0] == index.meta
+}
+
+// interval reads the sequence name and the 0-based half-open region of a record.
+func (index *tabixIndex) interval(line string) (chrom string, start, end int, err error) {
+	fields := strings.Split(line, "\t")
+	for _, column := range []int32{index.colSeq, index.colBeg, index.colEnd} {
+		if int(column) > len(fields) {
+			return "", 0, 0, fmt.Errorf("expected at least %d columns, found %d", column, len(fields))
+		}
+	}
+	chrom = fields[index.colSeq-1]
+	start, err = strconv.Atoi(fields[index.colBeg-1])
+	if err != nil {
+		return "", 0, 0, errors.New("unable to parse the start of the record: " + fields[index.colBeg-1])
+	}
+	if index.format&tabixUCSC == 0 {
+		start-- // converts 1-based starts
+	}
+	end = start + 1
+	if index.colEnd > 0 {
+		if end, err = strconv.Atoi(fields[index.colEnd-1]); err != nil {
+			return "", 0, 0, errors.New("unable to parse the end of the record: " + fields[index.colEnd-1])
+		}
+	}
+	if index.format&0xffff == tabixVCF && len(fields) > 7 {
+		end = start + len(fields[3])
+		if infoEnd, found := infoToMap(fields[7])["END"].(string); found {
+			if value, err := strconv.Atoi(infoEnd); err == nil && value > start {
+				end = value
+			}
+		}
+	}
+	return chrom, start, end, nil
+}
+
+// reference returns the position of a sequence name on the index, matching names with and without the "chr"
+// prefix, since parsing strips it.
+func (index *tabixIndex) reference(chrom string) (int, bool) {
+	for i, name := range index.names {
+		if name == chrom {
+			return i, true
+		}
+	}
+	for i, name := range index.names {
+		if normalizeChrom(name) == normalizeChrom(chrom) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// chunks returns the sorted and merged chunks that may hold records overlapping the 0-based half-open region.
+func (index *tabixIndex) chunks(chrom string, start, end int) []tabixChunk {
+	id, found := index.reference(chrom)
+	if !found {
+		return nil
+	}
+	reference := index.references[id]
+	var minimum uint64
+	if window := start >> tabixMinShift; window < len(reference.linear) {
+		minimum = reference.linear[window]
+	} else if len(reference.linear) > 0 {
+		minimum = reference.linear[len(reference.linear)-1]
+	}
+
+	var chunks []tabixChunk
+	for _, bin := range tabixBins(start, end) {
+		for _, chunk := range reference.bins[bin] {
+			if chunk.end > minimum {
+				chunks = append(chunks, chunk)
+			}
+		}
+	}
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].begin < chunks[j].begin })
+
+	merged := make([]tabixChunk, 0, len(chunks))
+	for _, chunk := range chunks {
+		if chunk.begin < minimum {
+			chunk.begin = minimum
+		}
+		if last := len(merged) - 1; last >= 0 && chunk.begin <= merged[last].end {
+			if chunk.end > merged[last].end {
+				merged[last].end = chunk.end
+			}
+			continue
+		}
+		merged = append(merged, chunk)
+	}
+	return merged
+}
+
+// query reads the records overlapping the 0-based half-open region.
+func (index *tabixIndex) query(reader *bgzfReader, chrom string, start, end int) ([]string, error) {
+	id, found := index.reference(chrom)
+	if !found {
+		return nil, nil
+	}
+	var records []string
+	for _, chunk := range index.chunks(chrom, start, end) {
+		if err := reader.seek(chunk.begin); err != nil {
+			return nil, err
+		}
+		for reader.virtualOffset() < chunk.end {
+			line, err := reader.readLine()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if line == "" || line[0] == index.meta {
+				continue
+			}
+			recordChrom, recordStart, recordEnd, err := index.interval(line)
+			if err != nil {
+				return nil, err
+			}
+			if recordStart >= end {
+				break
+			}
+			if recordChrom == index.names[id] && recordEnd > start {
+				records = append(records, line)
+			}
+		}
+	}
+	return records, nil
+}
+
+// readTabixIndex reads a .tbi file.
+func readTabixIndex(r io.Reader) (*tabixIndex, error) {
+	var data bytes.Buffer
+	for {
+		block, err := readBGZFBlock(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		data.Write(block.data)
+	}
+	if !bytes.HasPrefix(data.Bytes(), []byte("TBI\x01")) {
+		return nil, errors.New("not a tabix index")
+	}
+	data.Next(4)
+
+	var header struct {
+		References, Format, ColSeq, ColBeg, ColEnd, Meta, Skip, NamesLength int32
+	}
+	if err := binary.Read(&data, binary.LittleEndian, &header); err != nil {
+		return nil, errors.New("truncated tabix index")
+	}
+	index := &tabixIndex{
+		format: header.Format,
+		colSeq: header.ColSeq,
+		colBeg: header.ColBeg,
+		colEnd: header.ColEnd,
+		meta:   byte(header.Meta),
+		skip:   header.Skip,
+	}
+	namesLength, err := tabixCount(header.NamesLength, 1, &data)
+	if err != nil {
+		return nil, err
+	}
+	names := data.Next(namesLength)
+	for _, name := range bytes.Split(bytes.TrimRight(names, "\x00"), []byte{0}) {
+		index.names = append(index.names, string(name))
+	}
+	if len(index.names) != int(header.References) {
+		return nil, errors.New("tabix index with inconsistent sequence names")
+	}
+	references, err := tabixCount(header.References, 8, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < references; i++ {
+		reference := &tabixReference{bins: make(map[uint32][]tabixChunk)}
+		var binCount int32
+		if err := binary.Read(&data, binary.LittleEndian, &binCount); err != nil {
+			return nil, errors.New("truncated tabix index")
+		}
+		bins, err := tabixCount(binCount, 8, &data)
+		if err != nil {
+			return nil, err
+		}
+		for j := 0; j < bins; j++ {
+			var bin struct {
+				ID     uint32
+				Chunks int32
+			}
+			if err := binary.Read(&data, binary.LittleEndian, &bin); err != nil {
+				return nil, errors.New("truncated tabix index")
+			}
+			chunkCount, err := tabixCount(bin.Chunks, 16, &data)
+			if err != nil {
+				return nil, err
+			}
+			offsets := make([]uint64, 2*chunkCount)
+			if err := binary.Read(&data, binary.LittleEndian, offsets); err != nil {
+				return nil, errors.New("truncated tabix index")
+			}
+			chunks := make([]tabixChunk, chunkCount)
+			for k := range chunks {
+				chunks[k] = tabixChunk{begin: offsets[2*k], end: offsets[2*k+1]}
+			}
+			reference.bins[bin.ID] = chunks
+		}
+		var windows int32
+		if err := binary.Read(&data, binary.LittleEndian, &windows); err != nil {
+			return nil, errors.New("truncated tabix index")
+		}
+		intervals, err := tabixCount(windows, 8, &data)
+		if err != nil {
+			return nil, err
+		}
+		reference.linear = make([]uint64, intervals)
+		if err := binary.Read(&data, binary.LittleEndian, reference.linear); err != nil {
+			return nil, errors.New("truncated tabix index")
+		}
+		index.references = append(index.references, reference)
+	}
+	return index, nil
+}
+
+// tabixCount checks a count read from an index against the bytes left to read, given the size of each of its
+// elements, so that a corrupt or truncated index fails instead of allocating without limit.
+func tabixCount(count int32, size int, data *bytes.Buffer) (int, error) {
+	if count < 0 || int64(count)*int64(size) > int64(data.Len()) {
+		return 0, fmt.Errorf("corrupt tabix index: unexpected count %d", count)
+	}
+	return int(count), nil
+}
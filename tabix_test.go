@@ -0,0 +1,124 @@
+package vcf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type TabixSuite struct {
+	suite.Suite
+}
+
+func (s *TabixSuite) index(data []byte, format tabixFormat) *tabixIndex {
+	var encoded bytes.Buffer
+	assert.NoError(s.T(), writeTabixIndex(bytes.NewReader(data), &encoded, format))
+	index, err := readTabixIndex(&encoded)
+	assert.NoError(s.T(), err)
+	return index
+}
+
+func (s *TabixSuite) TestQueryMatchesScan() {
+	random := rand.New(rand.NewSource(1))
+	lines := []string{"##fileformat=VCFv4.2", "#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO"}
+	type record struct {
+		chrom      string
+		start, end int
+	}
+	var records []record
+	for _, chrom := range []string{"chr1", "chr2"} {
+		pos := 0
+		for i := 0; i < 5000; i++ {
+			pos += random.Intn(200) + 1
+			ref, info := "A", "."
+			if i%500 == 0 {
+				info = fmt.Sprintf("END=%d", pos+100000)
+				records = append(records, record{chrom, pos - 1, pos + 100000})
+			} else {
+				ref = strings.Repeat("A", random.Intn(5)+1)
+				records = append(records, record{chrom, pos - 1, pos - 1 + len(ref)})
+			}
+			lines = append(lines, fmt.Sprintf("%s\t%d\t.\t%s\t<DEL>\t.\t.\t%s", chrom, pos, ref, info))
+		}
+	}
+	data := bgzipped(strings.Join(lines, "\n") + "\n")
+	index := s.index(data, tabixFormatVCF)
+	assert.Equal(s.T(), []string{"chr1", "chr2"}, index.names)
+
+	reader := newBGZFReader(bytes.NewReader(data))
+	for _, region := range []record{{"1", 0, 10}, {"chr1", 50000, 51000}, {"2", 250000, 250001}, {"chr2", 999000, 2000000}, {"3", 0, 100}} {
+		expected := 0
+		for _, r := range records {
+			if normalizeChrom(r.chrom) == normalizeChrom(region.chrom) && r.start < region.end && r.end > region.start {
+				expected++
+			}
+		}
+		found, err := index.query(reader, region.chrom, region.start, region.end)
+		assert.NoError(s.T(), err)
+		assert.Len(s.T(), found, expected, "%v", region)
+	}
+}
+
+func (s *TabixSuite) TestBED() {
+	data := bgzipped("#chrom\tstart\tend\tname\nchr1\t100\t200\tA\nchr1\t150\t300\tB\nchr1\t400\t500\tC\n")
+	index := s.index(data, tabixFormatBED)
+
+	found, err := index.query(newBGZFReader(bytes.NewReader(data)), "chr1", 199, 200)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"chr1\t100\t200\tA", "chr1\t150\t300\tB"}, found)
+
+	found, err = index.query(newBGZFReader(bytes.NewReader(data)), "chr1", 300, 400)
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), found, "BED ends are exclusive")
+}
+
+func (s *TabixSuite) TestUnsorted() {
+	var encoded bytes.Buffer
+	err := writeTabixIndex(bytes.NewReader(bgzipped("1\t200\t.\tA\tG\t.\t.\t.\n1\t100\t.\tA\tG\t.\t.\t.\n")), &encoded, tabixFormatVCF)
+	assert.EqualError(s.T(), err, "line 2: records are not sorted by position")
+
+	err = writeTabixIndex(bytes.NewReader(bgzipped("1\t100\t.\tA\tG\t.\t.\t.\n2\t100\t.\tA\tG\t.\t.\t.\n1\t300\t.\tA\tG\t.\t.\t.\n")), &encoded, tabixFormatVCF)
+	assert.EqualError(s.T(), err, "line 3: records of 1 are not contiguous")
+}
+
+func (s *TabixSuite) TestBins() {
+	assert.Equal(s.T(), uint32(4681), tabixBin(0, 1))
+	assert.Equal(s.T(), uint32(585), tabixBin(16383, 16385))
+	assert.Equal(s.T(), uint32(0), tabixBin(0, 1<<29))
+	assert.Contains(s.T(), tabixBins(16383, 16385), uint32(585))
+}
+
+func (s *TabixSuite) TestCorruptIndex() {
+	corrupt := func(counts ...int32) error {
+		var data bytes.Buffer
+		data.WriteString("TBI\x01")
+		binary.Write(&data, binary.LittleEndian, []int32{1, tabixVCF, 1, 2, 0, '#', 0, 2})
+		data.WriteString("1\x00")
+		binary.Write(&data, binary.LittleEndian, counts)
+		var compressed bytes.Buffer
+		assert.NoError(s.T(), writeBGZFBlocks(&compressed, data.Bytes()))
+		_, err := readTabixIndex(&compressed)
+		return err
+	}
+	assert.EqualError(s.T(), corrupt(-1, 0), "corrupt tabix index: unexpected count -1", "negative bin count")
+	assert.EqualError(s.T(), corrupt(1<<30, 0), "corrupt tabix index: unexpected count 1073741824", "huge bin count")
+	assert.EqualError(s.T(), corrupt(1, 4681, -2, 0), "corrupt tabix index: unexpected count -2", "negative chunk count")
+	assert.EqualError(s.T(), corrupt(1, 4681, 1<<28, 0), "corrupt tabix index: unexpected count 268435456", "huge chunk count")
+	assert.EqualError(s.T(), corrupt(0, 1<<20), "corrupt tabix index: unexpected count 1048576", "huge interval count")
+	assert.NoError(s.T(), corrupt(0, 0))
+}
+
+func (s *TabixSuite) TestNotAnIndex() {
+	_, err := readTabixIndex(bytes.NewReader(bgzipped("TBX")))
+	assert.EqualError(s.T(), err, "not a tabix index")
+}
+
+func TestTabixSuite(t *testing.T) {
+	suite.Run(t, new(TabixSuite))
+}
@@ -0,0 +1,180 @@
+package vcf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// The tests build the indexes they read, as tabix does for the .tbi file.
+
+// tabixFormat selects how the columns of the records are read by writeTabixIndex.
+type tabixFormat int
+
+const (
+	// tabixFormatVCF indexes VCF records by CHROM, POS and the length of REF, or END when present.
+	tabixFormatVCF tabixFormat = iota
+	// tabixFormatBED indexes BED records by their first three columns, whose start is 0-based.
+	tabixFormatBED
+)
+
+func newTabixIndex(format tabixFormat) *tabixIndex {
+	if format == tabixFormatBED {
+		return &tabixIndex{format: tabixGeneric | tabixUCSC, colSeq: 1, colBeg: 2, colEnd: 3, meta: '#'}
+	}
+	return &tabixIndex{format: tabixVCF, colSeq: 1, colBeg: 2, colEnd: 0, meta: '#'}
+}
+
+// writeTabixIndex reads a BGZF file of records sorted by position, such as the output of ConcatBGZF, and writes its
+// tabix index to w, as tabix does for the .tbi file.
+func writeTabixIndex(bgzfData io.Reader, w io.Writer, format tabixFormat) error {
+	index := newTabixIndex(format)
+	reader := newBGZFReader(bgzfData)
+	references := make(map[string]int)
+	var builder *tabixBuilder
+	previousBegin := 0
+	for lineNumber := 1; ; lineNumber++ {
+		begin := reader.virtualOffset()
+		line, err := reader.readLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if index.isMeta(line, lineNumber) {
+			continue
+		}
+		chrom, start, end, err := index.interval(line)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+
+		if builder == nil || index.names[len(index.names)-1] != chrom {
+			if _, seen := references[chrom]; seen {
+				return fmt.Errorf("line %d: records of %s are not contiguous", lineNumber, chrom)
+			}
+			if builder != nil {
+				index.references = append(index.references, builder.finish())
+			}
+			references[chrom] = len(index.names)
+			index.names = append(index.names, chrom)
+			builder = newTabixBuilder()
+		} else if start < previousBegin {
+			return fmt.Errorf("line %d: records are not sorted by position", lineNumber)
+		}
+		previousBegin = start
+		builder.add(start, end, begin, reader.virtualOffset())
+	}
+	if builder != nil {
+		index.references = append(index.references, builder.finish())
+	}
+
+	var data bytes.Buffer
+	index.write(&data)
+	if err := writeBGZFBlocks(w, data.Bytes()); err != nil {
+		return err
+	}
+	_, err := w.Write(bgzfEOF)
+	return err
+}
+
+// tabixBuilder collects the bins and the linear index of a reference while its records are read.
+type tabixBuilder struct {
+	reference  *tabixReference
+	first      uint64
+	last       uint64
+	records    uint64
+	hasRecords bool
+}
+
+func newTabixBuilder() *tabixBuilder {
+	return &tabixBuilder{reference: &tabixReference{bins: make(map[uint32][]tabixChunk)}}
+}
+
+// add records a record spanning the 0-based half-open region, stored between the virtual offsets.
+func (b *tabixBuilder) add(start, end int, begin, finish uint64) {
+	bin := tabixBin(start, end)
+	chunks := b.reference.bins[bin]
+	if last := len(chunks) - 1; last >= 0 && (chunks[last].end == begin || chunks[last].end>>16 == begin>>16) {
+		chunks[last].end = finish
+	} else {
+		b.reference.bins[bin] = append(chunks, tabixChunk{begin: begin, end: finish})
+	}
+
+	if end <= start {
+		end = start + 1
+	}
+	for window := start >> tabixMinShift; window <= (end-1)>>tabixMinShift; window++ {
+		for len(b.reference.linear) <= window {
+			b.reference.linear = append(b.reference.linear, 0)
+		}
+		if b.reference.linear[window] == 0 {
+			b.reference.linear[window] = begin
+		}
+	}
+
+	if !b.hasRecords {
+		b.first, b.hasRecords = begin, true
+	}
+	b.last = finish
+	b.records++
+}
+
+func (b *tabixBuilder) finish() *tabixReference {
+	// windows without records point to the previous record, so that queries start early enough
+	for i := 1; i < len(b.reference.linear); i++ {
+		if b.reference.linear[i] == 0 {
+			b.reference.linear[i] = b.reference.linear[i-1]
+		}
+	}
+	b.reference.bins[tabixMetaBin] = []tabixChunk{{begin: b.first, end: b.last}, {begin: b.records, end: 0}}
+	return b.reference
+}
+
+// tabixBin returns the smallest bin that contains the 0-based half-open region.
+func tabixBin(start, end int) uint32 {
+	if end <= start {
+		end = start + 1
+	}
+	end--
+	for _, level := range []struct{ offset, shift int }{{4681, 14}, {585, 17}, {73, 20}, {9, 23}, {1, 26}} {
+		if start>>level.shift == end>>level.shift {
+			return uint32(level.offset + start>>level.shift)
+		}
+	}
+	return 0
+}
+
+// write writes the uncompressed content of the .tbi file. Writes to a bytes.Buffer do not fail.
+func (index *tabixIndex) write(w *bytes.Buffer) {
+	var names bytes.Buffer
+	for _, name := range index.names {
+		names.WriteString(name)
+		names.WriteByte(0)
+	}
+	w.Write([]byte("TBI\x01"))
+	binary.Write(w, binary.LittleEndian, []int32{
+		int32(len(index.names)), index.format, index.colSeq, index.colBeg, index.colEnd, int32(index.meta), index.skip, int32(names.Len()),
+	})
+	w.Write(names.Bytes())
+	for _, reference := range index.references {
+		ids := make([]uint32, 0, len(reference.bins))
+		for id := range reference.bins {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		binary.Write(w, binary.LittleEndian, int32(len(ids)))
+		for _, id := range ids {
+			binary.Write(w, binary.LittleEndian, id)
+			binary.Write(w, binary.LittleEndian, int32(len(reference.bins[id])))
+			for _, chunk := range reference.bins[id] {
+				binary.Write(w, binary.LittleEndian, []uint64{chunk.begin, chunk.end})
+			}
+		}
+		binary.Write(w, binary.LittleEndian, int32(len(reference.linear)))
+		binary.Write(w, binary.LittleEndian, reference.linear)
+	}
+}
@@ -0,0 +1,459 @@
+package vcf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ValidationRule identifies a check performed by Validate.
+type ValidationRule string
+
+const (
+	// RuleFileFormat requires the first line to be a ##fileformat line declaring a VCF version.
+	RuleFileFormat ValidationRule = "fileformat"
+	// RuleHeaderLine requires the #CHROM line, with the fixed columns in order.
+	RuleHeaderLine ValidationRule = "header-line"
+	// RuleColumns requires the eight fixed columns on every record.
+	RuleColumns ValidationRule = "columns"
+	// RulePosition requires POS to be a positive integer.
+	RulePosition ValidationRule = "position"
+	// RuleRefBases requires REF to be made of the bases A, C, G, T and N.
+	RuleRefBases ValidationRule = "ref-bases"
+	// RuleQual requires QUAL to be a number or missing.
+	RuleQual ValidationRule = "qual"
+	// RuleUndefinedFilter requires every FILTER other than PASS to be declared on a ##FILTER line.
+	RuleUndefinedFilter ValidationRule = "undefined-filter"
+	// RuleUndefinedInfo requires every INFO key to be declared on a ##INFO line.
+	RuleUndefinedInfo ValidationRule = "undefined-info"
+	// RuleInfoType requires INFO values to match their declared Type.
+	RuleInfoType ValidationRule = "info-type"
+	// RuleInfoNumber requires INFO values to have the declared Number of entries.
+	RuleInfoNumber ValidationRule = "info-number"
+	// RuleUndefinedFormat requires every FORMAT key to be declared on a ##FORMAT line.
+	RuleUndefinedFormat ValidationRule = "undefined-format"
+	// RuleFormatType requires sample values to match their declared Type.
+	RuleFormatType ValidationRule = "format-type"
+	// RuleFormatNumber requires sample values to have the declared Number of entries.
+	RuleFormatNumber ValidationRule = "format-number"
+	// RuleGenotypeIndex requires GT allele indexes to refer to REF or one of the ALTs.
+	RuleGenotypeIndex ValidationRule = "genotype-index"
+	// RuleSampleFields requires samples to have at most as many fields as the FORMAT column.
+	RuleSampleFields ValidationRule = "sample-fields"
+	// RuleSampleCount requires records to have one column per sample of the #CHROM line.
+	RuleSampleCount ValidationRule = "sample-count"
+	// RuleReservedCharacters rejects white space and separators used where the spec does not allow them.
+	RuleReservedCharacters ValidationRule = "reserved-characters"
+)
+
+// validationRules lists every rule, in the order they are reported.
+var validationRules = []ValidationRule{
+	RuleFileFormat, RuleHeaderLine, RuleColumns, RulePosition, RuleRefBases, RuleQual, RuleUndefinedFilter,
+	RuleUndefinedInfo, RuleInfoType, RuleInfoNumber, RuleUndefinedFormat, RuleFormatType, RuleFormatNumber,
+	RuleGenotypeIndex, RuleSampleFields, RuleSampleCount, RuleReservedCharacters,
+}
+
+// DefaultValidationExamples is the number of examples kept for each rule when none is given.
+const DefaultValidationExamples = 5
+
+// ValidateOptions configures Validate.
+type ValidateOptions struct {
+	// MaxExamples is the number of issues kept as examples for each rule. Zero means DefaultValidationExamples.
+	MaxExamples int
+}
+
+// ValidationIssue is a single violation of a rule.
+type ValidationIssue struct {
+	LineNumber int
+	Message    string
+	Line       string
+}
+
+// RuleSummary holds the violations of a rule: how many were found and the first ones as examples.
+type RuleSummary struct {
+	Rule     ValidationRule
+	Count    int
+	Examples []ValidationIssue
+}
+
+// ValidationReport is the result of Validate.
+type ValidationReport struct {
+	// Records is the number of records checked.
+	Records int
+
+	// Rules holds a summary for each rule that was violated, in the order of validationRules.
+	Rules []*RuleSummary
+}
+
+// Valid tells whether no rule was violated.
+func (r *ValidationReport) Valid() bool {
+	return len(r.Rules) == 0
+}
+
+// String formats the report as text, with one section per violated rule.
+func (r *ValidationReport) String() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%d records checked, %d rules violated\n", r.Records, len(r.Rules))
+	for _, summary := range r.Rules {
+		fmt.Fprintf(&builder, "\n%s: %d issues\n", summary.Rule, summary.Count)
+		for _, issue := range summary.Examples {
+			fmt.Fprintf(&builder, "  line %d: %s\n", issue.LineNumber, issue.Message)
+			if issue.Line != "" {
+				fmt.Fprintf(&builder, "    %s\n", issue.Line)
+			}
+		}
+	}
+	return builder.String()
+}
+
+// Validate checks a VCF against the spec, going further than the parsing done by ToChannel: the header must
+// be well formed and declare every INFO, FORMAT and FILTER used, values must match their declared Type and
+// Number, and genotypes must refer to existing alleles. Each record is checked on its own, so the order of
+// the records is not checked; see the CheckOrder option for that.
+//
+// Violations do not stop the validation and are summarized per rule on the report. The returned error is
+// only set when the reader fails.
+func Validate(reader io.Reader, options ValidateOptions) (*ValidationReport, error) {
+	maxExamples := options.MaxExamples
+	if maxExamples <= 0 {
+		maxExamples = DefaultValidationExamples
+	}
+	validator := &validator{header: newHeader(), summaries: make(map[ValidationRule]*RuleSummary), maxExamples: maxExamples}
+
+	bufferedReader := bufio.NewReaderSize(reader, 100*1024)
+	lineNumber := 0
+	for {
+		line, err := bufferedReader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if line == "" && err == io.EOF {
+			break
+		}
+		lineNumber++
+		validator.line(strings.TrimRight(line, "\r\n"), lineNumber)
+		if err == io.EOF {
+			break
+		}
+	}
+	if validator.header.Columns == nil {
+		validator.report(RuleHeaderLine, lineNumber, "", "#CHROM line not found")
+	}
+
+	return validator.result(), nil
+}
+
+var (
+	refBases = regexp.MustCompile(`^[ACGTNacgtn]+$`)
+	infoKey  = regexp.MustCompile(`^([A-Za-z_][0-9A-Za-z_.]*|1000G)$`)
+)
+
+type validator struct {
+	header      *Header
+	records     int
+	summaries   map[ValidationRule]*RuleSummary
+	maxExamples int
+
+	// first is the first violation found, as reported by ToChannel with the Strict level
+	first *ParseError
+}
+
+// ruleColumns holds the 1-based column checked by the record rules that concern a single column.
+var ruleColumns = map[ValidationRule]int{
+	RulePosition:        2,
+	RuleRefBases:        4,
+	RuleQual:            6,
+	RuleUndefinedFilter: 7,
+	RuleUndefinedInfo:   8,
+	RuleInfoType:        8,
+	RuleInfoNumber:      8,
+	RuleUndefinedFormat: 9,
+}
+
+// strictRecordError runs the record checks of Validate for ToChannel with the Strict level, returning the first
+// violation found on the line, or nil when it conforms.
+func strictRecordError(header *Header, line string) *ParseError {
+	v := &validator{header: header, summaries: make(map[ValidationRule]*RuleSummary), maxExamples: 1}
+	v.record(strings.TrimRight(line, "\r\n"), 0)
+	return v.first
+}
+
+func (v *validator) report(rule ValidationRule, lineNumber int, line, message string) {
+	if v.first == nil {
+		v.first = &ParseError{Kind: SpecViolation, Column: ruleColumns[rule], Message: string(rule) + ": " + message}
+	}
+	summary, found := v.summaries[rule]
+	if !found {
+		summary = &RuleSummary{Rule: rule}
+		v.summaries[rule] = summary
+	}
+	summary.Count++
+	if len(summary.Examples) < v.maxExamples {
+		summary.Examples = append(summary.Examples, ValidationIssue{LineNumber: lineNumber, Message: message, Line: line})
+	}
+}
+
+func (v *validator) result() *ValidationReport {
+	report := &ValidationReport{Records: v.records}
+	for _, rule := range validationRules {
+		if summary, found := v.summaries[rule]; found {
+			report.Rules = append(report.Rules, summary)
+		}
+	}
+	return report
+}
+
+func (v *validator) line(line string, lineNumber int) {
+	switch {
+	case lineNumber == 1 && !strings.HasPrefix(line, "##fileformat=VCFv"):
+		v.report(RuleFileFormat, lineNumber, line, "the first line must be ##fileformat=VCFv4.x")
+		if strings.HasPrefix(line, "#") {
+			v.headerLine(line, lineNumber)
+		} else {
+			v.record(line, lineNumber)
+		}
+	case strings.HasPrefix(line, "#"):
+		v.headerLine(line, lineNumber)
+	case strings.TrimSpace(line) == "":
+	default:
+		v.record(line, lineNumber)
+	}
+}
+
+func (v *validator) headerLine(line string, lineNumber int) {
+	if v.header.Columns != nil {
+		v.report(RuleHeaderLine, lineNumber, line, "header line found after the #CHROM line")
+		return
+	}
+	if strings.HasPrefix(line, "##") {
+		v.header.AddMetaLine(line)
+		return
+	}
+
+	columns := strings.Split(line[1:], "\t")
+	v.header.Columns = columns
+	for i, column := range fixedColumns {
+		if i >= len(columns) || columns[i] != column {
+			v.report(RuleHeaderLine, lineNumber, line, "the #CHROM line must start with the columns "+strings.Join(fixedColumns, ", "))
+			return
+		}
+	}
+	if len(columns) > 8 && columns[8] != "FORMAT" {
+		v.report(RuleHeaderLine, lineNumber, line, "the FORMAT column must come before the samples")
+	}
+}
+
+func (v *validator) record(line string, lineNumber int) {
+	v.records++
+	if v.header.Columns == nil {
+		v.report(RuleHeaderLine, lineNumber, line, "record found before the #CHROM line")
+		v.header.Columns = fixedColumns
+	}
+
+	fields := strings.Split(line, "\t")
+	if len(fields) < 8 {
+		v.report(RuleColumns, lineNumber, line, fmt.Sprintf("expected at least 8 columns, found %d", len(fields)))
+		return
+	}
+	report := func(rule ValidationRule, format string, args ...interface{}) {
+		v.report(rule, lineNumber, line, fmt.Sprintf(format, args...))
+	}
+
+	if strings.ContainsAny(fields[0], " ") {
+		report(RuleReservedCharacters, "white space on CHROM %q", fields[0])
+	}
+	if pos, err := strconv.Atoi(fields[1]); err != nil || pos < 1 {
+		report(RulePosition, "POS must be a positive integer, found %q", fields[1])
+	}
+	if strings.ContainsAny(fields[2], " ;") {
+		report(RuleReservedCharacters, "white space or semicolon on ID %q", fields[2])
+	}
+	if !refBases.MatchString(fields[3]) {
+		report(RuleRefBases, "REF must be made of the bases A, C, G, T and N, found %q", fields[3])
+	}
+	if _, err := strconv.ParseFloat(fields[5], 64); err != nil && fields[5] != "." {
+		report(RuleQual, "QUAL must be a number or '.', found %q", fields[5])
+	}
+	v.filters(fields[6], report)
+
+	alternatives := 0
+	if fields[4] != "." {
+		alternatives = len(strings.Split(fields[4], ","))
+	}
+	v.info(fields[7], alternatives, report)
+
+	samples := v.header.SampleIDs()
+	if len(fields) > 8 {
+		if len(fields)-9 != len(samples) {
+			report(RuleSampleCount, "expected %d samples, found %d", len(samples), len(fields)-9)
+		}
+		v.samples(fields[8], fields[9:], alternatives, report)
+	} else if len(samples) > 0 {
+		report(RuleSampleCount, "expected %d samples, found none", len(samples))
+	}
+}
+
+func (v *validator) filters(filter string, report func(ValidationRule, string, ...interface{})) {
+	if filter == "." || filter == "PASS" {
+		return
+	}
+	for _, id := range strings.Split(filter, ";") {
+		if strings.ContainsAny(id, " ") {
+			report(RuleReservedCharacters, "white space on FILTER %q", id)
+		} else if _, declared := v.header.Filters[id]; !declared {
+			report(RuleUndefinedFilter, "FILTER %s is not declared on the header", id)
+		}
+	}
+}
+
+func (v *validator) info(info string, alternatives int, report func(ValidationRule, string, ...interface{})) {
+	if info == "." {
+		return
+	}
+	for _, entry := range strings.Split(info, ";") {
+		key, value, hasValue := strings.Cut(entry, "=")
+		if !infoKey.MatchString(key) {
+			report(RuleReservedCharacters, "invalid INFO key %q", key)
+			continue
+		}
+		if strings.ContainsAny(value, " =") {
+			report(RuleReservedCharacters, "white space or equals sign on the value of INFO %s", key)
+		}
+		definition, declared := v.header.Infos[key]
+		if !declared {
+			report(RuleUndefinedInfo, "INFO %s is not declared on the header", key)
+			continue
+		}
+		if definition.Type == "Flag" {
+			if hasValue {
+				report(RuleInfoType, "INFO %s is a Flag and must not have a value", key)
+			}
+			continue
+		}
+		if !hasValue {
+			report(RuleInfoType, "INFO %s is a %s and must have a value", key, definition.Type)
+			continue
+		}
+		if message := checkValues(value, definition.Type, definition.Number, alternatives, 2); message != "" {
+			rule := RuleInfoType
+			if strings.HasPrefix(message, "expected") {
+				rule = RuleInfoNumber
+			}
+			report(rule, "INFO %s: %s", key, message)
+		}
+	}
+}
+
+func (v *validator) samples(format string, samples []string, alternatives int, report func(ValidationRule, string, ...interface{})) {
+	keys := strings.Split(format, ":")
+	for _, key := range keys {
+		if _, declared := v.header.Formats[key]; !declared {
+			report(RuleUndefinedFormat, "FORMAT %s is not declared on the header", key)
+		}
+	}
+
+	for i, sample := range samples {
+		name := strconv.Itoa(i + 1)
+		if i < len(v.header.SampleIDs()) {
+			name = v.header.SampleIDs()[i]
+		}
+		values := strings.Split(sample, ":")
+		if len(values) > len(keys) {
+			report(RuleSampleFields, "sample %s has %d fields, but FORMAT has %d", name, len(values), len(keys))
+		}
+		if strings.ContainsAny(sample, " ") {
+			report(RuleReservedCharacters, "white space on sample %s", name)
+		}
+
+		ploidy := 2
+		for j, value := range values {
+			if j >= len(keys) {
+				break
+			}
+			if keys[j] == "GT" {
+				genotype, err := ParseGenotype(value)
+				if err != nil {
+					report(RuleFormatType, "sample %s: invalid genotype %q", name, value)
+					continue
+				}
+				ploidy = len(genotype.Alleles)
+				for _, allele := range genotype.Alleles {
+					if allele > alternatives {
+						report(RuleGenotypeIndex, "sample %s: allele %d of %s exceeds the %d alternatives", name, allele, value, alternatives)
+						break
+					}
+				}
+			}
+		}
+		for j, value := range values {
+			if j >= len(keys) || keys[j] == "GT" {
+				continue
+			}
+			definition, declared := v.header.Formats[keys[j]]
+			if !declared {
+				continue
+			}
+			if message := checkValues(value, definition.Type, definition.Number, alternatives, ploidy); message != "" {
+				rule := RuleFormatType
+				if strings.HasPrefix(message, "expected") {
+					rule = RuleFormatNumber
+				}
+				report(rule, "sample %s, FORMAT %s: %s", name, keys[j], message)
+			}
+		}
+	}
+}
+
+// checkValues checks a comma separated value against a declared Type and Number, returning a description of
+// the problem, or an empty string when it conforms. Messages about the Number start with "expected".
+func checkValues(value, valueType, number string, alternatives, ploidy int) string {
+	if value == "." {
+		return ""
+	}
+	entries := strings.Split(value, ",")
+	if expected, known := expectedValues(number, alternatives, ploidy); known && len(entries) != expected {
+		return fmt.Sprintf("expected %d values for Number=%s, found %d", expected, number, len(entries))
+	}
+	for _, entry := range entries {
+		if entry == "." {
+			continue
+		}
+		var err error
+		switch valueType {
+		case "Integer":
+			_, err = strconv.Atoi(entry)
+		case "Float":
+			_, err = strconv.ParseFloat(entry, 64)
+		case "Character":
+			if len(entry) != 1 {
+				err = strconv.ErrSyntax
+			}
+		}
+		if err != nil {
+			return fmt.Sprintf("%q is not a valid %s", entry, valueType)
+		}
+	}
+	return ""
+}
+
+// expectedValues tells how many values a Number declares for a record with the given alternatives and ploidy.
+func expectedValues(number string, alternatives, ploidy int) (int, bool) {
+	switch number {
+	case "A":
+		return alternatives, true
+	case "R":
+		return alternatives + 1, true
+	case "G":
+		// combinations with repetition of the alleles, taken ploidy at a time
+		genotypes := 1
+		for i := 1; i <= ploidy; i++ {
+			genotypes = genotypes * (alternatives + i) / i
+		}
+		return genotypes, true
+	}
+	count, err := strconv.Atoi(number)
+	return count, err == nil
+}
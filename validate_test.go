@@ -0,0 +1,118 @@
+package vcf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+const validHeader = `##fileformat=VCFv4.2
+##FILTER=<ID=q10,Description="Quality below 10">
+##INFO=<ID=DP,Number=1,Type=Integer,Description="Depth">
+##INFO=<ID=AF,Number=A,Type=Float,Description="Allele frequency">
+##INFO=<ID=DB,Number=0,Type=Flag,Description="dbSNP membership">
+##FORMAT=<ID=GT,Number=1,Type=String,Description="Genotype">
+##FORMAT=<ID=AD,Number=R,Type=Integer,Description="Allelic depths">
+##FORMAT=<ID=PL,Number=G,Type=Integer,Description="Likelihoods">
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	S1	S2
+`
+
+type ValidateSuite struct {
+	suite.Suite
+}
+
+func (s *ValidateSuite) validate(vcf string) *ValidationReport {
+	report, err := Validate(strings.NewReader(vcf), ValidateOptions{})
+	assert.NoError(s.T(), err)
+	return report
+}
+
+func (s *ValidateSuite) rules(report *ValidationReport) map[ValidationRule]int {
+	counts := make(map[ValidationRule]int)
+	for _, summary := range report.Rules {
+		counts[summary.Rule] = summary.Count
+	}
+	return counts
+}
+
+func (s *ValidateSuite) TestValidFile() {
+	report := s.validate(validHeader +
+		"1\t100\trs1\tA\tG,T\t50\tPASS\tDP=10;AF=0.1,0.2;DB\tGT:AD:PL\t0/1:5,5,0:0,10,20,30,40,50\t1|2:0,3,3:.\n" +
+		"1\t200\t.\tC\tT\t.\tq10\t.\tGT:AD\t0/0:10,0\t./.:.\n")
+	assert.True(s.T(), report.Valid(), report.String())
+	assert.Equal(s.T(), 2, report.Records)
+}
+
+func (s *ValidateSuite) TestRecordRules() {
+	report := s.validate(validHeader +
+		"1\t0\t.\tA\tG\tbad\tlowqual\tDP=x;AF=0.1,0.2;XX=1;DB=1\tGT:AD:ZZ\t0/2:5\t0/1:5,5:1:extra\n" +
+		"1\t100\t.\tAXT\tG\t50\tPASS\tDP=1\tGT:PL\t0/1:1,2\n" +
+		"1\t100\ta b\tA\tG\t50\tPASS\tDP=1;1bad=2\tGT\t0/1\n" +
+		"1\t100\t.\tA\tG\n")
+
+	assert.Equal(s.T(), map[ValidationRule]int{
+		RulePosition:           1,
+		RuleQual:               1,
+		RuleUndefinedFilter:    1,
+		RuleInfoType:           2, // DP=x and DB=1
+		RuleInfoNumber:         1, // AF
+		RuleUndefinedInfo:      1, // XX
+		RuleUndefinedFormat:    1, // ZZ
+		RuleGenotypeIndex:      1,
+		RuleFormatNumber:       2, // AD of S1 and PL
+		RuleSampleFields:       1,
+		RuleSampleCount:        2, // second and third records have a single sample
+		RuleRefBases:           1,
+		RuleReservedCharacters: 2, // ID and INFO key
+		RuleColumns:            1,
+	}, s.rules(report))
+	assert.Equal(s.T(), 4, report.Records)
+
+	var genotypeIndex *RuleSummary
+	for _, summary := range report.Rules {
+		if summary.Rule == RuleGenotypeIndex {
+			genotypeIndex = summary
+		}
+	}
+	assert.Equal(s.T(), []ValidationIssue{{
+		LineNumber: 10,
+		Message:    "sample S1: allele 2 of 0/2 exceeds the 1 alternatives",
+		Line:       "1\t0\t.\tA\tG\tbad\tlowqual\tDP=x;AF=0.1,0.2;XX=1;DB=1\tGT:AD:ZZ\t0/2:5\t0/1:5,5:1:extra",
+	}}, genotypeIndex.Examples)
+}
+
+func (s *ValidateSuite) TestHeaderRules() {
+	report := s.validate("##source=lab\n#CHROM\tPOS\tREF\n##INFO=<ID=DP,Number=1,Type=Integer,Description=\"Depth\">\n")
+	assert.Equal(s.T(), map[ValidationRule]int{RuleFileFormat: 1, RuleHeaderLine: 2}, s.rules(report))
+
+	report = s.validate("##fileformat=VCFv4.2\n")
+	assert.Equal(s.T(), map[ValidationRule]int{RuleHeaderLine: 1}, s.rules(report))
+}
+
+func (s *ValidateSuite) TestExamplesAreLimited() {
+	records := strings.Repeat("1\t100\t.\tA\tG\t50\tmissing\t.\tGT\t0/1\t0/1\n", 10)
+	report, err := Validate(strings.NewReader(validHeader+records), ValidateOptions{MaxExamples: 2})
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), report.Rules, 1)
+	assert.Equal(s.T(), 10, report.Rules[0].Count)
+	assert.Len(s.T(), report.Rules[0].Examples, 2)
+	assert.True(s.T(), strings.HasPrefix(report.String(), "10 records checked, 1 rules violated\n\nundefined-filter: 10 issues\n  line 10: FILTER missing is not declared on the header\n"))
+}
+
+func (s *ValidateSuite) TestExpectedValues() {
+	count, known := expectedValues("G", 2, 2)
+	assert.True(s.T(), known)
+	assert.Equal(s.T(), 6, count)
+	count, _ = expectedValues("G", 1, 1)
+	assert.Equal(s.T(), 2, count, "haploid calls have one value per allele")
+	count, _ = expectedValues("G", 1, 3)
+	assert.Equal(s.T(), 4, count)
+	_, known = expectedValues(".", 1, 2)
+	assert.False(s.T(), known)
+}
+
+func TestValidateSuite(t *testing.T) {
+	suite.Run(t, new(ValidateSuite))
+}
@@ -2,10 +2,8 @@ package vcf
 
 import (
 	"bufio"
-	"errors"
 	"fmt"
 	"io"
-	"log"
 	"strconv"
 	"strings"
 )
@@ -23,6 +21,7 @@ const (
 	DeletionMobileElement
 	InsertionMobileElement
 	Breakend
+	TandemRepeatCopyNumber
 )
 
 // Variant is a struct representing the fields specified in the VCF 4.2 spec.
@@ -55,6 +54,9 @@ type Variant struct {
 	// Genotype fields for each sample
 	Samples []map[string]string
 
+	// Format holds the keys of the FORMAT column, in the order they appear on the line.
+	Format []string
+
 	// Optional info fields. These are the reserved fields listed on the VCF 4.2 spec, session 1.4.1, number 8.
 	// The parsing is lenient, if the fields do not conform to the expected type listed here, they will be set to nil.
 	// The fields are meant as helpers for common scenarios, since the generic usage is covered by the Info map.
@@ -83,8 +85,28 @@ type Variant struct {
 	Novel                            *bool
 	StructuralVariantType            *SVType
 	StructuralVariantLength          *int
-	ConfidenceIntervalAroundPosition *int
-	ConfidenceIntervalAroundEnd      *int
+	ConfidenceIntervalAroundPosition *ConfidenceInterval
+	ConfidenceIntervalAroundEnd      *ConfidenceInterval
+	ConfidenceIntervalAroundLength   *ConfidenceInterval
+	HomologyLength                   *int
+	HomologySequence                 *string
+	MateID                           *string
+	Event                            *string
+
+	// StructuralVariantClaim is the SVCLAIM of VCF 4.4: D when the variant is claimed from read depth, J when it is
+	// claimed from the breakend junctions, or DJ for both.
+	StructuralVariantClaim *string
+
+	// Breakend is set when the ALT uses the breakend notation. In that case Alt holds the raw ALT.
+	Breakend *BreakendAllele
+
+	// AlleleIndex is the index of Alt among the alternatives of the line, starting at 1, as used by GT.
+	// Alternatives holds all the alternatives of the line, upper-cased like Alt but never suffix-trimmed.
+	AlleleIndex  int
+	Alternatives []string
+
+	// Symbolic is set when the ALT is a symbolic allele such as <DEL:ME:ALU>. In that case Alt holds the raw ALT.
+	Symbolic *SymbolicAllele
 }
 
 // String provides a representation of the variant key: the fields Chrom, Pos, Ref and Alt
@@ -94,10 +116,19 @@ func (v *Variant) String() string {
 }
 
 // InvalidLine represents a VCF line that could not be parsed.
-// It encapsulates the problematic line with its corresponding error.
+// It encapsulates the problematic line with its corresponding error, which is a *ParseError for lines that
+// do not follow the format, and its location on the file.
 type InvalidLine struct {
 	Line string
 	Err  error
+
+	// LineNumber is the 1-based number of the line, counting the header lines, and Offset the position of its
+	// first byte on the file.
+	LineNumber int
+	Offset     int64
+
+	// Column is the 1-based column that could not be parsed, or zero when the error concerns the whole line.
+	Column int
 }
 
 // ToChannel reads from an io.Reader and puts all variants into an already initialized channel.
@@ -105,12 +136,55 @@ type InvalidLine struct {
 // If any of the two channels are full, ToChannel will block.
 // The consumer must guarantee there is enough buffer space on the channels.
 // Both channels are closed when the reader is fully scanned.
-func ToChannel(reader io.Reader, output chan<- *Variant, invalids chan<- InvalidLine) error {
+// Optional behavior, such as dropping gVCF <NON_REF> alleles, can be enabled through ReaderOptions.
+func ToChannel(reader io.Reader, output chan<- *Variant, invalids chan<- InvalidLine, options ...ReaderOption) error {
+	config := newReaderOptions(options)
 	bufferedReader := bufio.NewReaderSize(reader, 100*1024)
 	header, err := vcfHeader(bufferedReader)
 	if err != nil {
 		return err
 	}
+	var headerErrors []InvalidLine
+	var strictHeader *Header
+	if config.strictness == Strict {
+		headerErrors = strictHeaderErrors(header)
+		// records are checked against the samples of the file, before SelectSamples removes some of them
+		fileHeader := *header
+		strictHeader = &fileHeader
+	}
+	if err := config.selectSamples(header); err != nil {
+		return err
+	}
+	if config.recomputeCounts {
+		addAlleleCountDefinitions(header)
+	}
+	if config.headerHandler != nil {
+		config.headerHandler(header)
+	}
+	var checker *orderChecker
+	if config.checkOrder {
+		checker = newOrderChecker(header)
+	}
+
+	lineNumber, offset := header.lineCount, header.byteCount
+	for _, invalid := range headerErrors {
+		invalids <- invalid
+	}
+
+	// with the Strict level, the first warning of a line makes it invalid
+	var violation *ParseError
+	warn := ignoreWarnings
+	if config.strictness == Strict {
+		warn = func(field, message string) {
+			if violation == nil {
+				violation = &ParseError{Kind: SpecViolation, Column: warningColumn(field), Message: field + ": " + message}
+			}
+		}
+	} else if config.warningHandler != nil {
+		warn = func(field, message string) {
+			config.warningHandler(Warning{LineNumber: lineNumber, Field: field, Message: message})
+		}
+	}
 
 	for {
 		line, readError := bufferedReader.ReadString('\n')
@@ -123,17 +197,48 @@ func ToChannel(reader io.Reader, output chan<- *Variant, invalids chan<- Invalid
 			// If there is an empty line at EOF, end the loop without propagating the error
 			break
 		}
+		lineNumber++
+		lineOffset := offset
+		offset += int64(len(line))
 		if isHeaderLine(line) {
 			continue
 		}
-		variants, err := parseVcfLine(line, header)
+		violation = nil
+		variants, err := parseVcfLine(line, header, config.strictness, warn)
+		if err == nil && violation != nil {
+			variants, err = nil, violation
+		}
+		if err == nil && strictHeader != nil {
+			if violation := strictRecordError(strictHeader, line); violation != nil {
+				variants, err = nil, violation
+			}
+		}
+		if err == nil && checker != nil && len(variants) > 0 {
+			err = checker.check(line, variants[0].Chrom, variants[0].Pos+1, lineNumber)
+			if err != nil {
+				variants = nil
+			}
+		}
 		if variants != nil && err == nil {
 			for _, variant := range variants {
+				if config.dropNonRefAllele && isNonRefAllele(variant.Alt) && !variant.IsReferenceBlock() {
+					continue
+				}
+				if config.recomputeCounts {
+					variant.recomputeAlleleCounts()
+				}
 				fixedVariant := fixRefAltSuffix(variant)
+				if !config.inRegions(fixedVariant) {
+					continue
+				}
 				output <- fixedVariant
 			}
 		} else if err != nil {
-			invalids <- InvalidLine{line, err}
+			invalid := InvalidLine{Line: line, Err: err, LineNumber: lineNumber, Offset: lineOffset}
+			if parseErr, ok := err.(*ParseError); ok {
+				invalid.Column = parseErr.Column
+			}
+			invalids <- invalid
 		}
 		// Check again for a read error. This is only possible on EOF
 		if readError != nil {
@@ -155,24 +260,7 @@ func SampleIDs(reader io.Reader) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	if len(header) > 9 {
-		return header[9:], nil
-	}
-	return nil, nil
-}
-
-func vcfHeader(bufferedReader *bufio.Reader) ([]string, error) {
-	for {
-		line, err := bufferedReader.ReadString('\n')
-		if err != nil {
-			return nil, err
-		}
-		if strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "##") {
-			line = strings.TrimSpace(line)
-			return strings.Split(line[1:], "\t"), nil
-		}
-	}
-	return nil, errors.New("vcf header not found on file")
+	return header.SampleIDs(), nil
 }
 
 func isHeaderLine(line string) bool {
@@ -183,26 +271,44 @@ type vcfLine struct {
 	Chr, Pos, ID, Ref, Alt, Qual, Filter, Info string
 	Format                                     []string
 	Samples                                    []map[string]string
+	// SampleCount is the number of sample columns of the line, including the ones not parsed.
+	SampleCount int
 }
 
-func parseVcfLine(line string, header []string) ([]*Variant, error) {
+// parseVcfLine parses a record into one Variant per alternative, following the strictness level. Problems that
+// do not prevent parsing, such as values that are ignored, are reported to warn.
+func parseVcfLine(line string, header *Header, strictness Strictness, warn warnFunc) ([]*Variant, error) {
+	if warn == nil {
+		warn = ignoreWarnings
+	}
 	line = strings.TrimSpace(line)
-	vcfLine, err := splitVcfFields(line)
+	if columns := strings.Count(line, "\t") + 1; strictness == Lenient && columns >= 5 && columns < 8 {
+		warn("columns", fmt.Sprintf("found %d columns, filling the missing ones with '.'", columns))
+		line += strings.Repeat("\t.", 8-columns)
+	}
+	columns, total := header.sampleColumns()
+	vcfLine, err := splitVcfFields(line, columns, strictness, warn)
 	if err != nil {
-		return nil, errors.New("unable to parse apparently misformatted VCF line: " + line)
+		return nil, err
+	}
+	if header != nil && header.Columns != nil && vcfLine.SampleCount != total {
+		message := fmt.Sprintf("expected %d samples as declared on the #CHROM line, found %d", total, vcfLine.SampleCount)
+		if strictness == Strict {
+			return nil, &ParseError{Kind: WrongColumnCount, Message: message}
+		}
+		warn("samples", message)
 	}
 
 	baseVariant := Variant{}
 
-	if strings.Contains(vcfLine.Chr, "chr") {
-		baseVariant.Chrom = strings.Replace(vcfLine.Chr, "chr", "", -1)
-	} else {
-		baseVariant.Chrom = vcfLine.Chr
+	baseVariant.Chrom = normalizeChrom(vcfLine.Chr)
+	pos, err := strconv.Atoi(vcfLine.Pos)
+	if err != nil {
+		return nil, &ParseError{Kind: InvalidPosition, Column: 2, Value: vcfLine.Pos, Message: "unable to parse POS as integer: " + vcfLine.Pos}
 	}
-	pos, _ := strconv.Atoi(vcfLine.Pos)
 	baseVariant.Pos = pos - 1 // converts variant to 0-based
 	baseVariant.Ref = strings.ToUpper(vcfLine.Ref)
-	baseVariant.Alt = strings.ToUpper(strings.Replace(vcfLine.Alt, ".", "", -1))
+	baseVariant.Alt = vcfLine.Alt
 
 	baseVariant.ID = vcfLine.ID
 	floatQuality, err := strconv.ParseFloat(vcfLine.Qual, 64)
@@ -212,18 +318,31 @@ func parseVcfLine(line string, header []string) ([]*Variant, error) {
 		baseVariant.Qual = nil
 	} else {
 		baseVariant.Qual = nil
-		log.Println("unable to parse quality as float, setting as nil")
+		warn("QUAL", "unable to parse quality as float, setting as nil")
 	}
 	baseVariant.Filter = vcfLine.Filter
 	baseVariant.Samples = vcfLine.Samples
+	baseVariant.Format = vcfLine.Format
 	baseVariant.Info = infoToMap(vcfLine.Info)
 
 	alternatives := strings.Split(baseVariant.Alt, ",")
 
-	info := splitMultipleAltInfos(baseVariant.Info, len(alternatives))
+	info := splitMultipleAltInfos(baseVariant.Info, len(alternatives), header, warn)
+	if header.atLeast(4, 3) {
+		// values are decoded once split, so that encoded commas do not separate alternatives
+		for _, altinfo := range info {
+			decodeInfoValues(altinfo)
+		}
+		decodeSampleValues(baseVariant.Samples)
+	}
+
+	normalizedAlternatives := make([]string, len(alternatives))
+	for i, rawAlternative := range alternatives {
+		normalizedAlternatives[i] = normalizeAlternative(rawAlternative)
+	}
 
 	result := make([]*Variant, 0, 64)
-	for i, alternative := range alternatives {
+	for i, rawAlternative := range alternatives {
 		var altinfo map[string]interface{}
 		if i >= len(info) {
 			altinfo = info[0]
@@ -231,31 +350,60 @@ func parseVcfLine(line string, header []string) ([]*Variant, error) {
 			altinfo = info[i]
 		}
 
+		var breakend *BreakendAllele
+		var symbolic *SymbolicAllele
+		if isSymbolicAllele(rawAlternative) {
+			symbolic = parseSymbolicAllele(rawAlternative, header)
+		} else if isBreakendAllele(rawAlternative) {
+			breakend, err = parseBreakendAllele(rawAlternative, baseVariant.Ref)
+			if err != nil && strictness == Lenient {
+				warn("ALT", err.Error()+", keeping it as a raw ALT")
+			} else if err != nil {
+				return nil, &ParseError{Kind: InvalidBreakend, Column: 5, Value: vcfLine.Alt, Message: err.Error()}
+			}
+		}
+
 		variant := &Variant{
-			Chrom:   baseVariant.Chrom,
-			Pos:     baseVariant.Pos,
-			Ref:     baseVariant.Ref,
-			Alt:     alternative,
-			ID:      baseVariant.ID,
-			Samples: baseVariant.Samples,
-			Info:    altinfo,
-			Qual:    baseVariant.Qual,
-			Filter:  baseVariant.Filter,
+			Chrom:        baseVariant.Chrom,
+			Pos:          baseVariant.Pos,
+			Ref:          baseVariant.Ref,
+			Alt:          normalizedAlternatives[i],
+			AlleleIndex:  i + 1,
+			Alternatives: normalizedAlternatives,
+			Breakend:     breakend,
+			Symbolic:     symbolic,
+			ID:           baseVariant.ID,
+			Samples:      baseVariant.Samples,
+			Format:       baseVariant.Format,
+			Info:         altinfo,
+			Qual:         baseVariant.Qual,
+			Filter:       baseVariant.Filter,
+		}
+		buildInfoSubFields(variant, warn)
+		if header.atLeast(4, 4) {
+			applySVLength(variant, warn)
 		}
-		buildInfoSubFields(variant)
 
 		result = append(result, variant)
 	}
 	return result, nil
 }
 
-func splitVcfFields(line string) (ret *vcfLine, err error) {
+// splitVcfFields splits a record into its columns, parsing the samples at the given columns only, or all of them
+// when columns is nil. Selected samples missing from the line are reported with every value missing.
+func splitVcfFields(line string, columns []int, strictness Strictness, warn warnFunc) (ret *vcfLine, err error) {
+	if warn == nil {
+		warn = ignoreWarnings
+	}
 	line = strings.TrimSpace(line)
 
 	fields := strings.Split(line, "\t")
 
 	if len(fields) < 8 {
-		return nil, errors.New("wrong amount of columns: " + string(len(fields)))
+		return nil, &ParseError{
+			Kind:    WrongColumnCount,
+			Message: fmt.Sprintf("unable to parse apparently misformatted VCF line, expected at least 8 columns and found %d", len(fields)),
+		}
 	}
 	ret = &vcfLine{}
 
@@ -270,26 +418,83 @@ func splitVcfFields(line string) (ret *vcfLine, err error) {
 
 	if len(fields) > 8 {
 		samples := fields[9:len(fields)]
-		ret.Samples = make([]map[string]string, len(fields)-9)
+		ret.SampleCount = len(samples)
 		ret.Format = strings.Split(fields[8], ":")
-		for i, sample := range samples {
-			ret.Samples[i] = parseSample(ret.Format, sample)
+		if columns == nil {
+			ret.Samples = make([]map[string]string, len(samples))
+			for i, sample := range samples {
+				ret.Samples[i], err = parseSample(ret.Format, sample, i, strictness, warn)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return
+		}
+		ret.Samples = make([]map[string]string, len(columns))
+		for i, column := range columns {
+			sample := "."
+			if column < len(samples) {
+				sample = samples[column]
+			}
+			ret.Samples[i], err = parseSample(ret.Format, sample, column, strictness, warn)
+			if err != nil {
+				return nil, err
+			}
 		}
 	}
 
 	return
 }
 
-func parseSample(format []string, unparsedSample string) map[string]string {
-	sampleMapping := make(map[string]string)
+// normalizeAlternative upper-cases sequence alternatives and removes their dots. Symbolic and breakend
+// alternatives are kept as they are, since they are not sequences.
+func normalizeAlternative(alt string) string {
+	if isSymbolicAllele(alt) || isBreakendAllele(alt) {
+		return alt
+	}
+	return strings.ToUpper(strings.Replace(alt, ".", "", -1))
+}
+
+// normalizeChrom strips the "chr" prefix from chromosome names, so that "chr1" and "1" are reported the same way.
+func normalizeChrom(chrom string) string {
+	if strings.Contains(chrom, "chr") {
+		return strings.Replace(chrom, "chr", "", -1)
+	}
+	return chrom
+}
+
+// parseSample maps the values of a sample column to the FORMAT keys. Trailing values dropped from the column,
+// as the spec allows, are set as missing, and so is every value of a column that is just '.'. Values without a
+// corresponding key are an error, except with the Lenient level, where they are ignored.
+func parseSample(format []string, unparsedSample string, index int, strictness Strictness, warn warnFunc) (map[string]string, error) {
+	sampleMapping := make(map[string]string, len(format))
 	sampleFields := strings.Split(unparsedSample, ":")
-	for i, field := range sampleFields {
-		sampleMapping[format[i]] = field
+	if unparsedSample == "." {
+		sampleFields = nil
+	}
+	if len(sampleFields) > len(format) {
+		message := fmt.Sprintf("%d values for %d FORMAT keys", len(sampleFields), len(format))
+		if strictness != Lenient {
+			return nil, &ParseError{Kind: ExtraSampleFields, Column: 10 + index, Value: unparsedSample, Message: fmt.Sprintf("sample %d has %s", index+1, message)}
+		}
+		warn(fmt.Sprintf("sample %d", index+1), message+", ignoring the extra ones")
+		sampleFields = sampleFields[:len(format)]
 	}
-	return sampleMapping
+	for i, key := range format {
+		if i < len(sampleFields) {
+			sampleMapping[key] = sampleFields[i]
+		} else {
+			sampleMapping[key] = "."
+		}
+	}
+	return sampleMapping, nil
 }
 
 func fixRefAltSuffix(variant *Variant) *Variant {
+	if variant.Breakend != nil || variant.Symbolic != nil {
+		// breakend and symbolic ALTs are not sequences, trimming them would corrupt the notation
+		return variant
+	}
 	ref := variant.Ref
 	alt := variant.Alt
 	i := len(ref) - 1
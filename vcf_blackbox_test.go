@@ -55,6 +55,39 @@ A	B	C	D	E	F`
 	assert.False(s.T(), hasMore, fmt.Sprintf("More than %d variants came out of the invalid channel, it should be closed", totalLines))
 }
 
+func (s *ChannelSuite) TestInvalidLineLocation() {
+	vcfLines := "##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n" +
+		"1\t100\t.\tA\tG\t.\t.\t.\n" +
+		"1\tabc\t.\tA\tG\t.\t.\t.\n" +
+		"1\t300\t.\tA\tG[1:x[\t.\t.\t.\n" +
+		"1\t400\n"
+
+	err := vcf.ToChannel(strings.NewReader(vcfLines), s.outChannel, s.invalidChannel)
+	assert.NoError(s.T(), err)
+
+	expected := []struct {
+		lineNumber int
+		offset     int64
+		column     int
+		kind       vcf.ParseErrorKind
+	}{
+		{4, 78, 2, vcf.InvalidPosition},
+		{5, 96, 5, vcf.InvalidBreakend},
+		{6, 119, 0, vcf.WrongColumnCount},
+	}
+	for _, e := range expected {
+		invalid := <-s.invalidChannel
+		assert.Equal(s.T(), e.lineNumber, invalid.LineNumber)
+		assert.Equal(s.T(), e.offset, invalid.Offset)
+		assert.Equal(s.T(), e.column, invalid.Column)
+		parseErr, ok := invalid.Err.(*vcf.ParseError)
+		assert.True(s.T(), ok, "error should be a *ParseError")
+		assert.Equal(s.T(), e.kind, parseErr.Kind)
+		assert.Equal(s.T(), e.lineNumber, strings.Count(vcfLines[:invalid.Offset], "\n")+1, "offset should point to the start of the line")
+	}
+	assert.Equal(s.T(), "InvalidBreakend", vcf.InvalidBreakend.String())
+}
+
 func (s *ChannelSuite) TestToChannel() {
 	vcfLine := `#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	185423
 1	847491	rs28407778	GTTTA	G....	745.77	PASS	AC=1;AF=0.500;AN=2;BaseQRankSum=0.842;ClippingRankSum=0.147;DB;DP=41;FS=0.000;MLEAC=1;MLEAF=0.500;MQ=60.00;MQ0=0;MQRankSum=-1.109;QD=18.19;ReadPosRankSum=0.334;VQSLOD=2.70;culprit=FS;set=variant	GT:AD:DP:GQ:PL	0/1:16,25:41:99:774,0,434`
@@ -342,6 +375,32 @@ func (s *InfoSuite) TestMultiple() {
 	assert.False(s.T(), hasMore, "No variant should come out of invalid channel, it should be closed")
 }
 
+func (s *InfoSuite) TestFlagsOnEveryAlternative() {
+	vcfLine := `#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO
+1	100	.	C	A,T,G	50	PASS	DB;H2;DP=20;AC=1,2,3`
+	ioreader := strings.NewReader(vcfLine)
+
+	err := vcf.ToChannel(ioreader, s.outChannel, s.invalidChannel)
+	assert.NoError(s.T(), err, "Valid VCF line should not return error")
+
+	for i, alt := range []string{"A", "T", "G"} {
+		variant := <-s.outChannel
+		assert.NotNil(s.T(), variant, "One variant per alternative should come out of channel")
+		assert.Equal(s.T(), alt, variant.Alt)
+		assert.Equal(s.T(), true, variant.Info["DB"], "flags are set on every alternative")
+		assert.Equal(s.T(), true, variant.Info["H2"], "flags are set on every alternative")
+		assert.True(s.T(), *variant.InDBSNP)
+		assert.True(s.T(), *variant.InHapmap2)
+		assert.Equal(s.T(), "20", variant.Info["DP"])
+		assert.Equal(s.T(), i+1, *variant.AlleleCount)
+	}
+
+	_, hasMore := <-s.outChannel
+	assert.False(s.T(), hasMore, "No fourth variant should come out of the channel, it should be closed")
+	_, hasMore = <-s.invalidChannel
+	assert.False(s.T(), hasMore, "No variant should come out of invalid channel, it should be closed")
+}
+
 func TestInfoSuite(t *testing.T) {
 	suite.Run(t, new(InfoSuite))
 }
@@ -624,7 +683,7 @@ func (s *StructuralSuite) TestSVType() {
 
 func (s *StructuralSuite) TestStructuralVariantInts() {
 	vcfLine := `#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	185423
-1	847491	CNVR8241.1	G	A	745.77	PASS	SVTYPE=DUP;SVLEN=337;CIPOS=10;CIEND=7	GT	0/1`
+1	847491	CNVR8241.1	G	A	745.77	PASS	SVTYPE=DUP;SVLEN=337;CIPOS=-10,10;CIEND=-7,5	GT	0/1`
 	ioreader := strings.NewReader(vcfLine)
 
 	err := vcf.ToChannel(ioreader, s.outChannel, s.invalidChannel)
@@ -645,9 +704,9 @@ func (s *StructuralSuite) TestStructuralVariantInts() {
 	assert.NotNil(s.T(), variant.StructuralVariantLength)
 	assert.Equal(s.T(), *variant.StructuralVariantLength, 337)
 	assert.NotNil(s.T(), variant.ConfidenceIntervalAroundPosition)
-	assert.Equal(s.T(), *variant.ConfidenceIntervalAroundPosition, 10)
+	assert.Equal(s.T(), *variant.ConfidenceIntervalAroundPosition, vcf.ConfidenceInterval{Lower: -10, Upper: 10})
 	assert.NotNil(s.T(), variant.ConfidenceIntervalAroundEnd)
-	assert.Equal(s.T(), *variant.ConfidenceIntervalAroundEnd, 7)
+	assert.Equal(s.T(), *variant.ConfidenceIntervalAroundEnd, vcf.ConfidenceInterval{Lower: -7, Upper: 5})
 
 	_, hasMore := <-s.outChannel
 	assert.False(s.T(), hasMore, "No second variant should come out of the channel, it should be closed")
@@ -11,34 +11,34 @@ type ParseVcfLineSuite struct {
 	suite.Suite
 }
 
-var defaultHeader = []string{"CHROM", "POS", "ID", "REF", "ALT", "QUAL", "FILTER", "INFO"}
+var defaultHeader = &Header{Columns: []string{"CHROM", "POS", "ID", "REF", "ALT", "QUAL", "FILTER", "INFO"}}
 
 func (s *ParseVcfLineSuite) TestBlankLineShouldReturnError() {
-	result, err := parseVcfLine("\t ", defaultHeader)
+	result, err := parseVcfLine("\t ", defaultHeader, Standard, nil)
 	assert.Error(s.T(), err, "Line with only blanks should return empty and an error")
 	assert.Empty(s.T(), result, "Line with only blanks should return emptyand an error")
 }
 
 func (s *ParseVcfLineSuite) TestContinuousLineShouldReturnError() {
-	result, err := parseVcfLine("Lorem ipsum dolor sit amet, consectetur adipiscing elit. Nunc tellus ligula, faucibus sed nibh sed, fringilla viverra enim.", defaultHeader)
+	result, err := parseVcfLine("Lorem ipsum dolor sit amet, consectetur adipiscing elit. Nunc tellus ligula, faucibus sed nibh sed, fringilla viverra enim.", defaultHeader, Standard, nil)
 	assert.Error(s.T(), err, "Line with continuous text should return empty and an error")
 	assert.Empty(s.T(), result, "Line with continuous text should return empty and an error")
 }
 
 func (s *ParseVcfLineSuite) TestEmptyFieldedLineShouldReturnError() {
-	result, err := parseVcfLine("\t\t\t\t\t", defaultHeader)
+	result, err := parseVcfLine("\t\t\t\t\t", defaultHeader, Standard, nil)
 	assert.Error(s.T(), err, "Line with empty fields should return empty and an error")
 	assert.Empty(s.T(), result, "Line with empty fields should return empty and an error")
 }
 
 func (s *ParseVcfLineSuite) TestWrongFormattedFieldedLineShouldReturnError() {
-	result, err := parseVcfLine("A\tB\tC\tD\tE\tF", defaultHeader)
+	result, err := parseVcfLine("A\tB\tC\tD\tE\tF", defaultHeader, Standard, nil)
 	assert.Error(s.T(), err, "Line with wrong formatted fields should return empty and an error")
 	assert.Empty(s.T(), result, "Line with wrong formatted fields should return empty and an error")
 }
 
 func (s *ParseVcfLineSuite) TestValidLineShouldReturnOneElementAndNoErrors() {
-	result, err := parseVcfLine("1\t847491\trs28407778\tGT\tA\t745.77\tPASS\tAC=1;AF=0.500;AN=2;BaseQRankSum=0.842;ClippingRankSum=0.147;DB;DP=41;FS=0.000;MLEAC=1;MLEAF=0.500;MQ=60.00;MQ0=0;MQRankSum=-1.109;QD=18.19;ReadPosRankSum=0.334;VQSLOD=2.70;culprit=FS;set=variant\tGT:AD:DP:GQ:PL\t0/1:16,25:41:99:774,0,434", defaultHeader)
+	result, err := parseVcfLine("1\t847491\trs28407778\tGT\tA\t745.77\tPASS\tAC=1;AF=0.500;AN=2;BaseQRankSum=0.842;ClippingRankSum=0.147;DB;DP=41;FS=0.000;MLEAC=1;MLEAF=0.500;MQ=60.00;MQ0=0;MQRankSum=-1.109;QD=18.19;ReadPosRankSum=0.334;VQSLOD=2.70;culprit=FS;set=variant\tGT:AD:DP:GQ:PL\t0/1:16,25:41:99:774,0,434", defaultHeader, Standard, nil)
 
 	assert.NoError(s.T(), err, "Valid VCF line should not return error")
 	assert.NotNil(s.T(), result, "Valid VCF line should not return nil")
@@ -50,7 +50,7 @@ func (s *ParseVcfLineSuite) TestValidLineShouldReturnOneElementAndNoErrors() {
 }
 
 func (s *ParseVcfLineSuite) TestValidLineWithChrShouldStripIt() {
-	result, err := parseVcfLine("chr1\t847491\trs28407778\tGT\tA\t745.77\tPASS\tAC=1;AF=0.500;AN=2;BaseQRankSum=0.842;ClippingRankSum=0.147;DB;DP=41;FS=0.000;MLEAC=1;MLEAF=0.500;MQ=60.00;MQ0=0;MQRankSum=-1.109;QD=18.19;ReadPosRankSum=0.334;VQSLOD=2.70;culprit=FS;set=variant\tGT:AD:DP:GQ:PL\t0/1:16,25:41:99:774,0,434", defaultHeader)
+	result, err := parseVcfLine("chr1\t847491\trs28407778\tGT\tA\t745.77\tPASS\tAC=1;AF=0.500;AN=2;BaseQRankSum=0.842;ClippingRankSum=0.147;DB;DP=41;FS=0.000;MLEAC=1;MLEAF=0.500;MQ=60.00;MQ0=0;MQRankSum=-1.109;QD=18.19;ReadPosRankSum=0.334;VQSLOD=2.70;culprit=FS;set=variant\tGT:AD:DP:GQ:PL\t0/1:16,25:41:99:774,0,434", defaultHeader, Standard, nil)
 
 	assert.NoError(s.T(), err, "Valid VCF line should not return error")
 	assert.NotNil(s.T(), result, "Valid VCF line should not return nil")
@@ -62,7 +62,7 @@ func (s *ParseVcfLineSuite) TestValidLineWithChrShouldStripIt() {
 }
 
 func (s *ParseVcfLineSuite) TestValidLineWithLowercaseRefAndAltShouldReturnOneElementAndNoErrors() {
-	result, err := parseVcfLine("1\t847491\trs28407778\tgt\ta\t745.77\tPASS\tAC=1;AF=0.500;AN=2;BaseQRankSum=0.842;ClippingRankSum=0.147;DB;DP=41;FS=0.000;MLEAC=1;MLEAF=0.500;MQ=60.00;MQ0=0;MQRankSum=-1.109;QD=18.19;ReadPosRankSum=0.334;VQSLOD=2.70;culprit=FS;set=variant\tGT:AD:DP:GQ:PL\t0/1:16,25:41:99:774,0,434", defaultHeader)
+	result, err := parseVcfLine("1\t847491\trs28407778\tgt\ta\t745.77\tPASS\tAC=1;AF=0.500;AN=2;BaseQRankSum=0.842;ClippingRankSum=0.147;DB;DP=41;FS=0.000;MLEAC=1;MLEAF=0.500;MQ=60.00;MQ0=0;MQRankSum=-1.109;QD=18.19;ReadPosRankSum=0.334;VQSLOD=2.70;culprit=FS;set=variant\tGT:AD:DP:GQ:PL\t0/1:16,25:41:99:774,0,434", defaultHeader, Standard, nil)
 
 	assert.NoError(s.T(), err, "Valid VCF line should not return error")
 	assert.NotNil(s.T(), result, "Valid VCF line should not return nil")
@@ -74,7 +74,7 @@ func (s *ParseVcfLineSuite) TestValidLineWithLowercaseRefAndAltShouldReturnOneEl
 }
 
 func (s *ParseVcfLineSuite) TestDotsShouldBeRemovedFromValidLineAlternative() {
-	result, err := parseVcfLine("1\t847491\trs28407778\tGTTTA\tG....\t745.77\tPASS\tAC=1;AF=0.500;AN=2;BaseQRankSum=0.842;ClippingRankSum=0.147;DB;DP=41;FS=0.000;MLEAC=1;MLEAF=0.500;MQ=60.00;MQ0=0;MQRankSum=-1.109;QD=18.19;ReadPosRankSum=0.334;VQSLOD=2.70;culprit=FS;set=variant\tGT:AD:DP:GQ:PL\t0/1:16,25:41:99:774,0,434", defaultHeader)
+	result, err := parseVcfLine("1\t847491\trs28407778\tGTTTA\tG....\t745.77\tPASS\tAC=1;AF=0.500;AN=2;BaseQRankSum=0.842;ClippingRankSum=0.147;DB;DP=41;FS=0.000;MLEAC=1;MLEAF=0.500;MQ=60.00;MQ0=0;MQRankSum=-1.109;QD=18.19;ReadPosRankSum=0.334;VQSLOD=2.70;culprit=FS;set=variant\tGT:AD:DP:GQ:PL\t0/1:16,25:41:99:774,0,434", defaultHeader, Standard, nil)
 
 	assert.NoError(s.T(), err, "Valid VCF line should not return error")
 	assert.NotNil(s.T(), result, "Valid VCF line should not return nil")
@@ -86,7 +86,7 @@ func (s *ParseVcfLineSuite) TestDotsShouldBeRemovedFromValidLineAlternative() {
 }
 
 func (s *ParseVcfLineSuite) TestValidLineWithMultipleAlternativesShouldReturnThreeElementsAndNoErrors() {
-	result, err := parseVcfLine("1\t847491\trs28407778\tGT\tA,C,G\t745.77\tPASS\tAC=1;AF=0.300,0.300,0.400;AN=2;BaseQRankSum=0.842;ClippingRankSum=0.147;DB;DP=41;FS=0.000;MLEAC=1;MLEAF=0.500;MQ=60.00;MQ0=0;MQRankSum=-1.109;QD=18.19;ReadPosRankSum=0.334;VQSLOD=2.70;culprit=FS;set=variant\tGT:AD:DP:GQ:PL\t0/1:16,25:41:99:774,0,434", defaultHeader)
+	result, err := parseVcfLine("1\t847491\trs28407778\tGT\tA,C,G\t745.77\tPASS\tAC=1;AF=0.300,0.300,0.400;AN=2;BaseQRankSum=0.842;ClippingRankSum=0.147;DB;DP=41;FS=0.000;MLEAC=1;MLEAF=0.500;MQ=60.00;MQ0=0;MQRankSum=-1.109;QD=18.19;ReadPosRankSum=0.334;VQSLOD=2.70;culprit=FS;set=variant\tGT:AD:DP:GQ:PL\t0/1:16,25:41:99:774,0,434", defaultHeader, Standard, nil)
 
 	assert.NoError(s.T(), err, "Valid VCF line should not return error")
 	assert.NotNil(s.T(), result, "Valid VCF line should not return nil")
@@ -109,7 +109,7 @@ func (s *ParseVcfLineSuite) TestValidLineWithMultipleAlternativesShouldReturnThr
 }
 
 func (s *ParseVcfLineSuite) TestValidLineWithSampleGenotypeFields() {
-	result, err := parseVcfLine("1\t847491\trs28407778\tGTTTA\tG....\t745.77\tPASS\tAC=1;AF=0.500;AN=2;BaseQRankSum=0.842;ClippingRankSum=0.147;DB;DP=41;FS=0.000;MLEAC=1;MLEAF=0.500;MQ=60.00;MQ0=0;MQRankSum=-1.109;QD=18.19;ReadPosRankSum=0.334;VQSLOD=2.70;culprit=FS;set=variant\tGT:AD:DP:GQ:PL\t0/1:16,25:41:99:774,0,434", defaultHeader)
+	result, err := parseVcfLine("1\t847491\trs28407778\tGTTTA\tG....\t745.77\tPASS\tAC=1;AF=0.500;AN=2;BaseQRankSum=0.842;ClippingRankSum=0.147;DB;DP=41;FS=0.000;MLEAC=1;MLEAF=0.500;MQ=60.00;MQ0=0;MQRankSum=-1.109;QD=18.19;ReadPosRankSum=0.334;VQSLOD=2.70;culprit=FS;set=variant\tGT:AD:DP:GQ:PL\t0/1:16,25:41:99:774,0,434", defaultHeader, Standard, nil)
 
 	assert.NoError(s.T(), err, "Valid VCF line should not return error")
 	assert.NotNil(s.T(), result, "Valid VCF line should not return nil")
@@ -144,7 +144,7 @@ func (s *ParseVcfLineSuite) TestValidLineWithSampleGenotypeFields() {
 }
 
 func (s *ParseVcfLineSuite) TestInfoFields() {
-	result, err := parseVcfLine("1\t847491\trs28407778\tG\tA,C\t745.77\tPASS\tAC=1,2;AF=0.500,0.335;AN=2;BQ=30.00;BaseQRankSum=0.842;ClippingRankSum=0.147;DB;DP=41;FS=0.000;NS=27;H2;H3;SOMATIC;VALIDATED;1000G;MLEAC=1;MLEAF=0.500;END=847492;MQ=60.00;MQ0=0;SB=0.127;MQRankSum=-1.109;QD=18.19;ReadPosRankSum=0.334;VQSLOD=2.70;CIGAR=a;culprit=FS;set=variant\tGT:AD:DP:GQ:PL\t0/1:16,25:41:99:774,0,434", defaultHeader)
+	result, err := parseVcfLine("1\t847491\trs28407778\tG\tA,C\t745.77\tPASS\tAC=1,2;AF=0.500,0.335;AN=2;BQ=30.00;BaseQRankSum=0.842;ClippingRankSum=0.147;DB;DP=41;FS=0.000;NS=27;H2;H3;SOMATIC;VALIDATED;1000G;MLEAC=1;MLEAF=0.500;END=847492;MQ=60.00;MQ0=0;SB=0.127;MQRankSum=-1.109;QD=18.19;ReadPosRankSum=0.334;VQSLOD=2.70;CIGAR=a;culprit=FS;set=variant\tGT:AD:DP:GQ:PL\t0/1:16,25:41:99:774,0,434", defaultHeader, Standard, nil)
 
 	assert.NoError(s.T(), err, "Valid VCF line should not return error")
 	assert.NotNil(s.T(), result, "Valid VCF line should not return nil")
@@ -258,7 +258,7 @@ func (s *ParseVcfLineSuite) TestInfoFields() {
 }
 
 func (s *ParseVcfLineSuite) TestInfoWithoutFormat() {
-	result, err := parseVcfLine("1\t847491\trs28407778\tG\tA,C\t745.77\tPASS\tAC=1,2;AF=0.500,0.335;AN=2;BQ=30.00;BaseQRankSum=0.842;ClippingRankSum=0.147;DB;DP=41;FS=0.000;NS=27;H2;H3;SOMATIC;VALIDATED;1000G;MLEAC=1;MLEAF=0.500;END=847492;MQ=60.00;MQ0=0;SB=0.127;MQRankSum=-1.109;QD=18.19;ReadPosRankSum=0.334;VQSLOD=2.70;CIGAR=a;culprit=FS;toxic\n", defaultHeader)
+	result, err := parseVcfLine("1\t847491\trs28407778\tG\tA,C\t745.77\tPASS\tAC=1,2;AF=0.500,0.335;AN=2;BQ=30.00;BaseQRankSum=0.842;ClippingRankSum=0.147;DB;DP=41;FS=0.000;NS=27;H2;H3;SOMATIC;VALIDATED;1000G;MLEAC=1;MLEAF=0.500;END=847492;MQ=60.00;MQ0=0;SB=0.127;MQRankSum=-1.109;QD=18.19;ReadPosRankSum=0.334;VQSLOD=2.70;CIGAR=a;culprit=FS;toxic\n", defaultHeader, Standard, nil)
 
 	assert.NoError(s.T(), err, "Valid VCF line should not return error")
 	assert.NotNil(s.T(), result, "Valid VCF line should not return nil")
@@ -280,7 +280,7 @@ func (s *ParseVcfLineSuite) TestInfoWithoutFormat() {
 }
 
 func (s *ParseVcfLineSuite) TestAncestralAllele() {
-	result, _ := parseVcfLine("1\t847491\trs28407778\tG\tA,C\t745.77\tPASS\tAC=1;AF=0.500,0.335;AN=2;BaseQRankSum=0.842;ClippingRankSum=0.147;DB;AA=T;DP=41;FS=0.000;MLEAC=1;MLEAF=0.500;MQ=60.00;MQ0=0;MQRankSum=-1.109;QD=18.19;ReadPosRankSum=0.334;VQSLOD=2.70;culprit=FS;set=variant\tGT:AD:DP:GQ:PL\t0/1:16,25:41:99:774,0,434", defaultHeader)
+	result, _ := parseVcfLine("1\t847491\trs28407778\tG\tA,C\t745.77\tPASS\tAC=1;AF=0.500,0.335;AN=2;BaseQRankSum=0.842;ClippingRankSum=0.147;DB;AA=T;DP=41;FS=0.000;MLEAC=1;MLEAF=0.500;MQ=60.00;MQ0=0;MQRankSum=-1.109;QD=18.19;ReadPosRankSum=0.334;VQSLOD=2.70;culprit=FS;set=variant\tGT:AD:DP:GQ:PL\t0/1:16,25:41:99:774,0,434", defaultHeader, Standard, nil)
 
 	aa := result[0].AncestralAllele
 	assert.NotNil(s.T(), aa, "AncestralAllele field must be found")
@@ -292,7 +292,7 @@ func (s *ParseVcfLineSuite) TestAlternateFormatOptionalField() {
 	var err error
 
 	assert.NotPanics(s.T(), func() {
-		result, err = parseVcfLine("1\t847491\trs28407778\tG\tA\t745.77\tPASS\tSB=strong;AA\tGT:AD:DP:GQ:PL\t0/1:16,25:41:99:774,0,434", defaultHeader)
+		result, err = parseVcfLine("1\t847491\trs28407778\tG\tA\t745.77\tPASS\tSB=strong;AA\tGT:AD:DP:GQ:PL\t0/1:16,25:41:99:774,0,434", defaultHeader, Standard, nil)
 	})
 
 	assert.NoError(s.T(), err, "Valid VCF line should not return error")
@@ -314,7 +314,7 @@ func (s *ParseVcfLineSuite) TestAlternateFormatOptionalField() {
 }
 
 func (s *ParseVcfLineSuite) TestGenotype() {
-	result, _ := parseVcfLine("1\t847491\trs28407778\tG\tA,C\t745.77\tPASS\tAC=1;AF=0.500,0.335;AN=2;BaseQRankSum=0.842;ClippingRankSum=0.147;DB;AA=T;DP=41;FS=0.000;MLEAC=1;MLEAF=0.500;MQ=60.00;MQ0=0;MQRankSum=-1.109;QD=18.19;ReadPosRankSum=0.334;VQSLOD=2.70;culprit=FS;set=variant\tGT:AD:DP:GQ:PL\t0/1:16,25:41:99:774,0,434", defaultHeader)
+	result, _ := parseVcfLine("1\t847491\trs28407778\tG\tA,C\t745.77\tPASS\tAC=1;AF=0.500,0.335;AN=2;BaseQRankSum=0.842;ClippingRankSum=0.147;DB;AA=T;DP=41;FS=0.000;MLEAC=1;MLEAF=0.500;MQ=60.00;MQ0=0;MQRankSum=-1.109;QD=18.19;ReadPosRankSum=0.334;VQSLOD=2.70;culprit=FS;set=variant\tGT:AD:DP:GQ:PL\t0/1:16,25:41:99:774,0,434", defaultHeader, Standard, nil)
 
 	samples := result[0].Samples
 	assert.NotNil(s.T(), samples, "Samples must be found")
@@ -331,7 +331,7 @@ func TestParseVcfLineSuite(t *testing.T) {
 }
 
 func (s *ParseVcfLineSuite) TestValidCNVShouldReturnOneElementAndNoErrors() {
-	result, err := parseVcfLine("22\t16533236\tSI_BD_17525\tC\t<CN0>\t100\tPASS\tAC=125;AF=0.0249601;AN=5008;CIEND=-50,141;CIPOS=-141,50;CS=DEL_union;END=16536204;NS=2504;SVLEN=-2968;SVTYPE=DEL;DP=14570;EAS_AF=0;AMR_AF=0.0086;AFR_AF=0.09;EUR_AF=0;SAS_AF=0\tGT", defaultHeader)
+	result, err := parseVcfLine("22\t16533236\tSI_BD_17525\tC\t<CN0>\t100\tPASS\tAC=125;AF=0.0249601;AN=5008;CIEND=-50,141;CIPOS=-141,50;CS=DEL_union;END=16536204;NS=2504;SVLEN=-2968;SVTYPE=DEL;DP=14570;EAS_AF=0;AMR_AF=0.0086;AFR_AF=0.09;EUR_AF=0;SAS_AF=0\tGT", defaultHeader, Standard, nil)
 
 	assert.NoError(s.T(), err, "Valid VCF line should not return error")
 	assert.NotNil(s.T(), result, "Valid VCF line should not return nil")
@@ -396,7 +396,7 @@ type SplitVcfFieldsSuite struct {
 
 func (s *SplitVcfFieldsSuite) TestNewlineChomp() {
 	line := "X\t32632420\t.\tN\t<DEL>\t87.2\tPASS\tSVTYPE=DEL;END=32717410;EXPECTED=1071;OBSERVED=17;RATIO=0.0159;BF=87.2\tGT\t1/1\n"
-	vcfLine, err := splitVcfFields(line)
+	vcfLine, err := splitVcfFields(line, nil, Standard, nil)
 
 	assert.NoError(s.T(), err, "split should not fail")
 	assert.NotNil(s.T(), vcfLine, "vcf line can't be nil")
@@ -405,6 +405,38 @@ func (s *SplitVcfFieldsSuite) TestNewlineChomp() {
 	assert.Equal(s.T(), "1/1", gt)
 }
 
+func (s *SplitVcfFieldsSuite) TestWrongColumnCount() {
+	_, err := splitVcfFields("A\tB\tC\tD\tE\tF\n", nil, Standard, nil)
+
+	parseErr, ok := err.(*ParseError)
+	assert.True(s.T(), ok, "error should be a *ParseError")
+	assert.Equal(s.T(), WrongColumnCount, parseErr.Kind)
+	assert.Equal(s.T(), "unable to parse apparently misformatted VCF line, expected at least 8 columns and found 6", parseErr.Error())
+}
+
+func (s *SplitVcfFieldsSuite) TestMissingSampleFields() {
+	result, err := splitVcfFields("1\t100\t.\tA\tG\t.\t.\t.\tGT:DP:GQ\t0/1:12\t.\n", nil, Standard, nil)
+
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), map[string]string{"GT": "0/1", "DP": "12", "GQ": "."}, result.Samples[0], "dropped trailing fields should be missing")
+	assert.Equal(s.T(), map[string]string{"GT": ".", "DP": ".", "GQ": "."}, result.Samples[1], "a '.' column should be all missing")
+}
+
+func (s *SplitVcfFieldsSuite) TestExtraSampleFields() {
+	_, err := splitVcfFields("1\t100\t.\tA\tG\t.\t.\t.\tGT\t0/1\t0/1:12\n", nil, Standard, nil)
+
+	parseErr, ok := err.(*ParseError)
+	assert.True(s.T(), ok, "error should be a *ParseError")
+	assert.Equal(s.T(), ExtraSampleFields, parseErr.Kind)
+	assert.Equal(s.T(), 11, parseErr.Column)
+	assert.Equal(s.T(), "0/1:12", parseErr.Value)
+	assert.Equal(s.T(), "sample 2 has 2 values for 1 FORMAT keys", parseErr.Error())
+
+	result, err := splitVcfFields("1\t100\t.\tA\tG\t.\t.\t.\tGT\t0/1\t0/1:12\n", nil, Lenient, nil)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), map[string]string{"GT": "0/1"}, result.Samples[1], "extra fields should be ignored by the Lenient level")
+}
+
 func TestSplitVcfFieldsSuite(t *testing.T) {
 	suite.Run(t, new(SplitVcfFieldsSuite))
 }
@@ -0,0 +1,46 @@
+package vcf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Warning is a problem found on a line that did not prevent it from being parsed, such as a value that could
+// not be parsed and was ignored.
+type Warning struct {
+	LineNumber int
+
+	// Field is where the problem was found: a column such as QUAL, an INFO key such as INFO/DP, or a sample
+	// column such as sample 2, counting from the first sample.
+	Field string
+
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("line %d, %s: %s", w.LineNumber, w.Field, w.Message)
+}
+
+// warnFunc receives the warnings found while parsing a line.
+type warnFunc func(field, message string)
+
+// ignoreWarnings is used when nobody handles the warnings.
+func ignoreWarnings(field, message string) {}
+
+// warningColumn returns the 1-based column of the field of a warning.
+func warningColumn(field string) int {
+	switch {
+	case field == "ALT":
+		return 5
+	case field == "QUAL":
+		return 6
+	case strings.HasPrefix(field, "INFO/"):
+		return 8
+	case strings.HasPrefix(field, "sample "):
+		if sample, err := strconv.Atoi(strings.TrimPrefix(field, "sample ")); err == nil {
+			return 9 + sample
+		}
+	}
+	return 0
+}
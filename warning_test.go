@@ -0,0 +1,82 @@
+package vcf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type WarningSuite struct {
+	suite.Suite
+}
+
+func (s *WarningSuite) TestWarningHandler() {
+	vcfLines := `##fileformat=VCFv4.2
+##INFO=<ID=AF,Number=A,Type=Float,Description="Allele frequency">
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	S1	S2
+1	100	.	A	G	high	PASS	DP=deep;AF=0.1,0.2;SVTYPE=ODD;DB=1;END	GT:DP	0/1:10:extra	0/0
+1	200	.	A	G	50	PASS	DP=10	GT	0/1	0/0
+`
+	output := make(chan *Variant, 10)
+	invalids := make(chan InvalidLine, 10)
+	warnings := make([]Warning, 0)
+	err := ToChannel(strings.NewReader(vcfLines), output, invalids, WithStrictness(Lenient), WithWarningHandler(func(warning Warning) {
+		warnings = append(warnings, warning)
+	}))
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), output, 2, "warnings should not prevent parsing")
+	assert.Len(s.T(), invalids, 0)
+
+	fields := make(map[string]string)
+	for _, warning := range warnings {
+		assert.Equal(s.T(), 4, warning.LineNumber)
+		fields[warning.Field] = warning.Message
+	}
+	assert.Equal(s.T(), map[string]string{
+		"QUAL":        "unable to parse quality as float, setting as nil",
+		"INFO/DP":     "unable to parse deep as integer, ignoring it",
+		"INFO/AF":     "expected one value per alternative for Number=A, found 0.1,0.2",
+		"INFO/SVTYPE": "unknown structural variant type ODD",
+		"INFO/DB":     "flag with value 1, ignoring it",
+		"INFO/END":    "unable to parse true as integer, ignoring it",
+		"sample 1":    "3 values for 2 FORMAT keys, ignoring the extra ones",
+	}, fields)
+
+	variant := <-output
+	assert.Nil(s.T(), variant.Qual)
+	assert.Nil(s.T(), variant.Depth)
+	assert.Equal(s.T(), map[string]string{"GT": "0/1", "DP": "10"}, variant.Samples[0])
+	assert.Equal(s.T(), map[string]string{"GT": "0/0", "DP": "."}, variant.Samples[1], "dropped trailing fields are missing")
+	assert.Equal(s.T(), "line 4, QUAL: unable to parse quality as float, setting as nil", Warning{LineNumber: 4, Field: "QUAL", Message: "unable to parse quality as float, setting as nil"}.String())
+}
+
+func (s *WarningSuite) TestSampleCount() {
+	vcfLines := `##fileformat=VCFv4.2
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	S1	S2
+1	100	.	A	G	50	PASS	.	GT	0/1
+`
+	output := make(chan *Variant, 10)
+	invalids := make(chan InvalidLine, 10)
+	warnings := make([]Warning, 0)
+	err := ToChannel(strings.NewReader(vcfLines), output, invalids, WithWarningHandler(func(warning Warning) {
+		warnings = append(warnings, warning)
+	}))
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), output, 1)
+	assert.Equal(s.T(), []Warning{{LineNumber: 3, Field: "samples", Message: "expected 2 samples as declared on the #CHROM line, found 1"}}, warnings)
+
+	output = make(chan *Variant, 10)
+	invalids = make(chan InvalidLine, 10)
+	err = ToChannel(strings.NewReader(vcfLines), output, invalids, WithStrictness(Strict))
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), output, 0)
+	invalid := <-invalids
+	assert.Equal(s.T(), WrongColumnCount, invalid.Err.(*ParseError).Kind, "only the Strict level rejects missing samples")
+	assert.EqualError(s.T(), invalid.Err, "expected 2 samples as declared on the #CHROM line, found 1")
+}
+
+func TestWarningSuite(t *testing.T) {
+	suite.Run(t, new(WarningSuite))
+}
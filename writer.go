@@ -0,0 +1,290 @@
+package vcf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Writer formats variants as VCF lines.
+//
+// Each Variant is written on its own line, so multiple alternatives that were split by ToChannel are written
+// as separate biallelic records. Since parsing strips the "chr" prefix from chromosome names, the Writer
+// restores it for chromosomes that are declared with the prefix on the ##contig lines of the header. For headers
+// of VCF 4.3 or later, special characters of the INFO and sample values are percent-encoded.
+//
+// Variants split from a multiallelic line are written with their genotypes and per-allele sample values
+// restricted to their own alternative, as Merge does, so that every record written is valid on its own.
+type Writer struct {
+	writer        *bufio.Writer
+	header        *Header
+	contigs       map[string]string
+	infoOrder     map[string]int
+	percentEncode bool
+}
+
+// NewWriter creates a Writer and writes the header to w.
+func NewWriter(w io.Writer, header *Header) (*Writer, error) {
+	writer := &Writer{
+		writer:        bufio.NewWriterSize(w, 100*1024),
+		header:        header,
+		contigs:       make(map[string]string),
+		infoOrder:     header.infoOrder(),
+		percentEncode: header.atLeast(4, 3),
+	}
+	for _, contig := range header.Contigs {
+		writer.contigs[normalizeChrom(contig.ID)] = contig.ID
+	}
+	if _, err := writer.writer.WriteString(header.String()); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// Write writes a single variant as a VCF line.
+func (w *Writer) Write(variant *Variant) error {
+	_, err := w.writer.WriteString(w.format(variant) + "\n")
+	return err
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (w *Writer) Flush() error {
+	return w.writer.Flush()
+}
+
+func (w *Writer) format(variant *Variant) string {
+	chrom := variant.Chrom
+	if declared, found := w.contigs[chrom]; found {
+		chrom = declared
+	}
+
+	fields := []string{
+		chrom,
+		strconv.Itoa(variant.Pos + 1), // converts variant back to 1-based
+		missingIfEmpty(variant.ID),
+		missingIfEmpty(variant.Ref),
+		missingIfEmpty(variant.Alt),
+		formatQual(variant.Qual),
+		missingIfEmpty(variant.Filter),
+		formatInfo(biallelicInfo(variant, w.header), w.infoOrder, w.infoEncoder()),
+	}
+
+	if len(variant.Samples) > 0 {
+		format := variant.Format
+		if len(format) == 0 {
+			format = sampleKeys(variant.Samples)
+		}
+		fields = append(fields, strings.Join(format, ":"))
+		for _, sample := range variant.Samples {
+			fields = append(fields, formatSample(format, biallelicSample(variant, sample, w.header), w.sampleEncoder()))
+		}
+	}
+
+	return strings.Join(fields, "\t")
+}
+
+// infoEncoder returns the function percent-encoding INFO values, or nil when the version of the header does not
+// require it.
+func (w *Writer) infoEncoder() func(key, value string) string {
+	if !w.percentEncode {
+		return nil
+	}
+	return func(key, value string) string {
+		return percentEncodeValue(value, infoNumber(key, w.header))
+	}
+}
+
+// sampleEncoder returns the function percent-encoding sample values, or nil when the version of the header does
+// not require it.
+func (w *Writer) sampleEncoder() func(key, value string) string {
+	if !w.percentEncode {
+		return nil
+	}
+	return func(key, value string) string {
+		return percentEncodeValue(value, formatNumber(key, w.header))
+	}
+}
+
+// splitFromMultiallelic tells whether a variant is one of the alternatives of a multiallelic line, whose sample
+// values still refer to every allele of the line.
+func splitFromMultiallelic(variant *Variant) bool {
+	return variant.AlleleIndex > 0 && len(variant.Alternatives) > 1
+}
+
+// biallelicInfo restricts the Number=G INFO values of a variant split from a multiallelic line to its
+// alternative. Number=A and Number=R values are already split when the line is parsed.
+func biallelicInfo(variant *Variant, header *Header) map[string]interface{} {
+	if !splitFromMultiallelic(variant) {
+		return variant.Info
+	}
+	var restricted map[string]interface{}
+	for key, value := range variant.Info {
+		str, ok := value.(string)
+		if !ok || infoNumber(key, header) != "G" {
+			continue
+		}
+		if restricted == nil {
+			restricted = make(map[string]interface{}, len(variant.Info))
+			for key, value := range variant.Info {
+				restricted[key] = value
+			}
+		}
+		restricted[key] = biallelicValue(str, "G", variant.AlleleIndex)
+	}
+	if restricted == nil {
+		return variant.Info
+	}
+	return restricted
+}
+
+// biallelicSample restricts the genotype and the Number=A, R and G values of a sample of a variant split from a
+// multiallelic line to its alternative. Other alternatives of the genotype become missing alleles.
+func biallelicSample(variant *Variant, sample map[string]string, header *Header) map[string]string {
+	if !splitFromMultiallelic(variant) {
+		return sample
+	}
+	restricted := make(map[string]string, len(sample))
+	for key, value := range sample {
+		if key == "GT" {
+			if genotype, err := ParseGenotype(value); err == nil {
+				value = genotype.biallelic(variant.AlleleIndex).String()
+			}
+		} else {
+			value = biallelicValue(value, formatNumber(key, header), variant.AlleleIndex)
+		}
+		restricted[key] = value
+	}
+	return restricted
+}
+
+// reservedFormatNumbers lists the Number of the reserved FORMAT keys with one value per allele or genotype.
+var reservedFormatNumbers = map[string]string{
+	"AD":  "R",
+	"ADF": "R",
+	"ADR": "R",
+	"GL":  "G",
+	"PL":  "G",
+	"GP":  "G",
+}
+
+// formatNumber returns the declared Number of a FORMAT key, falling back to the reserved keys of the spec.
+func formatNumber(key string, header *Header) string {
+	if header != nil {
+		if definition, found := header.Formats[key]; found {
+			return definition.Number
+		}
+	}
+	return reservedFormatNumbers[key]
+}
+
+func missingIfEmpty(value string) string {
+	if value == "" {
+		return "."
+	}
+	return value
+}
+
+func formatQual(qual *float64) string {
+	if qual == nil {
+		return "."
+	}
+	return strconv.FormatFloat(*qual, 'f', -1, 64)
+}
+
+// formatInfo writes the INFO keys in the order they are declared on the header, followed by the undeclared
+// ones in alphabetical order. Flags are written without a value.
+func formatInfo(info map[string]interface{}, order map[string]int, encode func(key, value string) string) string {
+	keys := make([]string, 0, len(info))
+	for key := range info {
+		if key != "" && key != "." {
+			keys = append(keys, key)
+		}
+	}
+	rank := func(key string) int {
+		if position, found := order[key]; found {
+			return position
+		}
+		return len(order)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ri, rj := rank(keys[i]), rank(keys[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return keys[i] < keys[j]
+	})
+
+	entries := make([]string, 0, len(keys))
+	for _, key := range keys {
+		switch value := info[key].(type) {
+		case bool:
+			if value {
+				entries = append(entries, key)
+			}
+		case string:
+			if encode != nil {
+				value = encode(key, value)
+			}
+			entries = append(entries, key+"="+value)
+		default:
+			entries = append(entries, key+"="+fmt.Sprint(value))
+		}
+	}
+	if len(entries) == 0 {
+		return "."
+	}
+	return strings.Join(entries, ";")
+}
+
+// infoOrder maps each declared INFO key to its position among the ##INFO lines.
+func (h *Header) infoOrder() map[string]int {
+	order := make(map[string]int)
+	for _, meta := range h.MetaLines {
+		if meta.Key == "INFO" {
+			if id, found := meta.Fields["ID"]; found {
+				if _, seen := order[id]; !seen {
+					order[id] = len(order)
+				}
+			}
+		}
+	}
+	return order
+}
+
+// sampleKeys collects the keys of the sample maps, GT first as the spec requires and the others sorted.
+func sampleKeys(samples []map[string]string) []string {
+	seen := make(map[string]bool)
+	keys := make([]string, 0)
+	for _, sample := range samples {
+		for key := range sample {
+			if !seen[key] && key != "GT" {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	for _, sample := range samples {
+		if _, found := sample["GT"]; found {
+			return append([]string{"GT"}, keys...)
+		}
+	}
+	return keys
+}
+
+func formatSample(format []string, sample map[string]string, encode func(key, value string) string) string {
+	values := make([]string, len(format))
+	for i, key := range format {
+		value, found := sample[key]
+		if !found || value == "" {
+			value = "."
+		} else if encode != nil {
+			value = encode(key, value)
+		}
+		values[i] = value
+	}
+	return strings.Join(values, ":")
+}
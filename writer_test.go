@@ -0,0 +1,103 @@
+package vcf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type WriterSuite struct {
+	suite.Suite
+}
+
+const writerText = `##fileformat=VCFv4.2
+##contig=<ID=chr1,length=248956422>
+##INFO=<ID=DP,Number=1,Type=Integer,Description="Depth">
+##INFO=<ID=AF,Number=A,Type=Float,Description="Allele frequency">
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	S1	S2
+chr1	100	rs1	A	G,T	50	PASS	AF=0.25,0.5;DP=10;DB	GT:DP	0/1:4	1/2:6
+chr1	200	.	C	.	.	.	.	GT	0/0	./.
+`
+
+func (s *WriterSuite) TestRoundTrip() {
+	var header *Header
+	output := make(chan *Variant, 10)
+	invalids := make(chan InvalidLine, 10)
+	err := ToChannel(strings.NewReader(writerText), output, invalids, WithHeaderHandler(func(h *Header) { header = h }))
+	assert.NoError(s.T(), err)
+
+	var buffer bytes.Buffer
+	writer, err := NewWriter(&buffer, header)
+	assert.NoError(s.T(), err)
+	for variant := range output {
+		assert.NoError(s.T(), writer.Write(variant))
+	}
+	assert.NoError(s.T(), writer.Flush())
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Len(s.T(), lines, 8)
+	assert.Equal(s.T(), "##fileformat=VCFv4.2", lines[0])
+	assert.Equal(s.T(), `##INFO=<ID=AF,Number=A,Type=Float,Description="Allele frequency">`, lines[3])
+	assert.Equal(s.T(), "#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tS1\tS2", lines[4])
+	assert.Equal(s.T(), "chr1\t100\trs1\tA\tG\t50\tPASS\tDP=10;AF=0.25;DB\tGT:DP\t0/1:4\t1/.:6", lines[5])
+	assert.Equal(s.T(), "chr1\t100\trs1\tA\tT\t50\tPASS\tDP=10;AF=0.5;DB\tGT:DP\t0/.:4\t./1:6", lines[6])
+	assert.Equal(s.T(), "chr1\t200\t.\tC\t.\t.\t.\t.\tGT\t0/0\t./.", lines[7])
+}
+
+func (s *WriterSuite) TestMultiallelicSite() {
+	text := "##fileformat=VCFv4.2\n" +
+		"##INFO=<ID=AD2,Number=R,Type=Integer,Description=\"Allele depths\">\n" +
+		"##INFO=<ID=GL2,Number=G,Type=Float,Description=\"Likelihoods\">\n" +
+		"##FORMAT=<ID=AD,Number=R,Type=Integer,Description=\"Allele depths\">\n" +
+		"##FORMAT=<ID=PL,Number=G,Type=Integer,Description=\"Likelihoods\">\n" +
+		"##FORMAT=<ID=XA,Number=A,Type=Integer,Description=\"Per ALT\">\n" +
+		"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tS1\n" +
+		"1\t100\t.\tA\tG,T\t.\tPASS\tAD2=1,2,3;GL2=0,1,2,3,4,5\tGT:AD:PL:XA\t1/2:1,2,3:60,50,40,30,20,0:7,8\n"
+	var header *Header
+	output := make(chan *Variant, 10)
+	invalids := make(chan InvalidLine, 10)
+	err := ToChannel(strings.NewReader(text), output, invalids, WithHeaderHandler(func(h *Header) { header = h }))
+	assert.NoError(s.T(), err)
+
+	var buffer bytes.Buffer
+	writer, err := NewWriter(&buffer, header)
+	assert.NoError(s.T(), err)
+	for variant := range output {
+		assert.NoError(s.T(), writer.Write(variant))
+	}
+	assert.NoError(s.T(), writer.Flush())
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Equal(s.T(), "1\t100\t.\tA\tG\t.\tPASS\tAD2=1,2;GL2=0,1,2\tGT:AD:PL:XA\t1/.:1,2:60,50,40:7", lines[len(lines)-2])
+	assert.Equal(s.T(), "1\t100\t.\tA\tT\t.\tPASS\tAD2=1,3;GL2=0,3,5\tGT:AD:PL:XA\t./1:1,3:60,30,0:8", lines[len(lines)-1])
+}
+
+func (s *WriterSuite) TestVariantWithoutFormat() {
+	header := newHeader()
+	header.Columns = []string{"CHROM", "POS", "ID", "REF", "ALT", "QUAL", "FILTER", "INFO", "FORMAT", "S1", "S2"}
+	qual := 12.5
+
+	var buffer bytes.Buffer
+	writer, err := NewWriter(&buffer, header)
+	assert.NoError(s.T(), err)
+	assert.NoError(s.T(), writer.Write(&Variant{
+		Chrom:   "2",
+		Pos:     9,
+		Ref:     "T",
+		Alt:     "C",
+		Qual:    &qual,
+		Info:    map[string]interface{}{"Z": "1", "A": true},
+		Samples: []map[string]string{{"DP": "3", "GT": "0/1"}, {"GT": "1/1"}},
+	}))
+	assert.NoError(s.T(), writer.Flush())
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Equal(s.T(), "2\t10\t.\tT\tC\t12.5\t.\tA;Z=1\tGT:DP\t0/1:3\t1/1:.", lines[1])
+}
+
+func TestWriterSuite(t *testing.T) {
+	suite.Run(t, new(WriterSuite))
+}